@@ -0,0 +1,25 @@
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// bearerToken sends a token in the authorization metadata of every RPC.
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// RequireTransportSecurity keeps tokens off unencrypted connections.
+func (t bearerToken) RequireTransportSecurity() bool {
+	return true
+}
+
+// WithToken returns a dial option that authenticates every RPC with a bearer
+// token from the node's auth file. It requires TLS transport credentials.
+func WithToken(token string) grpc.DialOption {
+	return grpc.WithPerRPCCredentials(bearerToken(token))
+}
@@ -0,0 +1,356 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"kvstore/internal/dedup"
+	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/ring"
+)
+
+const (
+	// DefaultRefreshInterval is how often the ring is refreshed in the background
+	DefaultRefreshInterval = 30 * time.Second
+	// DefaultContextCacheSize bounds the number of keys with a cached causal context
+	DefaultContextCacheSize = 10000
+	// Cached contexts older than this are dropped; later writes are blind
+	contextCacheTTL = time.Hour
+)
+
+// ErrNotFound is returned by Get when the key does not exist or was deleted.
+var ErrNotFound = errors.New("key not found")
+
+// MergeFunc resolves concurrent sibling values of a key into a single value.
+type MergeFunc func(key string, siblings [][]byte) ([]byte, error)
+
+// Consistency is the consistency level of a request.
+type Consistency int
+
+const (
+	ConsistencyDefault     Consistency = iota // Use the cluster's configured R/W
+	ConsistencyOne                            // One replica
+	ConsistencyQuorum                         // A majority of the N replicas
+	ConsistencyAll                            // All N replicas
+	ConsistencyLocalQuorum                    // A majority of the N replicas, counting local-zone nodes only
+)
+
+// toProto converts a consistency level to its protobuf enum.
+func (c Consistency) toProto() kvstorepb.ConsistencyLevel {
+	switch c {
+	case ConsistencyOne:
+		return kvstorepb.ConsistencyLevel_ONE
+	case ConsistencyQuorum:
+		return kvstorepb.ConsistencyLevel_QUORUM
+	case ConsistencyAll:
+		return kvstorepb.ConsistencyLevel_ALL
+	case ConsistencyLocalQuorum:
+		return kvstorepb.ConsistencyLevel_LOCAL_QUORUM
+	default:
+		return kvstorepb.ConsistencyLevel_CONSISTENCY_LEVEL_UNSPECIFIED
+	}
+}
+
+// Result is the value of a key returned by Get.
+type Result struct {
+	// Values holds the value, or the concurrent sibling values if the key has
+	// conflicting versions and no MergeFunc is set. Deleted siblings are omitted.
+	Values [][]byte
+	// Context is the causal context covering the returned versions. It is
+	// cached by the client; it is exposed for callers that store it elsewhere.
+	Context string
+}
+
+// Value returns the value, or nil if there are several siblings.
+func (r *Result) Value() []byte {
+	if len(r.Values) != 1 {
+		return nil
+	}
+	return r.Values[0]
+}
+
+// conn holds the gRPC clients for one node.
+type conn struct {
+	cc         *grpc.ClientConn
+	kv         kvstorepb.KVStoreClient
+	membership kvstorepb.MembershipClient
+}
+
+// Client is a kvstore client. It is safe for concurrent use.
+type Client struct {
+	clientID  string
+	seeds     []string
+	dialOpts  []grpc.DialOption
+	namespace string
+
+	mu    sync.RWMutex
+	conns map[string]*conn
+	ring  *ring.Ring
+	rf    int
+
+	merge            MergeFunc
+	readConsistency  Consistency
+	writeConsistency Consistency
+	retry            RetryPolicy
+
+	contexts *dedup.Table[string] // Causal context per key
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// New creates a client and discovers the ring from the first reachable seed
+// address. If no dial options are given, connections are insecure. The ring is
+// refreshed every refreshInterval (DefaultRefreshInterval if <= 0) and when a
+// node is unavailable.
+func New(ctx context.Context, seeds []string, refreshInterval time.Duration, dialOpts ...grpc.DialOption) (*Client, error) {
+	if len(seeds) == 0 {
+		return nil, errors.New("at least one seed address is required")
+	}
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultRefreshInterval
+	}
+	if len(dialOpts) == 0 {
+		dialOpts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+
+	c := newClient(seeds)
+	c.dialOpts = dialOpts
+	if err := c.Refresh(ctx); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	c.wg.Add(1)
+	go c.refreshLoop(refreshInterval)
+	return c, nil
+}
+
+// newClient creates a client without connecting to the cluster.
+func newClient(seeds []string) *Client {
+	return &Client{
+		clientID: newID(),
+		seeds:    seeds,
+		conns:    make(map[string]*conn),
+		ring:     ring.NewRing(0),
+		retry:    DefaultRetryPolicy(),
+		contexts: dedup.NewTable[string](DefaultContextCacheSize, contextCacheTTL),
+		stop:     make(chan struct{}),
+	}
+}
+
+// SetMergeFunc sets the function used to resolve siblings on Get.
+// Must be called before the client is used.
+func (c *Client) SetMergeFunc(merge MergeFunc) {
+	c.merge = merge
+}
+
+// SetNamespace sets the namespace of every request (the default namespace if
+// empty). Must be called before the client is used.
+func (c *Client) SetNamespace(namespace string) {
+	c.namespace = namespace
+}
+
+// SetConsistency sets the consistency levels of reads and writes.
+// Must be called before the client is used.
+func (c *Client) SetConsistency(read, write Consistency) {
+	c.readConsistency = read
+	c.writeConsistency = write
+}
+
+// SetRetryPolicy sets how requests are retried when a node is unavailable.
+// Must be called before the client is used.
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+// ClientID returns the identifier sent with every request of this client.
+func (c *Client) ClientID() string {
+	return c.clientID
+}
+
+// Close stops the background ring refresh and closes all connections.
+func (c *Client) Close() error {
+	c.stopOnce.Do(func() { close(c.stop) })
+	c.wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var firstErr error
+	for addr, cn := range c.conns {
+		if cn.cc != nil {
+			if err := cn.cc.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		delete(c.conns, addr)
+	}
+	return firstErr
+}
+
+// Get reads a key. If the key has siblings and a MergeFunc is set, the
+// siblings are merged and the merged value is written back; a failed
+// write-back is not reported, since the next write of the key (which carries
+// the cached context) resolves the siblings as well.
+func (c *Client) Get(ctx context.Context, key string) (*Result, error) {
+	req := &kvstorepb.GetRequest{
+		Key:              key,
+		Namespace:        c.namespace,
+		ClientId:         c.clientID,
+		RequestId:        newID(),
+		ConsistencyLevel: c.readConsistency.toProto(),
+	}
+
+	var resp *kvstorepb.GetResponse
+	err := c.do(ctx, key, func(kv kvstorepb.KVStoreClient) (err error) {
+		resp, err = kv.Get(ctx, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.Status {
+	case kvstorepb.GetResponse_SUCCESS:
+	case kvstorepb.GetResponse_NOT_FOUND:
+		// A deleted key still has a context, so a later write supersedes the tombstone
+		c.saveContext(key, resp.Context)
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("get %q: %s", key, resp.ErrorMessage)
+	}
+	c.saveContext(key, resp.Context)
+
+	values := liveValues(resp)
+	if len(values) == 0 {
+		return nil, ErrNotFound
+	}
+	result := &Result{Values: values, Context: resp.Context}
+	if len(values) == 1 || c.merge == nil {
+		return result, nil
+	}
+
+	merged, err := c.merge(key, values)
+	if err != nil {
+		return nil, fmt.Errorf("merge siblings of %q: %w", key, err)
+	}
+	result.Values = [][]byte{merged}
+	if newContext, err := c.put(ctx, key, merged, 0, resp.Context); err == nil {
+		result.Context = newContext
+	}
+	return result, nil
+}
+
+// Put writes a value. The write supersedes the versions returned by the last
+// Get or write of the key through this client.
+func (c *Client) Put(ctx context.Context, key string, value []byte) error {
+	return c.PutTTL(ctx, key, value, 0)
+}
+
+// PutTTL writes a value that expires after ttl (no expiration if ttl <= 0).
+func (c *Client) PutTTL(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	_, err := c.put(ctx, key, value, ttl, c.cachedContext(key))
+	return err
+}
+
+// put writes a value with the given causal context and returns the new context.
+func (c *Client) put(ctx context.Context, key string, value []byte, ttl time.Duration, causal string) (string, error) {
+	req := &kvstorepb.PutRequest{
+		Key:              key,
+		Namespace:        c.namespace,
+		Value:            value,
+		ClientId:         c.clientID,
+		RequestId:        newID(),
+		Context:          causal,
+		ConsistencyLevel: c.writeConsistency.toProto(),
+	}
+	if ttl > 0 {
+		req.TtlMs = ttl.Milliseconds()
+	}
+
+	var resp *kvstorepb.PutResponse
+	err := c.do(ctx, key, func(kv kvstorepb.KVStoreClient) (err error) {
+		resp, err = kv.Put(ctx, req)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	if resp.Status != kvstorepb.PutResponse_SUCCESS {
+		return "", fmt.Errorf("put %q: %s", key, resp.ErrorMessage)
+	}
+	c.saveContext(key, resp.Context)
+	return resp.Context, nil
+}
+
+// Delete deletes a key. Like Put, the delete supersedes the versions the
+// client has seen.
+func (c *Client) Delete(ctx context.Context, key string) error {
+	req := &kvstorepb.DeleteRequest{
+		Key:              key,
+		Namespace:        c.namespace,
+		ClientId:         c.clientID,
+		RequestId:        newID(),
+		Context:          c.cachedContext(key),
+		ConsistencyLevel: c.writeConsistency.toProto(),
+	}
+
+	var resp *kvstorepb.DeleteResponse
+	err := c.do(ctx, key, func(kv kvstorepb.KVStoreClient) (err error) {
+		resp, err = kv.Delete(ctx, req)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if resp.Status == kvstorepb.DeleteResponse_ERROR {
+		return fmt.Errorf("delete %q: %s", key, resp.ErrorMessage)
+	}
+	c.saveContext(key, resp.Context)
+	return nil
+}
+
+// cachedContext returns the cached causal context of a key, or "" if none.
+func (c *Client) cachedContext(key string) string {
+	causal, _ := c.contexts.Get(key)
+	return causal
+}
+
+// saveContext caches the causal context of a key.
+func (c *Client) saveContext(key, causal string) {
+	if causal != "" {
+		c.contexts.Put(key, causal)
+	}
+}
+
+// liveValues returns the non-deleted values of a Get response.
+func liveValues(resp *kvstorepb.GetResponse) [][]byte {
+	versions := resp.Conflicts
+	if len(versions) == 0 && resp.Value != nil {
+		versions = []*kvstorepb.VersionedValue{resp.Value}
+	}
+	values := make([][]byte, 0, len(versions))
+	for _, v := range versions {
+		if !v.Deleted {
+			values = append(values, v.Value)
+		}
+	}
+	return values
+}
+
+// newID returns a random 128-bit identifier.
+func newID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("crypto/rand failed: %v", err))
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,229 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/ring"
+	"kvstore/internal/storage"
+)
+
+// fakeNode is an in-memory stand-in for a node's KVStore and Membership services.
+type fakeNode struct {
+	kvstorepb.KVStoreClient
+	kvstorepb.MembershipClient
+
+	addr    string
+	members []*kvstorepb.Member
+	err     error // Returned by every KVStore call if set
+
+	gets    []*kvstorepb.GetRequest
+	puts    []*kvstorepb.PutRequest
+	getResp *kvstorepb.GetResponse
+}
+
+func (f *fakeNode) Get(ctx context.Context, req *kvstorepb.GetRequest, opts ...grpc.CallOption) (*kvstorepb.GetResponse, error) {
+	f.gets = append(f.gets, req)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.getResp, nil
+}
+
+func (f *fakeNode) Put(ctx context.Context, req *kvstorepb.PutRequest, opts ...grpc.CallOption) (*kvstorepb.PutResponse, error) {
+	f.puts = append(f.puts, req)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &kvstorepb.PutResponse{Status: kvstorepb.PutResponse_SUCCESS, Context: "ctx-after-put"}, nil
+}
+
+func (f *fakeNode) GetMembership(ctx context.Context, req *kvstorepb.GetMembershipRequest, opts ...grpc.CallOption) (*kvstorepb.GetMembershipResponse, error) {
+	return &kvstorepb.GetMembershipResponse{Members: f.members}, nil
+}
+
+func (f *fakeNode) GetRing(ctx context.Context, req *kvstorepb.GetRingRequest, opts ...grpc.CallOption) (*kvstorepb.GetRingResponse, error) {
+	return &kvstorepb.GetRingResponse{ReplicationFactor: 2, Vnodes: 16}, nil
+}
+
+// staticNode is a node with static membership, which does not serve the
+// Membership service.
+type staticNode struct {
+	kvstorepb.MembershipClient
+}
+
+func (staticNode) GetMembership(ctx context.Context, req *kvstorepb.GetMembershipRequest, opts ...grpc.CallOption) (*kvstorepb.GetMembershipResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "unknown service kvstore.Membership")
+}
+
+// newFakeCluster returns a client connected to three fake nodes, one of them dead.
+func newFakeCluster(t *testing.T) (*Client, map[string]*fakeNode) {
+	t.Helper()
+	members := []*kvstorepb.Member{
+		{Id: "n1", Addr: "a1", Status: kvstorepb.MemberStatus_ALIVE},
+		{Id: "n2", Addr: "a2", Status: kvstorepb.MemberStatus_ALIVE},
+		{Id: "n3", Addr: "a3", Status: kvstorepb.MemberStatus_ALIVE},
+		{Id: "n4", Addr: "a4", Status: kvstorepb.MemberStatus_DEAD},
+	}
+	c := newClient([]string{"a1"})
+	c.SetRetryPolicy(RetryPolicy{MaxAttempts: 3})
+	nodes := make(map[string]*fakeNode)
+	for _, addr := range []string{"a1", "a2", "a3", "a4"} {
+		f := &fakeNode{addr: addr, members: members}
+		nodes[addr] = f
+		c.conns[addr] = &conn{kv: f, membership: f}
+	}
+	if err := c.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	return c, nodes
+}
+
+func TestRefresh_RoutesLikeTheCluster(t *testing.T) {
+	c, _ := newFakeCluster(t)
+
+	want := ring.NewRing(16)
+	want.SetNodes([]ring.Node{{ID: "n1", Addr: "a1"}, {ID: "n2", Addr: "a2"}, {ID: "n3", Addr: "a3"}})
+	for _, key := range []string{"user:1", "user:2", "cart:42", "x"} {
+		got := c.route(key)
+		expected := want.PreferenceList(key, 2)
+		if len(got) != 2 || got[0] != expected[0].Addr || got[1] != expected[1].Addr {
+			t.Errorf("route(%q) = %v, want %v", key, got, expected)
+		}
+		for _, addr := range got {
+			if addr == "a4" {
+				t.Errorf("route(%q) includes dead member", key)
+			}
+		}
+	}
+}
+
+func TestRefresh_StaticMembershipUsesSeeds(t *testing.T) {
+	c := newClient([]string{"a1", "a2"})
+	for _, addr := range []string{"a1", "a2"} {
+		c.conns[addr] = &conn{membership: staticNode{}}
+	}
+	if err := c.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if got := c.route("user:1"); len(got) != 2 || got[0] != "a1" || got[1] != "a2" {
+		t.Errorf("route() = %v, want the seeds", got)
+	}
+}
+
+func TestSetNamespace_RoutesAndTagsRequests(t *testing.T) {
+	c, nodes := newFakeCluster(t)
+	c.SetNamespace("sessions")
+
+	want := ring.NewRing(16)
+	want.SetNodes([]ring.Node{{ID: "n1", Addr: "a1"}, {ID: "n2", Addr: "a2"}, {ID: "n3", Addr: "a3"}})
+	target := want.PreferenceList(storage.NamespacedKey("sessions", "s:42"), 2)[0].Addr
+	if err := c.Put(context.Background(), "s:42", []byte("v")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	puts := nodes[target].puts
+	if len(puts) != 1 || puts[0].Namespace != "sessions" {
+		t.Errorf("Expected the put on %s in namespace sessions, got %v", target, puts)
+	}
+}
+
+func TestDo_RetriesUnavailableOnNextReplica(t *testing.T) {
+	c, nodes := newFakeCluster(t)
+	key := "user:1"
+	targets := c.route(key)
+	nodes[targets[0]].err = status.Error(codes.Unavailable, "down")
+	nodes[targets[1]].getResp = &kvstorepb.GetResponse{
+		Status:  kvstorepb.GetResponse_SUCCESS,
+		Value:   &kvstorepb.VersionedValue{Value: []byte("v")},
+		Context: "ctx-1",
+	}
+
+	result, err := c.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !bytes.Equal(result.Value(), []byte("v")) {
+		t.Errorf("Expected value v, got %q", result.Value())
+	}
+	first, second := nodes[targets[0]].gets, nodes[targets[1]].gets
+	if len(first) != 1 || len(second) != 1 {
+		t.Fatalf("Expected one attempt per replica, got %d and %d", len(first), len(second))
+	}
+	if first[0].RequestId != second[0].RequestId || first[0].ClientId != c.ClientID() {
+		t.Error("Expected retries to reuse the client and request IDs")
+	}
+
+	// Other errors are not retried
+	nodes[targets[0]].err = status.Error(codes.InvalidArgument, "bad")
+	nodes[targets[0]].gets = nil
+	if _, err := c.Get(context.Background(), key); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+	if len(nodes[targets[0]].gets) != 1 {
+		t.Errorf("Expected no retry, got %d attempts", len(nodes[targets[0]].gets))
+	}
+}
+
+func TestGet_MergesSiblingsAndWritesBack(t *testing.T) {
+	c, nodes := newFakeCluster(t)
+	key := "cart:42"
+	coordinator := nodes[c.route(key)[0]]
+	coordinator.getResp = &kvstorepb.GetResponse{
+		Status: kvstorepb.GetResponse_SUCCESS,
+		Conflicts: []*kvstorepb.VersionedValue{
+			{Value: []byte("a")},
+			{Value: []byte("b")},
+			{Deleted: true},
+		},
+		Context: "ctx-siblings",
+	}
+	c.SetMergeFunc(func(key string, siblings [][]byte) ([]byte, error) {
+		return bytes.Join(siblings, []byte("+")), nil
+	})
+
+	result, err := c.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !bytes.Equal(result.Value(), []byte("a+b")) {
+		t.Errorf("Expected merged value a+b, got %q", result.Value())
+	}
+	if len(coordinator.puts) != 1 || coordinator.puts[0].Context != "ctx-siblings" {
+		t.Fatalf("Expected write-back with the read context, got %v", coordinator.puts)
+	}
+	if result.Context != "ctx-after-put" {
+		t.Errorf("Expected context of the write-back, got %q", result.Context)
+	}
+
+	// The next write carries the cached context
+	if err := c.Put(context.Background(), key, []byte("c")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if got := coordinator.puts[1].Context; got != "ctx-after-put" {
+		t.Errorf("Expected Put to send cached context, got %q", got)
+	}
+
+	// A failed merge is reported
+	c.SetMergeFunc(func(string, [][]byte) ([]byte, error) { return nil, errors.New("boom") })
+	if _, err := c.Get(context.Background(), key); err == nil {
+		t.Error("Expected merge error")
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{BaseBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	for retry, max := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 5: 300 * time.Millisecond} {
+		for i := 0; i < 20; i++ {
+			if d := p.backoff(retry); d < max/2 || d > max {
+				t.Errorf("backoff(%d) = %v, want in [%v, %v]", retry, d, max/2, max)
+			}
+		}
+	}
+}
@@ -0,0 +1,15 @@
+// Package client is a Go client for kvstore clusters.
+//
+// A Client discovers the ring from any reachable node (Membership.GetMembership
+// and Membership.GetRing), sends each request to a replica of its key so it is
+// coordinated without forwarding, and retries with exponential backoff when a
+// node is unavailable. Nodes with static membership do not serve the ring, so
+// requests go to the seed addresses and are forwarded by the cluster. Retries reuse the request ID, so a write is applied at
+// most once even if an earlier attempt reached the cluster.
+//
+// The causal context returned by reads and writes is cached per key and sent
+// with the next write of that key, so writes supersede the versions the client
+// has seen instead of creating siblings. An optional MergeFunc resolves
+// siblings on read; the merged value is written back so the conflict is
+// resolved in the cluster as well.
+package client
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	kvstorepb "kvstore/internal/gen/api"
+)
+
+// RetryPolicy controls how requests are retried when a node is unavailable.
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts per request, including the first
+	BaseBackoff time.Duration // Backoff before the first retry, doubled per retry
+	MaxBackoff  time.Duration // Upper bound on the backoff
+}
+
+// DefaultRetryPolicy returns the default retry policy: 4 attempts with
+// backoff from 50ms up to 1s.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseBackoff: 50 * time.Millisecond,
+		MaxBackoff:  time.Second,
+	}
+}
+
+// backoff returns the delay before the given retry (1 for the first retry),
+// with jitter in [d/2, d] so that clients retrying together spread out.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.BaseBackoff
+	for i := 1; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// retryable reports whether an error is worth retrying on another node.
+func retryable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// do runs call against the replicas of a key, moving to the next replica and
+// backing off while the error is Unavailable. The ring is refreshed before
+// each retry, since unavailability often means membership changed.
+func (c *Client) do(ctx context.Context, key string, call func(kv kvstorepb.KVStoreClient) error) error {
+	attempts := c.retry.MaxAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	targets := c.route(key)
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(c.retry.backoff(attempt)):
+			}
+			if c.Refresh(ctx) == nil {
+				targets = c.route(key)
+			}
+		}
+
+		cn, dialErr := c.getConn(targets[attempt%len(targets)])
+		if dialErr != nil {
+			err = status.Error(codes.Unavailable, dialErr.Error())
+			continue
+		}
+		err = call(cn.kv)
+		if !retryable(err) {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/ring"
+	"kvstore/internal/storage"
+)
+
+// errNoMembership is returned by fetchRing for a node that does not serve the
+// membership service, as with static membership.
+var errNoMembership = errors.New("node does not serve membership")
+
+// Refresh rebuilds the client's view of the ring from the first node that
+// answers, trying known members before the seed addresses. A cluster with
+// static membership does not serve its ring; the client then sends requests
+// to the seed addresses, which forward them to the replicas of the key.
+func (c *Client) Refresh(ctx context.Context) error {
+	var errs []error
+	for _, addr := range c.discoveryAddrs() {
+		rng, rf, err := c.fetchRing(ctx, addr)
+		if errors.Is(err, errNoMembership) {
+			rng, rf, err = ring.NewRing(0), 0, nil
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", addr, err))
+			continue
+		}
+		c.mu.Lock()
+		c.ring = rng
+		c.rf = rf
+		c.mu.Unlock()
+		return nil
+	}
+	return fmt.Errorf("discover ring: %w", errors.Join(errs...))
+}
+
+// fetchRing builds the ring of alive members as seen by one node. The ring
+// uses the node's virtual node count, so it routes keys like the cluster does.
+func (c *Client) fetchRing(ctx context.Context, addr string) (*ring.Ring, int, error) {
+	cn, err := c.getConn(addr)
+	if err != nil {
+		return nil, 0, err
+	}
+	membership, err := cn.membership.GetMembership(ctx, &kvstorepb.GetMembershipRequest{})
+	if status.Code(err) == codes.Unimplemented {
+		return nil, 0, errNoMembership
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := cn.membership.GetRing(ctx, &kvstorepb.GetRingRequest{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	nodes := make([]ring.Node, 0, len(membership.Members))
+	for _, m := range membership.Members {
+		if m.Status == kvstorepb.MemberStatus_ALIVE {
+			nodes = append(nodes, ring.Node{ID: m.Id, Addr: m.Addr})
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, 0, errors.New("no alive members")
+	}
+
+	rng := ring.NewRing(int(info.Vnodes))
+	rng.SetNodes(nodes)
+	return rng, int(info.ReplicationFactor), nil
+}
+
+// discoveryAddrs returns the addresses to discover the ring from: the known
+// members followed by the seeds.
+func (c *Client) discoveryAddrs() []string {
+	c.mu.RLock()
+	nodes := c.ring.GetNodes()
+	c.mu.RUnlock()
+
+	seen := make(map[string]bool, len(nodes)+len(c.seeds))
+	addrs := make([]string, 0, len(nodes)+len(c.seeds))
+	for _, n := range nodes {
+		if !seen[n.Addr] {
+			seen[n.Addr] = true
+			addrs = append(addrs, n.Addr)
+		}
+	}
+	for _, addr := range c.seeds {
+		if !seen[addr] {
+			seen[addr] = true
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// route returns the addresses of the replicas of a key in the client's
+// namespace in preference order, or the seed addresses if the ring is empty.
+func (c *Client) route(key string) []string {
+	c.mu.RLock()
+	replicas := c.ring.PreferenceList(storage.NamespacedKey(c.namespace, key), c.rf)
+	c.mu.RUnlock()
+
+	if len(replicas) == 0 {
+		return c.seeds
+	}
+	addrs := make([]string, 0, len(replicas))
+	for _, r := range replicas {
+		addrs = append(addrs, r.Addr)
+	}
+	return addrs
+}
+
+// refreshLoop refreshes the ring periodically until the client is closed.
+func (c *Client) refreshLoop(interval time.Duration) {
+	defer c.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			// Keep the previous ring if no node answers
+			_ = c.Refresh(ctx)
+			cancel()
+		}
+	}
+}
+
+// getConn returns the connection to a node, creating it if needed.
+// Connections are established lazily by gRPC, so this does not block.
+func (c *Client) getConn(addr string) (*conn, error) {
+	c.mu.RLock()
+	cn, exists := c.conns[addr]
+	c.mu.RUnlock()
+	if exists {
+		return cn, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Double-check after acquiring write lock
+	if cn, exists := c.conns[addr]; exists {
+		return cn, nil
+	}
+
+	cc, err := grpc.NewClient(addr, c.dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", addr, err)
+	}
+	cn = &conn{
+		cc:         cc,
+		kv:         kvstorepb.NewKVStoreClient(cc),
+		membership: kvstorepb.NewMembershipClient(cc),
+	}
+	c.conns[addr] = cn
+	return cn, nil
+}
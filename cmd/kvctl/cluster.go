@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	kvstorepb "kvstore/internal/gen/api"
+)
+
+func runMembers(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("members", flag.ContinueOnError)
+	if _, err := parseArgs(fs, args, 0, "members"); err != nil {
+		return err
+	}
+	membership, err := c.membership(c.addr)
+	if err != nil {
+		return err
+	}
+	resp, err := membership.GetMembership(ctx, &kvstorepb.GetMembershipRequest{})
+	if err != nil {
+		return grpcError(err)
+	}
+
+	views := make(memberViews, 0, len(resp.Members))
+	for _, m := range resp.Members {
+		views = append(views, &memberView{
+			ID:          m.Id,
+			Addr:        m.Addr,
+			Status:      m.Status.String(),
+			Incarnation: m.Incarnation,
+			LastSeen:    time.UnixMilli(int64(m.LastSeenUnixMs)).UTC().Format(time.RFC3339),
+			Local:       m.Id == resp.LocalNodeId,
+		})
+	}
+	return c.print(views)
+}
+
+func runRing(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("ring", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("usage: kvctl ring [key]")
+	}
+	membership, err := c.membership(c.addr)
+	if err != nil {
+		return err
+	}
+	resp, err := membership.GetRing(ctx, &kvstorepb.GetRingRequest{Key: fs.Arg(0)})
+	if err != nil {
+		return grpcError(err)
+	}
+
+	view := &ringView{
+		Key:               fs.Arg(0),
+		Owner:             resp.OwnerId,
+		AliveMembers:      resp.AliveMembers,
+		ReplicationFactor: resp.ReplicationFactor,
+		VNodes:            resp.Vnodes,
+	}
+	for i, id := range resp.ReplicaIds {
+		addr := ""
+		if i < len(resp.ReplicaAddrs) {
+			addr = resp.ReplicaAddrs[i]
+		}
+		view.Replicas = append(view.Replicas, fmt.Sprintf("%s (%s)", id, addr))
+	}
+	return c.print(view)
+}
+
+func runHealth(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("health", flag.ContinueOnError)
+	if _, err := parseArgs(fs, args, 0, "health"); err != nil {
+		return err
+	}
+	membership, err := c.membership(c.addr)
+	if err != nil {
+		return err
+	}
+	resp, err := membership.Health(ctx, &kvstorepb.HealthRequest{})
+	if err != nil {
+		return grpcError(err)
+	}
+	return c.print(&healthView{
+		Node:    resp.NodeId,
+		Status:  resp.Status.String(),
+		Uptime:  (time.Duration(resp.UptimeSeconds) * time.Second).String(),
+		Message: resp.Message,
+	})
+}
+
+func runStatus(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	cluster := fs.Bool("cluster", false, "Show the status of every member")
+	if _, err := parseArgs(fs, args, 0, "status [--cluster]"); err != nil {
+		return err
+	}
+	admin, err := c.admin(c.addr)
+	if err != nil {
+		return err
+	}
+
+	if *cluster {
+		resp, err := admin.GetClusterStatus(ctx, &kvstorepb.GetClusterStatusRequest{})
+		if err != nil {
+			return grpcError(err)
+		}
+		return c.print(newClusterStatusView(resp))
+	}
+	resp, err := admin.GetNodeStatus(ctx, &kvstorepb.GetNodeStatusRequest{})
+	if err != nil {
+		return grpcError(err)
+	}
+	return c.print(newStatusView(resp.Status))
+}
+
+func runCompact(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("compact", flag.ContinueOnError)
+	grace := fs.Duration("tombstone-grace", 24*time.Hour, "Keep tombstones written more recently than this")
+	if _, err := parseArgs(fs, args, 0, "compact [--tombstone-grace d]"); err != nil {
+		return err
+	}
+	if *grace <= 0 {
+		return fmt.Errorf("--tombstone-grace must be positive")
+	}
+	admin, err := c.admin(c.addr)
+	if err != nil {
+		return err
+	}
+	resp, err := admin.Compact(ctx, &kvstorepb.CompactRequest{TombstoneGraceMs: grace.Milliseconds()})
+	if err != nil {
+		return grpcError(err)
+	}
+	return c.print(&compactView{Node: resp.NodeId, Expired: resp.Expired, Tombstones: resp.Tombstones})
+}
+
+func runSnapshot(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("snapshot", flag.ContinueOnError)
+	if _, err := parseArgs(fs, args, 0, "snapshot"); err != nil {
+		return err
+	}
+	admin, err := c.admin(c.addr)
+	if err != nil {
+		return err
+	}
+	resp, err := admin.Snapshot(ctx, &kvstorepb.SnapshotRequest{})
+	if err != nil {
+		return grpcError(err)
+	}
+	return c.print(&snapshotView{Node: resp.NodeId, Path: resp.Path, Records: resp.Records, Bytes: resp.Bytes})
+}
+
+func runDecommission(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("decommission", flag.ContinueOnError)
+	if _, err := parseArgs(fs, args, 0, "decommission"); err != nil {
+		return err
+	}
+	admin, err := c.admin(c.addr)
+	if err != nil {
+		return err
+	}
+	resp, err := admin.Decommission(ctx, &kvstorepb.DecommissionRequest{})
+	if err != nil {
+		return grpcError(err)
+	}
+	view := &decommissionView{Node: resp.NodeId, HandedOff: resp.HandedOff, Failed: resp.Failed}
+	if more := resp.FailedCount - int64(len(resp.Failed)); more > 0 {
+		view.Failed = append(view.Failed, fmt.Sprintf("... and %d more", more))
+	}
+	return c.print(view)
+}
+
+func runConfig(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 || (fs.NArg() == 1 && fs.Arg(0) != "reload") {
+		return fmt.Errorf("usage: kvctl config [reload]")
+	}
+	admin, err := c.admin(c.addr)
+	if err != nil {
+		return err
+	}
+
+	if fs.Arg(0) == "reload" {
+		resp, err := admin.ReloadConfig(ctx, &kvstorepb.ReloadConfigRequest{})
+		if err != nil {
+			return grpcError(err)
+		}
+		view := newConfigView(resp.NodeId, resp.Config)
+		view.Changed = &resp.Changed
+		return c.print(view)
+	}
+	resp, err := admin.GetConfig(ctx, &kvstorepb.GetConfigRequest{})
+	if err != nil {
+		return grpcError(err)
+	}
+	return c.print(newConfigView(resp.NodeId, resp.Config))
+}
+
+// aliveAddrs returns the addresses of the alive members known to the node.
+func (c *cli) aliveAddrs(ctx context.Context) ([]string, error) {
+	membership, err := c.membership(c.addr)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := membership.GetMembership(ctx, &kvstorepb.GetMembershipRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("get membership: %w", grpcError(err))
+	}
+	addrs := make([]string, 0, len(resp.Members))
+	for _, m := range resp.Members {
+		if m.Status == kvstorepb.MemberStatus_ALIVE {
+			addrs = append(addrs, m.Addr)
+		}
+	}
+	if len(addrs) == 0 {
+		return []string{c.addr}, nil
+	}
+	return addrs, nil
+}
+
+func runNamespaces(ctx context.Context, c *cli, args []string) error {
+	sub := ""
+	if len(args) > 0 && (args[0] == "put" || args[0] == "delete") {
+		sub, args = args[0], args[1:]
+	}
+	fs := flag.NewFlagSet("namespaces", flag.ContinueOnError)
+	rf := fs.Int("rf", 3, "Replication factor (N)")
+	r := fs.Int("r", 0, "Default read quorum (0 = majority of N)")
+	w := fs.Int("w", 0, "Default write quorum (0 = majority of N)")
+	ttl := fs.Duration("ttl", 0, "Expire values this long after they are written (0 = never)")
+	policy := fs.String("conflict", "siblings", "Conflict policy: siblings or lww")
+	maxKeys := fs.Int64("max-keys", 0, "Keys each node stores for the namespace (0 = unlimited)")
+	maxValueBytes := fs.Int64("max-value-bytes", 0, "Size of a single value (0 = unlimited)")
+	maxBytes := fs.Int64("max-bytes", 0, "Bytes of keys and values each node stores for the namespace (0 = unlimited)")
+
+	want, usage := 0, "namespaces [put|delete <namespace>]"
+	if sub != "" {
+		want, usage = 1, "namespaces "+sub+" <namespace>"
+	}
+	pos, err := parseArgs(fs, args, want, usage)
+	if err != nil {
+		return err
+	}
+	admin, err := c.admin(c.addr)
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "put":
+		resp, err := admin.PutNamespace(ctx, &kvstorepb.PutNamespaceRequest{Namespace: &kvstorepb.Namespace{
+			Name:              pos[0],
+			ReplicationFactor: int32(*rf),
+			DefaultR:          int32(*r),
+			DefaultW:          int32(*w),
+			DefaultTtlMs:      ttl.Milliseconds(),
+			ConflictPolicy:    *policy,
+			MaxKeys:           *maxKeys,
+			MaxValueBytes:     *maxValueBytes,
+			MaxBytes:          *maxBytes,
+		}})
+		if err != nil {
+			return grpcError(err)
+		}
+		return c.print(namespaceViews{newNamespaceView(resp.Namespace)})
+	case "delete":
+		if _, err := admin.DeleteNamespace(ctx, &kvstorepb.DeleteNamespaceRequest{Name: pos[0]}); err != nil {
+			return grpcError(err)
+		}
+		fmt.Fprintf(c.out, "Deleted namespace %s\n", pos[0])
+		return nil
+	}
+	resp, err := admin.ListNamespaces(ctx, &kvstorepb.ListNamespacesRequest{})
+	if err != nil {
+		return grpcError(err)
+	}
+	views := make(namespaceViews, 0, len(resp.Namespaces))
+	for _, ns := range resp.Namespaces {
+		views = append(views, newNamespaceView(ns))
+	}
+	return c.print(views)
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/status"
+	kvstorepb "kvstore/internal/gen/api"
+)
+
+// errNotFound is returned when a key does not exist (exit status 3).
+var errNotFound = errors.New("key not found")
+
+// requestFlags are the flags shared by data commands.
+type requestFlags struct {
+	consistency    string
+	replicaTimeout time.Duration
+	clientID       string
+	requestID      string
+}
+
+// register adds the shared flags to a flag set.
+func (f *requestFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.consistency, "consistency", "", "Consistency level: one, quorum, all or local_quorum (default: cluster R/W)")
+	fs.DurationVar(&f.replicaTimeout, "replica-timeout", 0, "Per-replica RPC timeout (default: node setting)")
+	fs.StringVar(&f.clientID, "client-id", "kvctl", "Client ID sent with the request")
+	fs.StringVar(&f.requestID, "request-id", "", "Request ID (default: random; reuse it to retry a write safely)")
+}
+
+// level returns the consistency level as a protobuf enum.
+func (f *requestFlags) level() (kvstorepb.ConsistencyLevel, error) {
+	if f.consistency == "" {
+		return kvstorepb.ConsistencyLevel_CONSISTENCY_LEVEL_UNSPECIFIED, nil
+	}
+	level, ok := kvstorepb.ConsistencyLevel_value[strings.ToUpper(f.consistency)]
+	if !ok || level == 0 {
+		return 0, fmt.Errorf("unknown consistency level %q", f.consistency)
+	}
+	return kvstorepb.ConsistencyLevel(level), nil
+}
+
+// id returns the request ID, generating one if unset.
+func (f *requestFlags) id() string {
+	if f.requestID != "" {
+		return f.requestID
+	}
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// parseArgs parses a command's flags and checks the number of positional args.
+func parseArgs(fs *flag.FlagSet, args []string, want int, usage string) ([]string, error) {
+	// Allow flags after positional arguments (kvctl get key --consistency all)
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) != want {
+		return nil, fmt.Errorf("usage: kvctl %s", usage)
+	}
+	return positional, nil
+}
+
+func runGet(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	var rf requestFlags
+	rf.register(fs)
+	pos, err := parseArgs(fs, args, 1, "get <key>")
+	if err != nil {
+		return err
+	}
+	level, err := rf.level()
+	if err != nil {
+		return err
+	}
+
+	kv, err := c.kv(c.addr)
+	if err != nil {
+		return err
+	}
+	resp, err := kv.Get(ctx, &kvstorepb.GetRequest{
+		Namespace:        c.namespace,
+		Key:              pos[0],
+		ClientId:         rf.clientID,
+		RequestId:        rf.id(),
+		ConsistencyLevel: level,
+		ReplicaTimeoutMs: int32(rf.replicaTimeout.Milliseconds()),
+	})
+	if err != nil {
+		return grpcError(err)
+	}
+	switch resp.Status {
+	case kvstorepb.GetResponse_NOT_FOUND:
+		return errNotFound
+	case kvstorepb.GetResponse_ERROR:
+		return errors.New(resp.ErrorMessage)
+	}
+	return c.print(newGetView(pos[0], resp))
+}
+
+func runPut(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("put", flag.ContinueOnError)
+	var rf requestFlags
+	rf.register(fs)
+	ttl := fs.Duration("ttl", 0, "Expire the value after this duration (default: never)")
+	causal := fs.String("context", "", "Causal context from a previous get (default: blind write)")
+	pos, err := parseArgs(fs, args, 2, "put <key> <value>")
+	if err != nil {
+		return err
+	}
+	level, err := rf.level()
+	if err != nil {
+		return err
+	}
+
+	kv, err := c.kv(c.addr)
+	if err != nil {
+		return err
+	}
+	resp, err := kv.Put(ctx, &kvstorepb.PutRequest{
+		Namespace:        c.namespace,
+		Key:              pos[0],
+		Value:            []byte(pos[1]),
+		TtlMs:            ttl.Milliseconds(),
+		ClientId:         rf.clientID,
+		RequestId:        rf.id(),
+		Context:          *causal,
+		ConsistencyLevel: level,
+		ReplicaTimeoutMs: int32(rf.replicaTimeout.Milliseconds()),
+	})
+	if err != nil {
+		return grpcError(err)
+	}
+	if resp.Status != kvstorepb.PutResponse_SUCCESS {
+		return errors.New(resp.ErrorMessage)
+	}
+	return c.print(&writeView{
+		Key:      pos[0],
+		Status:   resp.Status.String(),
+		Version:  formatVersion(resp.Version),
+		Replicas: resp.AckedReplicas,
+		Context:  resp.Context,
+	})
+}
+
+func runDelete(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("delete", flag.ContinueOnError)
+	var rf requestFlags
+	rf.register(fs)
+	causal := fs.String("context", "", "Causal context from a previous get")
+	pos, err := parseArgs(fs, args, 1, "delete <key>")
+	if err != nil {
+		return err
+	}
+	level, err := rf.level()
+	if err != nil {
+		return err
+	}
+
+	kv, err := c.kv(c.addr)
+	if err != nil {
+		return err
+	}
+	resp, err := kv.Delete(ctx, &kvstorepb.DeleteRequest{
+		Namespace:        c.namespace,
+		Key:              pos[0],
+		ClientId:         rf.clientID,
+		RequestId:        rf.id(),
+		Context:          *causal,
+		ConsistencyLevel: level,
+		ReplicaTimeoutMs: int32(rf.replicaTimeout.Milliseconds()),
+	})
+	if err != nil {
+		return grpcError(err)
+	}
+	if resp.Status == kvstorepb.DeleteResponse_ERROR {
+		return errors.New(resp.ErrorMessage)
+	}
+	return c.print(&writeView{
+		Key:      pos[0],
+		Status:   resp.Status.String(),
+		Version:  formatVersion(resp.Version),
+		Replicas: resp.AckedReplicas,
+		Context:  resp.Context,
+	})
+}
+
+func runScan(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("scan", flag.ContinueOnError)
+	prefix := fs.String("prefix", "", "Only list keys with this prefix")
+	limit := fs.Int("limit", 100, "Maximum number of keys (0 for no limit)")
+	values := fs.Bool("values", false, "Also read the value of each key")
+	if _, err := parseArgs(fs, args, 0, "scan [--prefix p] [--limit n] [--values]"); err != nil {
+		return err
+	}
+
+	keys, err := c.scan(ctx, *prefix, *limit)
+	if err != nil {
+		return err
+	}
+	if !*values {
+		return c.print(keyList(keys))
+	}
+
+	kv, err := c.kv(c.addr)
+	if err != nil {
+		return err
+	}
+	views := make(getViews, 0, len(keys))
+	for _, key := range keys {
+		resp, err := kv.Get(ctx, &kvstorepb.GetRequest{Namespace: c.namespace, Key: key, ClientId: "kvctl"})
+		if err != nil {
+			return grpcError(err)
+		}
+		if resp.Status == kvstorepb.GetResponse_SUCCESS {
+			views = append(views, newGetView(key, resp))
+		}
+	}
+	return c.print(views)
+}
+
+func runRepair(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("repair", flag.ContinueOnError)
+	prefix := fs.String("prefix", "", "Only repair keys with this prefix")
+	node := fs.Bool("node", false, "Let the node repair the keys it stores, instead of reading every key from kvctl")
+	if _, err := parseArgs(fs, args, 0, "repair [--prefix p] [--node]"); err != nil {
+		return err
+	}
+	if *node {
+		return c.repairOnNode(ctx, *prefix)
+	}
+
+	keys, err := c.scan(ctx, *prefix, 0)
+	if err != nil {
+		return err
+	}
+	kv, err := c.kv(c.addr)
+	if err != nil {
+		return err
+	}
+
+	// A read at ALL contacts every replica, so read repair brings all of them
+	// up to date with the reconciled version
+	report := &repairView{Keys: len(keys)}
+	for _, key := range keys {
+		resp, err := kv.Get(ctx, &kvstorepb.GetRequest{
+			Namespace:        c.namespace,
+			Key:              key,
+			ClientId:         "kvctl",
+			ConsistencyLevel: kvstorepb.ConsistencyLevel_ALL,
+		})
+		if err == nil && resp.Status == kvstorepb.GetResponse_ERROR {
+			err = errors.New(resp.ErrorMessage)
+		}
+		if err != nil {
+			report.Failed = append(report.Failed, fmt.Sprintf("%s: %s", key, status.Convert(err).Message()))
+			continue
+		}
+		report.Checked++
+	}
+	return c.print(report)
+}
+
+// repairOnNode asks the node to repair the keys it stores.
+func (c *cli) repairOnNode(ctx context.Context, prefix string) error {
+	admin, err := c.admin(c.addr)
+	if err != nil {
+		return err
+	}
+	resp, err := admin.Repair(ctx, &kvstorepb.RepairRequest{Namespace: c.namespace, Prefix: prefix})
+	if err != nil {
+		return grpcError(err)
+	}
+	report := &repairView{Keys: int(resp.Checked + resp.FailedCount), Checked: int(resp.Checked), Failed: resp.Failed}
+	if more := resp.FailedCount - int64(len(resp.Failed)); more > 0 {
+		report.Failed = append(report.Failed, fmt.Sprintf("... and %d more", more))
+	}
+	return c.print(report)
+}
+
+// scan lists up to limit keys (no limit if <= 0) with a prefix across all
+// alive members. Each node only returns the keys it stores, so the pages of
+// every member are merged.
+func (c *cli) scan(ctx context.Context, prefix string, limit int) ([]string, error) {
+	addrs, err := c.aliveAddrs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := make([][]string, 0, len(addrs))
+	for _, addr := range addrs {
+		kv, err := c.kv(addr)
+		if err != nil {
+			return nil, err
+		}
+		var keys []string
+		startAfter := ""
+		for limit <= 0 || len(keys) < limit {
+			resp, err := kv.Scan(ctx, &kvstorepb.ScanRequest{Namespace: c.namespace, Prefix: prefix, StartAfter: startAfter})
+			if err != nil {
+				return nil, fmt.Errorf("scan %s: %w", addr, grpcError(err))
+			}
+			keys = append(keys, resp.Keys...)
+			if !resp.More || len(resp.Keys) == 0 {
+				break
+			}
+			startAfter = resp.Keys[len(resp.Keys)-1]
+		}
+		lists = append(lists, keys)
+	}
+	return mergeKeys(lists, limit), nil
+}
+
+// mergeKeys merges sorted key lists into one sorted list without duplicates,
+// truncated to limit keys (no limit if <= 0).
+func mergeKeys(lists [][]string, limit int) []string {
+	seen := make(map[string]bool)
+	merged := make([]string, 0)
+	for _, keys := range lists {
+		for _, key := range keys {
+			if !seen[key] {
+				seen[key] = true
+				merged = append(merged, key)
+			}
+		}
+	}
+	sort.Strings(merged)
+	if limit > 0 && len(merged) > limit {
+		merged = merged[:limit]
+	}
+	return merged
+}
+
+// grpcError strips the gRPC status prefix from an error for display.
+func grpcError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	return fmt.Errorf("%s: %s", st.Code(), st.Message())
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+
+	kvstorepb "kvstore/internal/gen/api"
+)
+
+func TestMergeKeys(t *testing.T) {
+	lists := [][]string{
+		{"a", "c", "e"},
+		{"b", "c", "d"},
+		{"a", "e", "f"},
+	}
+	if got := mergeKeys(lists, 0); !reflect.DeepEqual(got, []string{"a", "b", "c", "d", "e", "f"}) {
+		t.Errorf("mergeKeys = %v", got)
+	}
+	if got := mergeKeys(lists, 3); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("mergeKeys with limit = %v", got)
+	}
+}
+
+func TestParseArgs_FlagsAfterPositional(t *testing.T) {
+	fs := flag.NewFlagSet("put", flag.ContinueOnError)
+	var rf requestFlags
+	rf.register(fs)
+	ttl := fs.Duration("ttl", 0, "")
+
+	pos, err := parseArgs(fs, []string{"k", "--consistency", "all", "v", "--ttl", "1m"}, 2, "put <key> <value>")
+	if err != nil {
+		t.Fatalf("parseArgs failed: %v", err)
+	}
+	if !reflect.DeepEqual(pos, []string{"k", "v"}) || rf.consistency != "all" || ttl.Minutes() != 1 {
+		t.Errorf("Unexpected parse: pos=%v consistency=%q ttl=%v", pos, rf.consistency, *ttl)
+	}
+	if level, err := rf.level(); err != nil || level != kvstorepb.ConsistencyLevel_ALL {
+		t.Errorf("level() = %v, %v", level, err)
+	}
+
+	if _, err := parseArgs(flag.NewFlagSet("get", flag.ContinueOnError), []string{"a", "b"}, 1, "get <key>"); err == nil {
+		t.Error("Expected error for extra argument")
+	}
+}
+
+func TestPrint_Formats(t *testing.T) {
+	resp := &kvstorepb.GetResponse{
+		Conflicts: []*kvstorepb.VersionedValue{
+			{Value: []byte("a"), Version: &kvstorepb.VectorClock{Entries: []*kvstorepb.VectorClockEntry{{NodeId: "n2", Counter: 1}, {NodeId: "n1", Counter: 3}}}},
+			{Value: []byte{0xff, 0xfe}},
+		},
+		Context: "ctx",
+	}
+	view := newGetView("k", resp)
+
+	var table bytes.Buffer
+	c := &cli{out: &table, format: "table"}
+	if err := c.print(view); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(table.String()), "\n"); len(lines) != 3 || !strings.Contains(lines[1], "{n1:3, n2:1}") {
+		t.Errorf("Unexpected table output:\n%s", table.String())
+	}
+
+	var out bytes.Buffer
+	c = &cli{out: &out, format: "json"}
+	if err := c.print(view); err != nil {
+		t.Fatal(err)
+	}
+	var decoded getView
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(decoded.Values) != 2 || !decoded.Values[1].Base64 || decoded.Context != "ctx" {
+		t.Errorf("Unexpected JSON output: %s", out.String())
+	}
+}
+
+func TestClusterStatusView(t *testing.T) {
+	view := newClusterStatusView(&kvstorepb.GetClusterStatusResponse{
+		ResponderId: "n1",
+		Members: []*kvstorepb.ClusterMemberStatus{
+			{NodeId: "n1", Addr: "a1", MemberStatus: kvstorepb.MemberStatus_ALIVE, Status: &kvstorepb.NodeStatus{NodeId: "n1", Addr: "a1", Keys: 3}},
+			{NodeId: "n2", Addr: "a2", MemberStatus: kvstorepb.MemberStatus_SUSPECT, Error: "connection refused"},
+		},
+		Keys: 3,
+	})
+
+	rows := view.rows()
+	if len(rows) != 3 {
+		t.Fatalf("Expected 2 member rows and a total, got %v", rows)
+	}
+	if rows[0][2] != "ALIVE" || rows[0][5] != "3" {
+		t.Errorf("Unexpected row for n1: %v", rows[0])
+	}
+	if rows[1][2] != "SUSPECT" || rows[1][9] != "connection refused" {
+		t.Errorf("Unexpected row for n2: %v", rows[1])
+	}
+	if rows[2][0] != "total" || rows[2][2] != "UNHEALTHY" || rows[2][5] != "3" {
+		t.Errorf("Unexpected total row: %v", rows[2])
+	}
+}
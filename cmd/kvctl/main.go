@@ -0,0 +1,204 @@
+// Command kvctl is a command-line tool for kvstore data and admin operations.
+//
+// Usage:
+//
+//	kvctl [--addr host:port] [-n namespace] [-o table|json] [--timeout 5s] [--tls-ca ca.crt] [--token t] <command> [flags] [args]
+//
+// Any node address works: requests are coordinated (or forwarded) by the
+// node, and cluster-wide commands discover the other members from it.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"kvstore/client"
+	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/tlsutil"
+)
+
+const usage = `Usage: kvctl [global flags] <command> [flags] [args]
+
+Data commands:
+  get <key>            Read a key
+  put <key> <value>    Write a key (--ttl, --context)
+  delete <key>         Delete a key (--context)
+  scan                 List keys across the cluster (--prefix, --limit, --values)
+  repair               Read keys at consistency ALL to repair every replica (--prefix, --node)
+
+Cluster commands:
+  members              Show cluster membership
+  ring [key]           Show ring information, or the replicas of a key
+  health               Show the health of the node
+  status               Show the status of the node, or of every member (--cluster)
+  compact              Remove expired values and old tombstones from the node (--tombstone-grace)
+  snapshot             Write the node's store to a file in its snapshot directory
+  decommission         Remove the node from the cluster and hand its keys to their new replicas
+  config [reload]      Show the node's runtime config, or reload it from its sources
+  namespaces           List namespaces
+  namespaces put <ns>  Create or update a namespace (--rf, --r, --w, --ttl, --conflict, --max-keys, --max-value-bytes, --max-bytes)
+  namespaces delete <ns>
+                       Delete a namespace
+
+Global flags:
+`
+
+// command is a kvctl subcommand.
+type command struct {
+	run func(ctx context.Context, cli *cli, args []string) error
+}
+
+var commands = map[string]command{
+	"get":      {runGet},
+	"put":      {runPut},
+	"delete":   {runDelete},
+	"scan":     {runScan},
+	"repair":   {runRepair},
+	"members":  {runMembers},
+	"ring":     {runRing},
+	"health":   {runHealth},
+	"config":   {runConfig},
+	"status":   {runStatus},
+	"compact":  {runCompact},
+	"snapshot": {runSnapshot},
+
+	"decommission": {runDecommission},
+
+	"namespaces": {runNamespaces},
+}
+
+// cli holds the global flags and connections shared by all commands.
+type cli struct {
+	addr      string
+	namespace string // Namespace of data commands ("" = default)
+	out       io.Writer
+	format    string
+	creds     credentials.TransportCredentials
+	token     string
+	conns     map[string]*grpc.ClientConn
+}
+
+func main() {
+	fs := flag.NewFlagSet("kvctl", flag.ExitOnError)
+	addr := fs.String("addr", envOr("KVCTL_ADDR", "localhost:50051"), "Node address (env KVCTL_ADDR)")
+	ns := fs.String("n", envOr("KVCTL_NAMESPACE", ""), "Namespace of data commands (env KVCTL_NAMESPACE; default: the default namespace)")
+	format := fs.String("o", "table", "Output format: table or json")
+	timeout := fs.Duration("timeout", 10*time.Second, "Timeout for the whole command")
+	tlsCA := fs.String("tls-ca", envOr("KVCTL_TLS_CA", ""), "CA verifying the node certificate; enables TLS (env KVCTL_TLS_CA)")
+	tlsCert := fs.String("tls-cert", envOr("KVCTL_TLS_CERT", ""), "Client certificate (env KVCTL_TLS_CERT)")
+	tlsKey := fs.String("tls-key", envOr("KVCTL_TLS_KEY", ""), "Client certificate key (env KVCTL_TLS_KEY)")
+	token := fs.String("token", envOr("KVCTL_TOKEN", ""), "Bearer token; requires TLS (env KVCTL_TOKEN)")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[1:])
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "kvctl: unknown command %q\n\n", fs.Arg(0))
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "kvctl: unknown output format %q (expected table or json)\n", *format)
+		os.Exit(2)
+	}
+
+	creds := insecure.NewCredentials()
+	if *tlsCA != "" || *tlsCert != "" {
+		tlsCfg, err := tlsutil.ClientConfig(*tlsCA, *tlsCert, *tlsKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "kvctl: %v\n", err)
+			os.Exit(2)
+		}
+		creds = credentials.NewTLS(tlsCfg)
+	} else if *token != "" {
+		fmt.Fprintln(os.Stderr, "kvctl: --token requires TLS (--tls-ca)")
+		os.Exit(2)
+	}
+
+	c := &cli{addr: *addr, namespace: *ns, out: os.Stdout, format: *format, creds: creds, token: *token, conns: make(map[string]*grpc.ClientConn)}
+	defer c.close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	if err := cmd.run(ctx, c, fs.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "kvctl: %v\n", err)
+		if errors.Is(err, errNotFound) {
+			os.Exit(3)
+		}
+		os.Exit(1)
+	}
+}
+
+// conn returns a connection to a node, creating it if needed.
+func (c *cli) conn(addr string) (*grpc.ClientConn, error) {
+	if cc, ok := c.conns[addr]; ok {
+		return cc, nil
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(c.creds)}
+	if c.token != "" {
+		opts = append(opts, client.WithToken(c.token))
+	}
+	cc, err := grpc.NewClient(addr, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", addr, err)
+	}
+	c.conns[addr] = cc
+	return cc, nil
+}
+
+// kv returns a KVStore client for a node.
+func (c *cli) kv(addr string) (kvstorepb.KVStoreClient, error) {
+	cc, err := c.conn(addr)
+	if err != nil {
+		return nil, err
+	}
+	return kvstorepb.NewKVStoreClient(cc), nil
+}
+
+// membership returns a Membership client for a node.
+func (c *cli) membership(addr string) (kvstorepb.MembershipClient, error) {
+	cc, err := c.conn(addr)
+	if err != nil {
+		return nil, err
+	}
+	return kvstorepb.NewMembershipClient(cc), nil
+}
+
+// admin returns an Admin client for a node.
+func (c *cli) admin(addr string) (kvstorepb.AdminClient, error) {
+	cc, err := c.conn(addr)
+	if err != nil {
+		return nil, err
+	}
+	return kvstorepb.NewAdminClient(cc), nil
+}
+
+// close closes all connections.
+func (c *cli) close() {
+	for _, cc := range c.conns {
+		cc.Close()
+	}
+}
+
+// envOr returns the value of an environment variable, or def if unset.
+func envOr(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
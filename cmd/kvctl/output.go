@@ -0,0 +1,520 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	kvstorepb "kvstore/internal/gen/api"
+)
+
+// tabular is implemented by results that can be printed as a table.
+type tabular interface {
+	header() []string
+	rows() [][]string
+}
+
+// print writes a result in the selected output format.
+func (c *cli) print(v tabular) error {
+	if c.format == "json" {
+		enc := json.NewEncoder(c.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	return writeTable(c.out, v)
+}
+
+// writeTable writes a result as an aligned table.
+func writeTable(out io.Writer, v tabular) error {
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(v.header(), "\t"))
+	for _, row := range v.rows() {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// getView is the result of reading a key.
+type getView struct {
+	Key      string        `json:"key"`
+	Values   []versionView `json:"values"`
+	Context  string        `json:"context"`
+	Replicas []string      `json:"replicas"`
+}
+
+// versionView is one version (or sibling) of a key.
+type versionView struct {
+	Value   string `json:"value"`
+	Base64  bool   `json:"base64,omitempty"` // Value is base64-encoded binary data
+	Version string `json:"version"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+func newGetView(key string, resp *kvstorepb.GetResponse) *getView {
+	versions := resp.Conflicts
+	if len(versions) == 0 && resp.Value != nil {
+		versions = []*kvstorepb.VersionedValue{resp.Value}
+	}
+	view := &getView{Key: key, Context: resp.Context, Replicas: resp.AckedReplicas}
+	for _, v := range versions {
+		value, isBase64 := formatValue(v.Value)
+		view.Values = append(view.Values, versionView{
+			Value:   value,
+			Base64:  isBase64,
+			Version: formatVersion(v.Version),
+			Deleted: v.Deleted,
+		})
+	}
+	return view
+}
+
+func (v *getView) header() []string { return []string{"KEY", "VALUE", "VERSION", "CONTEXT"} }
+
+func (v *getView) rows() [][]string {
+	rows := make([][]string, 0, len(v.Values))
+	for _, val := range v.Values {
+		value := val.Value
+		if val.Deleted {
+			value = "<deleted>"
+		}
+		rows = append(rows, []string{v.Key, value, val.Version, v.Context})
+	}
+	return rows
+}
+
+// getViews is the result of reading several keys.
+type getViews []*getView
+
+func (v getViews) header() []string { return []string{"KEY", "VALUE", "VERSION"} }
+
+func (v getViews) rows() [][]string {
+	var rows [][]string
+	for _, view := range v {
+		for _, row := range view.rows() {
+			rows = append(rows, row[:3])
+		}
+	}
+	return rows
+}
+
+// writeView is the result of a put or delete.
+type writeView struct {
+	Key      string   `json:"key"`
+	Status   string   `json:"status"`
+	Version  string   `json:"version"`
+	Replicas []string `json:"replicas"`
+	Context  string   `json:"context"`
+}
+
+func (v *writeView) header() []string {
+	return []string{"KEY", "STATUS", "VERSION", "REPLICAS", "CONTEXT"}
+}
+
+func (v *writeView) rows() [][]string {
+	return [][]string{{v.Key, v.Status, v.Version, strings.Join(v.Replicas, ","), v.Context}}
+}
+
+// keyList is the result of a scan without values.
+type keyList []string
+
+func (v keyList) header() []string { return []string{"KEY"} }
+
+func (v keyList) rows() [][]string {
+	rows := make([][]string, 0, len(v))
+	for _, key := range v {
+		rows = append(rows, []string{key})
+	}
+	return rows
+}
+
+// repairView is the result of a repair.
+type repairView struct {
+	Keys    int      `json:"keys"`
+	Checked int      `json:"checked"`
+	Failed  []string `json:"failed"`
+}
+
+func (v *repairView) header() []string { return []string{"KEYS", "CHECKED", "FAILED"} }
+
+func (v *repairView) rows() [][]string {
+	rows := [][]string{{fmt.Sprint(v.Keys), fmt.Sprint(v.Checked), fmt.Sprint(len(v.Failed))}}
+	for _, f := range v.Failed {
+		rows = append(rows, []string{"", "", f})
+	}
+	return rows
+}
+
+// memberView is one cluster member.
+type memberView struct {
+	ID          string `json:"id"`
+	Addr        string `json:"addr"`
+	Status      string `json:"status"`
+	Incarnation uint64 `json:"incarnation"`
+	LastSeen    string `json:"last_seen"`
+	Local       bool   `json:"local"`
+}
+
+// memberViews is the cluster membership.
+type memberViews []*memberView
+
+func (v memberViews) header() []string {
+	return []string{"ID", "ADDR", "STATUS", "INCARNATION", "LAST SEEN", ""}
+}
+
+func (v memberViews) rows() [][]string {
+	rows := make([][]string, 0, len(v))
+	for _, m := range v {
+		local := ""
+		if m.Local {
+			local = "*"
+		}
+		rows = append(rows, []string{m.ID, m.Addr, m.Status, fmt.Sprint(m.Incarnation), m.LastSeen, local})
+	}
+	return rows
+}
+
+// ringView is the ring information for the cluster or a key.
+type ringView struct {
+	Key               string   `json:"key,omitempty"`
+	Owner             string   `json:"owner,omitempty"`
+	Replicas          []string `json:"replicas,omitempty"`
+	AliveMembers      int32    `json:"alive_members"`
+	ReplicationFactor int32    `json:"replication_factor"`
+	VNodes            int32    `json:"vnodes"`
+}
+
+func (v *ringView) header() []string {
+	if v.Key == "" {
+		return []string{"ALIVE MEMBERS", "N", "VNODES"}
+	}
+	return []string{"KEY", "OWNER", "REPLICAS", "ALIVE MEMBERS", "N"}
+}
+
+func (v *ringView) rows() [][]string {
+	if v.Key == "" {
+		return [][]string{{fmt.Sprint(v.AliveMembers), fmt.Sprint(v.ReplicationFactor), fmt.Sprint(v.VNodes)}}
+	}
+	return [][]string{{v.Key, v.Owner, strings.Join(v.Replicas, ", "), fmt.Sprint(v.AliveMembers), fmt.Sprint(v.ReplicationFactor)}}
+}
+
+// healthView is the health of a node.
+type healthView struct {
+	Node    string `json:"node"`
+	Status  string `json:"status"`
+	Uptime  string `json:"uptime"`
+	Message string `json:"message"`
+}
+
+func (v *healthView) header() []string { return []string{"NODE", "STATUS", "UPTIME", "MESSAGE"} }
+
+func (v *healthView) rows() [][]string {
+	return [][]string{{v.Node, v.Status, v.Uptime, v.Message}}
+}
+
+// statusView is the status of a node.
+type statusView struct {
+	Node          string `json:"node"`
+	Addr          string `json:"addr"`
+	MemberStatus  string `json:"member_status,omitempty"` // Set in cluster status
+	Uptime        string `json:"uptime,omitempty"`
+	Membership    string `json:"membership,omitempty"`
+	Members       int32  `json:"members"`
+	AliveMembers  int32  `json:"alive_members"`
+	RingEpoch     uint64 `json:"ring_epoch"`
+	Keys          int64  `json:"keys"`
+	Tombstones    int64  `json:"tombstones"`
+	StoreBytes    int64  `json:"store_bytes"`
+	RepairBacklog int64  `json:"repair_backlog"`
+	ConfigVersion uint64 `json:"config_version"`
+	Error         string `json:"error,omitempty"` // Member could not be reached
+}
+
+func newStatusView(s *kvstorepb.NodeStatus) *statusView {
+	return &statusView{
+		Node:          s.NodeId,
+		Addr:          s.Addr,
+		Uptime:        (time.Duration(s.UptimeMs) * time.Millisecond).Round(time.Second).String(),
+		Membership:    s.Membership,
+		Members:       s.Members,
+		AliveMembers:  s.AliveMembers,
+		RingEpoch:     s.RingEpoch,
+		Keys:          s.Keys,
+		Tombstones:    s.Tombstones,
+		StoreBytes:    s.StoreBytes,
+		RepairBacklog: s.RepairBacklog,
+		ConfigVersion: s.ConfigVersion,
+	}
+}
+
+func (v *statusView) header() []string {
+	return []string{"NODE", "ADDR", "UPTIME", "MEMBERS", "RING EPOCH", "KEYS", "TOMBSTONES", "BYTES", "REPAIRS", "CONFIG"}
+}
+
+func (v *statusView) rows() [][]string {
+	return [][]string{{
+		v.Node, v.Addr, v.Uptime,
+		fmt.Sprintf("%d/%d alive (%s)", v.AliveMembers, v.Members, v.Membership),
+		fmt.Sprint(v.RingEpoch), fmt.Sprint(v.Keys), fmt.Sprint(v.Tombstones), fmt.Sprint(v.StoreBytes),
+		fmt.Sprint(v.RepairBacklog), fmt.Sprintf("v%d", v.ConfigVersion),
+	}}
+}
+
+// clusterStatusView is the status of every member, as seen by one node.
+type clusterStatusView struct {
+	Responder     string        `json:"responder"`
+	Healthy       bool          `json:"healthy"`
+	Members       []*statusView `json:"members"`
+	Keys          int64         `json:"keys"`
+	Tombstones    int64         `json:"tombstones"`
+	StoreBytes    int64         `json:"store_bytes"`
+	RepairBacklog int64         `json:"repair_backlog"`
+}
+
+func newClusterStatusView(resp *kvstorepb.GetClusterStatusResponse) *clusterStatusView {
+	v := &clusterStatusView{
+		Responder:     resp.ResponderId,
+		Healthy:       resp.Healthy,
+		Keys:          resp.Keys,
+		Tombstones:    resp.Tombstones,
+		StoreBytes:    resp.StoreBytes,
+		RepairBacklog: resp.RepairBacklog,
+	}
+	for _, m := range resp.Members {
+		mv := &statusView{Node: m.NodeId, Addr: m.Addr}
+		if m.Status != nil {
+			mv = newStatusView(m.Status)
+		}
+		mv.MemberStatus = m.MemberStatus.String()
+		mv.Error = m.Error
+		v.Members = append(v.Members, mv)
+	}
+	return v
+}
+
+func (v *clusterStatusView) header() []string {
+	return []string{"NODE", "ADDR", "STATUS", "UPTIME", "RING EPOCH", "KEYS", "TOMBSTONES", "BYTES", "REPAIRS", "ERROR"}
+}
+
+func (v *clusterStatusView) rows() [][]string {
+	rows := make([][]string, 0, len(v.Members)+1)
+	for _, m := range v.Members {
+		if m.Error != "" {
+			rows = append(rows, []string{m.Node, m.Addr, m.MemberStatus, "", "", "", "", "", "", m.Error})
+			continue
+		}
+		rows = append(rows, []string{
+			m.Node, m.Addr, m.MemberStatus, m.Uptime, fmt.Sprint(m.RingEpoch),
+			fmt.Sprint(m.Keys), fmt.Sprint(m.Tombstones), fmt.Sprint(m.StoreBytes),
+			fmt.Sprint(m.RepairBacklog), "",
+		})
+	}
+	health := "HEALTHY"
+	if !v.Healthy {
+		health = "UNHEALTHY"
+	}
+	return append(rows, []string{
+		"total", "", health, "", "",
+		fmt.Sprint(v.Keys), fmt.Sprint(v.Tombstones), fmt.Sprint(v.StoreBytes),
+		fmt.Sprint(v.RepairBacklog), "",
+	})
+}
+
+// compactView is the result of compacting a node's store.
+type compactView struct {
+	Node       string `json:"node"`
+	Expired    int64  `json:"expired"`
+	Tombstones int64  `json:"tombstones"`
+}
+
+func (v *compactView) header() []string { return []string{"NODE", "EXPIRED", "TOMBSTONES"} }
+
+func (v *compactView) rows() [][]string {
+	return [][]string{{v.Node, fmt.Sprint(v.Expired), fmt.Sprint(v.Tombstones)}}
+}
+
+// snapshotView describes a snapshot written by a node.
+type snapshotView struct {
+	Node    string `json:"node"`
+	Path    string `json:"path"`
+	Records int64  `json:"records"`
+	Bytes   int64  `json:"bytes"`
+}
+
+func (v *snapshotView) header() []string { return []string{"NODE", "PATH", "RECORDS", "BYTES"} }
+
+func (v *snapshotView) rows() [][]string {
+	return [][]string{{v.Node, v.Path, fmt.Sprint(v.Records), fmt.Sprint(v.Bytes)}}
+}
+
+// decommissionView is the result of a decommission.
+type decommissionView struct {
+	Node      string   `json:"node"`
+	HandedOff int64    `json:"handed_off"`
+	Failed    []string `json:"failed"`
+}
+
+func (v *decommissionView) header() []string { return []string{"NODE", "HANDED OFF", "FAILED"} }
+
+func (v *decommissionView) rows() [][]string {
+	rows := [][]string{{v.Node, fmt.Sprint(v.HandedOff), fmt.Sprint(len(v.Failed))}}
+	for _, f := range v.Failed {
+		rows = append(rows, []string{"", "", f})
+	}
+	return rows
+}
+
+// configView is the runtime configuration of a node.
+type configView struct {
+	Node              string `json:"node"`
+	Version           uint64 `json:"version"`
+	Changed           *bool  `json:"changed,omitempty"` // Set by config reload
+	ReplicationFactor int32  `json:"replication_factor"`
+	R                 int32  `json:"r"`
+	W                 int32  `json:"w"`
+	Coordinator       string `json:"coordinator"`
+	ReplicaTimeout    string `json:"replica_timeout"`
+	Gossip            string `json:"gossip"`
+	RateLimit         string `json:"rate_limit"`
+	Admission         string `json:"admission"`
+	ClockPrune        string `json:"clock_prune"`
+	LogLevels         string `json:"log_levels"`
+}
+
+func newConfigView(nodeID string, cfg *kvstorepb.RuntimeConfig) *configView {
+	ms := func(v int64) time.Duration { return time.Duration(v) * time.Millisecond }
+	timeout := fmt.Sprintf("fixed %v", ms(cfg.ReplicaTimeoutMs))
+	if cfg.ReplicaTimeoutMode == "adaptive" {
+		timeout = fmt.Sprintf("adaptive p%g in %v..%v", cfg.ReplicaTimeoutPercentile*100, ms(cfg.ReplicaTimeoutMinMs), ms(cfg.ReplicaTimeoutMaxMs))
+	}
+	rateLimit := "-"
+	if cfg.RateLimitRequests > 0 || cfg.RateLimitBytes > 0 {
+		rateLimit = fmt.Sprintf("%g req/s, %g B/s per client", cfg.RateLimitRequests, cfg.RateLimitBytes)
+		if cfg.RateLimitOverrides > 0 {
+			rateLimit += fmt.Sprintf(" (%d overrides)", cfg.RateLimitOverrides)
+		}
+	}
+	limit := func(n int32) string {
+		if n <= 0 {
+			return "unlimited"
+		}
+		return fmt.Sprint(n)
+	}
+	maxAge := "unlimited"
+	if cfg.ClockPruneMaxAgeMs > 0 {
+		maxAge = ms(cfg.ClockPruneMaxAgeMs).String()
+	}
+	return &configView{
+		Node:              nodeID,
+		Version:           cfg.Version,
+		ReplicationFactor: cfg.ReplicationFactor,
+		R:                 cfg.DefaultR,
+		W:                 cfg.DefaultW,
+		Coordinator:       cfg.Coordinator,
+		ReplicaTimeout:    timeout,
+		Gossip: fmt.Sprintf("probe %v, suspect %v, dead %v",
+			ms(cfg.GossipProbeIntervalMs), ms(cfg.GossipSuspectTimeoutMs), ms(cfg.GossipDeadTimeoutMs)),
+		RateLimit: rateLimit,
+		Admission: fmt.Sprintf("%s requests, %s replica, target %v",
+			limit(cfg.AdmissionMaxRequests), limit(cfg.AdmissionMaxReplicaRequests), ms(cfg.AdmissionTargetDelayMs)),
+		ClockPrune: fmt.Sprintf("%d..%s entries, %v..%s", cfg.ClockPruneMinEntries, limit(cfg.ClockPruneMaxEntries),
+			ms(cfg.ClockPruneMinAgeMs), maxAge),
+		LogLevels: cfg.LogLevels,
+	}
+}
+
+func (v *configView) header() []string {
+	h := []string{"NODE", "VERSION", "N", "R", "W", "COORDINATOR", "REPLICA TIMEOUT", "GOSSIP", "RATE LIMIT", "ADMISSION", "CLOCK PRUNE", "LOG"}
+	if v.Changed != nil {
+		h = append(h, "CHANGED")
+	}
+	return h
+}
+
+func (v *configView) rows() [][]string {
+	row := []string{v.Node, fmt.Sprint(v.Version), fmt.Sprint(v.ReplicationFactor), fmt.Sprint(v.R), fmt.Sprint(v.W),
+		v.Coordinator, v.ReplicaTimeout, v.Gossip, v.RateLimit, v.Admission, v.ClockPrune, v.LogLevels}
+	if v.Changed != nil {
+		row = append(row, fmt.Sprint(*v.Changed))
+	}
+	return [][]string{row}
+}
+
+// namespaceView is one namespace.
+type namespaceView struct {
+	Name              string `json:"name"`
+	ReplicationFactor int32  `json:"replication_factor"`
+	R                 int32  `json:"r"`
+	W                 int32  `json:"w"`
+	TTL               string `json:"ttl"`
+	ConflictPolicy    string `json:"conflict_policy"`
+	MaxKeys           int64  `json:"max_keys"`
+	MaxValueBytes     int64  `json:"max_value_bytes"`
+	MaxBytes          int64  `json:"max_bytes"`
+}
+
+func newNamespaceView(ns *kvstorepb.Namespace) *namespaceView {
+	ttl := "-"
+	if ns.DefaultTtlMs > 0 {
+		ttl = (time.Duration(ns.DefaultTtlMs) * time.Millisecond).String()
+	}
+	return &namespaceView{
+		Name:              ns.Name,
+		ReplicationFactor: ns.ReplicationFactor,
+		R:                 ns.DefaultR,
+		W:                 ns.DefaultW,
+		TTL:               ttl,
+		ConflictPolicy:    ns.ConflictPolicy,
+		MaxKeys:           ns.MaxKeys,
+		MaxValueBytes:     ns.MaxValueBytes,
+		MaxBytes:          ns.MaxBytes,
+	}
+}
+
+// namespaceViews is a list of namespaces.
+type namespaceViews []*namespaceView
+
+func (v namespaceViews) header() []string {
+	return []string{"NAME", "N", "R", "W", "TTL", "CONFLICT", "MAX KEYS", "MAX VALUE BYTES", "MAX BYTES"}
+}
+
+func (v namespaceViews) rows() [][]string {
+	limit := func(n int64) string {
+		if n <= 0 {
+			return "-"
+		}
+		return fmt.Sprint(n)
+	}
+	rows := make([][]string, 0, len(v))
+	for _, ns := range v {
+		rows = append(rows, []string{ns.Name, fmt.Sprint(ns.ReplicationFactor), fmt.Sprint(ns.R), fmt.Sprint(ns.W),
+			ns.TTL, ns.ConflictPolicy, limit(ns.MaxKeys), limit(ns.MaxValueBytes), limit(ns.MaxBytes)})
+	}
+	return rows
+}
+
+// formatValue returns a value as text, or base64 if it is not valid UTF-8.
+func formatValue(value []byte) (string, bool) {
+	if utf8.Valid(value) {
+		return string(value), false
+	}
+	return base64.StdEncoding.EncodeToString(value), true
+}
+
+// formatVersion formats a vector clock as {n1:2, n2:1}, sorted by node ID.
+func formatVersion(vc *kvstorepb.VectorClock) string {
+	if vc == nil {
+		return "{}"
+	}
+	entries := make([]string, 0, len(vc.Entries))
+	for _, e := range vc.Entries {
+		entries = append(entries, fmt.Sprintf("%s:%d", e.NodeId, e.Counter))
+	}
+	sort.Strings(entries)
+	return "{" + strings.Join(entries, ", ") + "}"
+}
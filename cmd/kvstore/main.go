@@ -0,0 +1,295 @@
+// Command kvstore runs a kvstore node.
+//
+// Usage:
+//
+//	kvstore --node-id n1 [--config kvstore.yaml] [--listen :50051] [--peers id=addr,...] [flags]
+//
+// Settings are taken from defaults, then the config file, then KVSTORE_*
+// environment variables, then flags. Run kvstore -h for the full list.
+//
+// On SIGHUP (or the Admin ReloadConfig RPC) the settings are reloaded from the
+// same sources and the runtime settings (quorums, gossip timing, coordinator,
+// replica timeouts, rate and admission limits, log levels) are applied without a restart. TLS certificates and
+// the auth file are reloaded then too; certificates also whenever the files
+// change.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"kvstore/internal/auth"
+	"kvstore/internal/config"
+	"kvstore/internal/conflict"
+	"kvstore/internal/logging"
+	"kvstore/internal/metrics"
+	"kvstore/internal/node"
+	"kvstore/internal/tlsutil"
+	"kvstore/internal/tracing"
+)
+
+func main() {
+	cfg, err := config.Load(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "kvstore: %v\n", err)
+		os.Exit(2)
+	}
+	logs, err := newLogs(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "kvstore: %v\n", err)
+		os.Exit(2)
+	}
+	logging.SetDefault(logs)
+	logger := logs.Logger(logging.Node).With(logging.NodeID(cfg.NodeID))
+
+	var certs *tlsutil.Certs
+	if cfg.TLS.Enabled() {
+		certs, err = tlsutil.Load(tlsutil.Files{
+			CertFile:     cfg.TLS.Cert,
+			KeyFile:      cfg.TLS.Key,
+			CAFile:       cfg.TLS.CA,
+			ClientCAFile: cfg.TLS.ClientCA,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "kvstore: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	n, err := newNode(cfg, certs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "kvstore: %v\n", err)
+		os.Exit(2)
+	}
+	if certs != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go certs.Watch(ctx, cfg.TLS.ReloadInterval)
+	} else {
+		logger.Warn("TLS is not configured, client and internal traffic is unencrypted")
+	}
+	tracer, err := newTracer(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "kvstore: %v\n", err)
+		os.Exit(2)
+	}
+	if tracer != nil {
+		n.SetTracer(tracer)
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			tracer.Shutdown(ctx)
+		}()
+	}
+	logger.Info("Config", slog.String("listen", cfg.ListenAddr), slog.String("membership", cfg.Membership),
+		slog.Int("peers", len(cfg.Peers)), slog.Int("n", cfg.ReplicationFactor), slog.Int("r", cfg.R), slog.Int("w", cfg.W),
+		slog.Int("vnodes", cfg.VNodes), slog.String("log_levels", logs.Config().String()))
+
+	errCh := make(chan error, 2)
+	go func() { errCh <- n.Start() }()
+	if cfg.MetricsAddr != "" {
+		go func() { errCh <- serveMetrics(cfg.NodeID, cfg.MetricsAddr, metrics.Handler(n.Metrics())) }()
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+
+	for {
+		select {
+		case err := <-errCh:
+			logger.Error("Node failed", logging.Err(err))
+			os.Exit(1)
+		case sig := <-sigCh:
+			if sig == syscall.SIGHUP {
+				if _, _, err := n.ReloadFromSource(); err != nil {
+					logger.Warn("Config reload failed, keeping active config", logging.Err(err))
+				}
+				continue
+			}
+			logger.Info("Shutting down", slog.String("signal", sig.String()))
+			n.Stop()
+			return
+		}
+	}
+}
+
+// newLogs creates the loggers in the configured format and levels.
+func newLogs(cfg *config.Config) (*logging.Logs, error) {
+	logCfg, err := cfg.Log.Config()
+	if err != nil {
+		return nil, fmt.Errorf("log: %w", err)
+	}
+	return logging.New(os.Stderr, cfg.Log.Format, logCfg)
+}
+
+// newTracer creates the configured trace exporter and tracer provider, or
+// returns nil if tracing is disabled.
+func newTracer(cfg *config.Config) (*sdktrace.TracerProvider, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Tracing.Exporter {
+	case "":
+		return nil, nil
+	case config.TraceExporterStdout:
+		exporter, err = tracing.NewWriterExporter(os.Stdout)
+	case config.TraceExporterOTLP:
+		exporter, err = tracing.NewOTLPExporter(context.Background(), cfg.Tracing.Endpoint)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tracing: %w", err)
+	}
+	otel.SetErrorHandler(tracing.ErrorHandler(cfg.NodeID))
+	return tracing.NewProvider(tracing.Config{
+		Service:    "kvstore",
+		InstanceID: cfg.NodeID,
+		SampleRate: cfg.Tracing.SampleRate,
+	}, exporter)
+}
+
+// serveMetrics serves metrics on addr at /metrics until the process exits.
+func serveMetrics(nodeID, addr string, metrics http.Handler) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	logging.For(logging.Node).Info("Serving metrics", logging.NodeID(nodeID), slog.String("url", "http://"+addr+"/metrics"))
+	if err := server.ListenAndServe(); err != nil {
+		return fmt.Errorf("metrics endpoint: %w", err)
+	}
+	return nil
+}
+
+// newNode builds a node from a validated configuration. certs is nil
+// without TLS.
+func newNode(cfg *config.Config, certs *tlsutil.Certs) (*node.Node, error) {
+	policies, err := conflict.ParsePolicies(cfg.ConflictPolicies)
+	if err != nil {
+		return nil, fmt.Errorf("conflict policies: %w", err)
+	}
+	rc, err := runtimeConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var n *node.Node
+	if cfg.Membership == config.MembershipStatic {
+		n = node.NewNode(cfg.NodeID, cfg.ListenAddr, cfg.BuildRingNodes(), nil, rc.Gossip,
+			cfg.VNodes, cfg.ReplicationFactor, rc.R, rc.W, policies, []byte(cfg.ContextSecret))
+	} else {
+		n = node.NewNode(cfg.NodeID, cfg.ListenAddr, nil, cfg.Seeds(), rc.Gossip,
+			cfg.VNodes, cfg.ReplicationFactor, rc.R, rc.W, policies, []byte(cfg.ContextSecret))
+	}
+	if err := n.SetTimeoutConfig(rc.Timeouts); err != nil {
+		return nil, fmt.Errorf("replica timeout: %w", err)
+	}
+	n.SetCoordinatorPolicy(rc.Coordinator)
+	if err := n.SetRateLimits(rc.RateLimits); err != nil {
+		return nil, fmt.Errorf("rate limit: %w", err)
+	}
+	if err := n.SetAdmission(rc.Admission); err != nil {
+		return nil, fmt.Errorf("admission: %w", err)
+	}
+	if err := n.SetClockPruning(rc.ClockPrune); err != nil {
+		return nil, fmt.Errorf("clock prune: %w", err)
+	}
+	n.SetSnapshotDir(cfg.SnapshotDir)
+	if cfg.RestoreSnapshot != "" {
+		if _, err := n.RestoreSnapshot(cfg.RestoreSnapshot); err != nil {
+			return nil, err
+		}
+	}
+	if certs != nil {
+		n.SetTLS(certs)
+	}
+	if cfg.AuthFile != "" {
+		policy, err := auth.LoadPolicy(cfg.AuthFile)
+		if err != nil {
+			return nil, err
+		}
+		n.SetAuthPolicy(policy)
+	}
+	n.SetReloadFunc(reloadFunc(cfg, certs, n))
+	return n, nil
+}
+
+// reloadFunc returns a function that reloads the configuration from the
+// same file, environment and flags as at startup, the TLS certificates and
+// the auth policy, warning about changed settings that only take effect on
+// restart.
+func reloadFunc(startup *config.Config, certs *tlsutil.Certs, n *node.Node) func() (node.RuntimeConfig, error) {
+	return func() (node.RuntimeConfig, error) {
+		if certs != nil {
+			if err := certs.Reload(); err != nil {
+				return node.RuntimeConfig{}, err
+			}
+		}
+		if startup.AuthFile != "" {
+			policy, err := auth.LoadPolicy(startup.AuthFile)
+			if err != nil {
+				return node.RuntimeConfig{}, err
+			}
+			n.SetAuthPolicy(policy)
+		}
+		cfg, err := config.Load(os.Args[1:], os.Getenv)
+		if err != nil {
+			return node.RuntimeConfig{}, err
+		}
+		if names := startup.RestartRequired(cfg); len(names) > 0 {
+			logging.For(logging.Node).Warn("Changed settings take effect only after a restart",
+				logging.NodeID(startup.NodeID), slog.Any("settings", names))
+		}
+		return runtimeConfig(cfg)
+	}
+}
+
+// runtimeConfig returns the runtime settings of a validated configuration.
+func runtimeConfig(cfg *config.Config) (node.RuntimeConfig, error) {
+	coordinator, err := node.ParseCoordinatorPolicy(cfg.Coordinator)
+	if err != nil {
+		return node.RuntimeConfig{}, err
+	}
+	mode, err := node.ParseTimeoutMode(cfg.ReplicaTimeout.Mode)
+	if err != nil {
+		return node.RuntimeConfig{}, err
+	}
+	logCfg, err := cfg.Log.Config()
+	if err != nil {
+		return node.RuntimeConfig{}, fmt.Errorf("log: %w", err)
+	}
+	return node.RuntimeConfig{
+		R: cfg.R,
+		W: cfg.W,
+		Timeouts: node.TimeoutConfig{
+			Mode:       mode,
+			Fixed:      cfg.ReplicaTimeout.Fixed,
+			Min:        cfg.ReplicaTimeout.Min,
+			Max:        cfg.ReplicaTimeout.Max,
+			Percentile: cfg.ReplicaTimeout.Percentile,
+		},
+		Coordinator: coordinator,
+		Gossip: node.GossipConfig{
+			ProbeInterval:  cfg.Gossip.ProbeInterval,
+			SuspectTimeout: cfg.Gossip.SuspectTimeout,
+			DeadTimeout:    cfg.Gossip.DeadTimeout,
+		},
+		RateLimits: cfg.RateLimit.Config(),
+		Admission: node.AdmissionConfig{
+			Coordinator: cfg.Admission.Coordinator(),
+			Replica:     cfg.Admission.Replica(),
+		},
+		ClockPrune: cfg.ClockPrune.Config(),
+		Logging:    logCfg,
+	}, nil
+}
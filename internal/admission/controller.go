@@ -0,0 +1,275 @@
+package admission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// interval is the window over which queueing delay is measured.
+const interval = 100 * time.Millisecond
+
+// ErrOverloaded is returned for operations that were shed.
+var ErrOverloaded = errors.New("overloaded")
+
+// Priority orders operations for admission; lower values are admitted first.
+type Priority int
+
+// Priorities, highest first
+const (
+	Read        Priority = iota // Client reads
+	Write                       // Client writes
+	Repair                      // Read repair
+	AntiEntropy                 // Background synchronization
+	numPriorities
+)
+
+// shares are the fractions of the limit each priority may use.
+var shares = [numPriorities]float64{1, 0.9, 0.5, 0.25}
+
+func (p Priority) String() string {
+	switch p {
+	case Read:
+		return "read"
+	case Write:
+		return "write"
+	case Repair:
+		return "repair"
+	case AntiEntropy:
+		return "anti-entropy"
+	}
+	return fmt.Sprintf("Priority(%d)", int(p))
+}
+
+// background reports whether operations of priority p are best effort and
+// never wait for a slot.
+func (p Priority) background() bool {
+	return p >= Repair
+}
+
+// Config bounds the operations a Controller admits.
+type Config struct {
+	MaxInFlight int           // Operations admitted at once (0 = unlimited)
+	MaxQueue    int           // Client operations waiting for a slot
+	TargetDelay time.Duration // Queueing delay above which waiting operations are shed
+	MaxWait     time.Duration // Longest a client operation waits while not overloaded
+}
+
+// Validate checks that no limit is negative and that the target delay does
+// not exceed the maximum wait.
+func (c Config) Validate() error {
+	if c.MaxInFlight < 0 || c.MaxQueue < 0 {
+		return fmt.Errorf("admission limits cannot be negative")
+	}
+	if c.TargetDelay < 0 || c.MaxWait < 0 {
+		return fmt.Errorf("admission delays cannot be negative")
+	}
+	if c.TargetDelay > c.MaxWait {
+		return fmt.Errorf("admission target delay (%v) cannot exceed the maximum wait (%v)", c.TargetDelay, c.MaxWait)
+	}
+	return nil
+}
+
+// Stats summarizes controller activity, with counters indexed by Priority.
+type Stats struct {
+	InFlight   int
+	Queued     int
+	Overloaded bool // Queueing delay is above the target
+	Admitted   [numPriorities]uint64
+	Shed       [numPriorities]uint64
+}
+
+// waiter is a client operation waiting for a slot.
+type waiter struct {
+	priority Priority
+	enqueued time.Time
+	ready    chan struct{} // Closed when admitted
+	admitted bool
+}
+
+// Controller admits operations up to a concurrency limit. It is safe for
+// concurrent use.
+type Controller struct {
+	mu       sync.Mutex
+	cfg      Config
+	inFlight int
+	queues   [Repair][]*waiter // Foreground priorities only; FIFO
+	stats    Stats
+
+	// Queueing delay in the current interval
+	intervalStart time.Time
+	minDelay      time.Duration
+	samples       int
+	overloaded    bool
+
+	now func() time.Time // overridable in tests
+}
+
+// NewController creates a controller with the given limits.
+func NewController(cfg Config) *Controller {
+	return &Controller{cfg: cfg, intervalStart: time.Now(), now: time.Now}
+}
+
+// SetConfig replaces the limits. Operations already admitted or waiting are
+// not affected until they complete or a slot frees up.
+func (c *Controller) SetConfig(cfg Config) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cfg = cfg
+	c.dispatch()
+}
+
+// Acquire admits an operation of priority p, waiting for a slot if needed,
+// and returns a function that must be called when the operation completes.
+// It returns an error wrapping ErrOverloaded if the operation was shed, or
+// ctx's error if ctx ended while waiting.
+func (c *Controller) Acquire(ctx context.Context, p Priority) (release func(), err error) {
+	c.mu.Lock()
+	c.roll()
+	if c.cfg.MaxInFlight <= 0 || (c.canRun(p) && !c.waiting(p)) {
+		c.admit(p, 0)
+		c.mu.Unlock()
+		return c.release, nil
+	}
+	if p.background() {
+		c.stats.Shed[p]++
+		c.mu.Unlock()
+		return nil, fmt.Errorf("%w: no capacity for %s", ErrOverloaded, p)
+	}
+	if c.queued() >= c.cfg.MaxQueue {
+		c.stats.Shed[p]++
+		c.mu.Unlock()
+		return nil, fmt.Errorf("%w: %d operations in flight and %d queued", ErrOverloaded, c.inFlight, c.queued())
+	}
+	wait := c.cfg.MaxWait
+	if c.overloaded {
+		wait = c.cfg.TargetDelay
+	}
+	w := &waiter{priority: p, enqueued: c.now(), ready: make(chan struct{})}
+	c.queues[p] = append(c.queues[p], w)
+	c.mu.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-w.ready:
+		return c.release, nil
+	case <-timer.C:
+		err = fmt.Errorf("%w: waited %v for a slot", ErrOverloaded, wait)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if w.admitted {
+		// Admitted while giving up; keep the slot rather than leak it
+		return c.release, nil
+	}
+	c.remove(w)
+	c.stats.Shed[p]++
+	return nil, err
+}
+
+// Stats returns a snapshot of the controller's statistics.
+func (c *Controller) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.roll()
+	stats := c.stats
+	stats.InFlight = c.inFlight
+	stats.Queued = c.queued()
+	stats.Overloaded = c.overloaded
+	return stats
+}
+
+// release frees the slot of a completed operation.
+func (c *Controller) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inFlight--
+	c.dispatch()
+}
+
+// dispatch admits waiting operations while slots are free, highest priority
+// first. Must be called with mu held.
+func (c *Controller) dispatch() {
+	for p := Read; p < Repair; p++ {
+		for len(c.queues[p]) > 0 && (c.cfg.MaxInFlight <= 0 || c.canRun(p)) {
+			w := c.queues[p][0]
+			c.queues[p] = c.queues[p][1:]
+			w.admitted = true
+			c.admit(p, c.now().Sub(w.enqueued))
+			close(w.ready)
+		}
+	}
+}
+
+// admit takes a slot for an operation that waited delay. Must be called with
+// mu held.
+func (c *Controller) admit(p Priority, delay time.Duration) {
+	c.inFlight++
+	c.stats.Admitted[p]++
+
+	c.roll()
+	if c.samples == 0 || delay < c.minDelay {
+		c.minDelay = delay
+	}
+	c.samples++
+}
+
+// roll ends the current interval once it has elapsed: the controller is
+// overloaded if no operation admitted during it waited less than the target
+// delay. An interval followed by idle time says nothing about the present.
+// Must be called with mu held.
+func (c *Controller) roll() {
+	now := c.now()
+	elapsed := now.Sub(c.intervalStart)
+	if elapsed < interval {
+		return
+	}
+	c.overloaded = elapsed < 2*interval && c.samples > 0 && c.cfg.TargetDelay > 0 && c.minDelay > c.cfg.TargetDelay
+	c.intervalStart = now
+	c.samples = 0
+}
+
+// canRun reports whether an operation of priority p fits within its share of
+// the limit. Must be called with mu held.
+func (c *Controller) canRun(p Priority) bool {
+	limit := int(float64(c.cfg.MaxInFlight) * shares[p])
+	return c.inFlight < max(limit, 1)
+}
+
+// waiting reports whether operations of priority p or higher are queued.
+// Must be called with mu held.
+func (c *Controller) waiting(p Priority) bool {
+	for q := Read; q <= p && q < Repair; q++ {
+		if len(c.queues[q]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// queued returns the number of waiting operations. Must be called with mu held.
+func (c *Controller) queued() int {
+	n := 0
+	for _, q := range c.queues {
+		n += len(q)
+	}
+	return n
+}
+
+// remove drops w from its queue. Must be called with mu held.
+func (c *Controller) remove(w *waiter) {
+	q := c.queues[w.priority]
+	for i, other := range q {
+		if other == w {
+			c.queues[w.priority] = append(q[:i:i], q[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package admission
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestController_Limits(t *testing.T) {
+	c := NewController(Config{MaxInFlight: 4, MaxQueue: 1, TargetDelay: time.Millisecond, MaxWait: 10 * time.Millisecond})
+	ctx := context.Background()
+
+	// Repair may use half of the slots and never waits
+	var releases []func()
+	for i := 0; i < 2; i++ {
+		release, err := c.Acquire(ctx, Repair)
+		if err != nil {
+			t.Fatalf("Repair %d rejected: %v", i, err)
+		}
+		releases = append(releases, release)
+	}
+	if _, err := c.Acquire(ctx, Repair); !errors.Is(err, ErrOverloaded) {
+		t.Errorf("Expected repair beyond its share to be shed, got %v", err)
+	}
+	if _, err := c.Acquire(ctx, AntiEntropy); !errors.Is(err, ErrOverloaded) {
+		t.Errorf("Expected anti-entropy beyond its share to be shed, got %v", err)
+	}
+
+	// Client requests use the remaining slots
+	for i := 0; i < 2; i++ {
+		release, err := c.Acquire(ctx, Read)
+		if err != nil {
+			t.Fatalf("Read %d rejected: %v", i, err)
+		}
+		releases = append(releases, release)
+	}
+
+	// A full controller queues client requests for up to MaxWait
+	start := time.Now()
+	if _, err := c.Acquire(ctx, Read); !errors.Is(err, ErrOverloaded) || time.Since(start) < 10*time.Millisecond {
+		t.Errorf("Expected read to wait and be shed, got %v after %v", err, time.Since(start))
+	}
+
+	// A freed slot goes to the waiting request
+	c.SetConfig(Config{MaxInFlight: 4, MaxQueue: 1, TargetDelay: time.Millisecond, MaxWait: time.Second})
+	done := make(chan error)
+	go func() {
+		release, err := c.Acquire(ctx, Read)
+		if err == nil {
+			release()
+		}
+		done <- err
+	}()
+	waitFor(t, func() bool { return c.Stats().Queued == 1 })
+	if _, err := c.Acquire(ctx, Read); !errors.Is(err, ErrOverloaded) {
+		t.Errorf("Expected request beyond the queue limit to be shed, got %v", err)
+	}
+	releases[2]()
+	if err := <-done; err != nil {
+		t.Errorf("Expected queued read to be admitted, got %v", err)
+	}
+
+	stats := c.Stats()
+	if stats.InFlight != 3 || stats.Admitted[Repair] != 2 || stats.Shed[Repair] != 1 || stats.Shed[Read] != 2 || stats.Admitted[Read] != 3 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestController_Priority(t *testing.T) {
+	c := NewController(Config{MaxInFlight: 1, MaxQueue: 10, MaxWait: time.Second})
+	ctx := context.Background()
+	release, _ := c.Acquire(ctx, Read)
+
+	order := make(chan Priority, 2)
+	acquire := func(p Priority) {
+		r, err := c.Acquire(ctx, p)
+		if err != nil {
+			t.Errorf("Acquire(%v) failed: %v", p, err)
+			order <- p
+			return
+		}
+		order <- p
+		r()
+	}
+	go acquire(Write)
+	waitFor(t, func() bool { return c.Stats().Queued == 1 })
+	go acquire(Read)
+	waitFor(t, func() bool { return c.Stats().Queued == 2 })
+
+	release()
+	if first, second := <-order, <-order; first != Read || second != Write {
+		t.Errorf("Expected read before write, got %v then %v", first, second)
+	}
+}
+
+func TestController_AdaptiveShedding(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	c := NewController(Config{MaxInFlight: 1, MaxQueue: 10, TargetDelay: 5 * time.Millisecond, MaxWait: time.Second})
+	c.now = func() time.Time { return now }
+	c.intervalStart = now
+
+	// Every admission in an interval waited longer than the target
+	c.mu.Lock()
+	c.admit(Read, 20*time.Millisecond)
+	c.admit(Read, 10*time.Millisecond)
+	c.inFlight = 1
+	c.mu.Unlock()
+	now = now.Add(interval)
+	if !c.Stats().Overloaded {
+		t.Fatal("Expected standing queue to mark the controller overloaded")
+	}
+
+	// While overloaded, waiting requests get only the target delay
+	start := time.Now()
+	if _, err := c.Acquire(context.Background(), Read); !errors.Is(err, ErrOverloaded) || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Expected fast shedding, got %v after %v", err, time.Since(start))
+	}
+
+	// An admission within the target ends the overload after the next interval
+	c.mu.Lock()
+	c.admit(Read, 0)
+	c.mu.Unlock()
+	now = now.Add(interval)
+	if c.Stats().Overloaded {
+		t.Error("Expected overload to end once requests are admitted promptly")
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"unlimited", Config{}, false},
+		{"limits", Config{MaxInFlight: 100, MaxQueue: 100, TargetDelay: 10 * time.Millisecond, MaxWait: time.Second}, false},
+		{"negative limit", Config{MaxInFlight: -1}, true},
+		{"target above max wait", Config{TargetDelay: time.Second, MaxWait: time.Millisecond}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// waitFor polls cond until it holds or a second has passed.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Condition not reached")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,15 @@
+// Package admission bounds the work a node accepts, so that overload
+// degrades into rejected requests instead of unbounded goroutines and memory.
+//
+// A Controller admits up to a fixed number of operations at once. Each
+// operation has a priority: client reads, then writes, then read repair, then
+// anti-entropy. Lower priorities may only use part of the limit, so that
+// background work never crowds out client requests, and never wait for a
+// slot. Client requests that find the limit reached wait in a bounded queue,
+// highest priority first.
+//
+// Shedding adapts to queueing latency in the manner of CoDel: if every
+// operation admitted during an interval waited longer than the target delay,
+// the queue is standing rather than absorbing a burst, and waiting requests
+// are given only the target delay before they are rejected.
+package admission
@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Permission is a set of operations.
+type Permission uint8
+
+const (
+	Read   Permission = 1 << iota // Get and Scan
+	Write                         // Put
+	Delete                        // Delete
+	Admin                         // Admin service (not key-scoped)
+)
+
+var permissionNames = []struct {
+	perm Permission
+	name string
+}{{Read, "read"}, {Write, "write"}, {Delete, "delete"}, {Admin, "admin"}}
+
+// String returns the permissions as a comma-separated list.
+func (p Permission) String() string {
+	var names []string
+	for _, pn := range permissionNames {
+		if p&pn.perm != 0 {
+			names = append(names, pn.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// ParsePermission parses "read", "write", "delete" or "admin" (case-insensitive).
+func ParsePermission(s string) (Permission, error) {
+	for _, pn := range permissionNames {
+		if strings.EqualFold(strings.TrimSpace(s), pn.name) {
+			return pn.perm, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown permission %q (expected read, write, delete or admin)", s)
+}
+
+// Rule grants permissions on the keys of a namespace starting with Prefix to
+// a principal.
+type Rule struct {
+	Principal string     // Principal name, or "*" for any authenticated principal
+	Namespace string     // Namespace, "" for the default namespace or "*" for all
+	Prefix    string     // Key prefix; empty for all keys
+	Allow     Permission // Granted permissions
+}
+
+// ACL is a list of rules. A request is allowed if any rule grants it;
+// everything else is denied.
+type ACL struct {
+	rules []Rule
+}
+
+// NewACL creates an ACL from rules.
+func NewACL(rules []Rule) *ACL {
+	return &ACL{rules: append([]Rule(nil), rules...)}
+}
+
+// Allowed reports whether principal may perform perm on key in namespace. For
+// a Scan, key is the scan prefix, so the rule must cover every key the scan
+// can return. Admin is checked with an empty namespace and key and so needs a
+// rule with neither.
+func (a *ACL) Allowed(principal, namespace, key string, perm Permission) bool {
+	for _, r := range a.rules {
+		if (r.Principal == principal || r.Principal == "*") && (r.Namespace == namespace || r.Namespace == "*") &&
+			strings.HasPrefix(key, r.Prefix) && r.Allow&perm == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy is the authentication and authorization configuration of a node:
+// static tokens, TLS client certificates and an ACL.
+type Policy struct {
+	Authenticator
+	ACL *ACL
+}
+
+// policyFile is the YAML format of a policy file.
+type policyFile struct {
+	Tokens []struct {
+		Principal string `yaml:"principal"`
+		Token     string `yaml:"token"`
+	} `yaml:"tokens"`
+	ACL []struct {
+		Principal string   `yaml:"principal"`
+		Namespace string   `yaml:"namespace"`
+		Prefix    string   `yaml:"prefix"`
+		Allow     []string `yaml:"allow"`
+	} `yaml:"acl"`
+}
+
+// LoadPolicy reads a policy file:
+//
+//	tokens:
+//	  - principal: alice
+//	    token: <secret>
+//	acl:
+//	  - principal: alice
+//	    prefix: "user:alice/"
+//	    allow: [read, write, delete]
+//	  - principal: "*"
+//	    prefix: "public/"
+//	    allow: [read]
+//	  - principal: ops
+//	    allow: [admin]
+//	  - principal: billing
+//	    namespace: invoices      # "" (default) if unset, "*" for all
+//	    allow: [read, write]
+//
+// Clients with a TLS client certificate authenticate as its common name.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read auth policy: %w", err)
+	}
+	p, err := ParsePolicy(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// ParsePolicy parses the contents of a policy file (see LoadPolicy).
+func ParsePolicy(data []byte) (*Policy, error) {
+	var f policyFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	tokens := make(map[string]string, len(f.Tokens))
+	for i, t := range f.Tokens {
+		if t.Principal == "" || t.Token == "" {
+			return nil, fmt.Errorf("token %d: principal and token are required", i+1)
+		}
+		if _, dup := tokens[t.Token]; dup {
+			return nil, fmt.Errorf("token %d: token of %s is already assigned", i+1, t.Principal)
+		}
+		tokens[t.Token] = t.Principal
+	}
+
+	rules := make([]Rule, 0, len(f.ACL))
+	for i, r := range f.ACL {
+		if r.Principal == "" {
+			return nil, fmt.Errorf("acl rule %d: principal is required", i+1)
+		}
+		rule := Rule{Principal: r.Principal, Namespace: r.Namespace, Prefix: r.Prefix}
+		for _, name := range r.Allow {
+			perm, err := ParsePermission(name)
+			if err != nil {
+				return nil, fmt.Errorf("acl rule %d: %w", i+1, err)
+			}
+			rule.Allow |= perm
+		}
+		if rule.Allow == 0 {
+			return nil, fmt.Errorf("acl rule %d: allow is required", i+1)
+		}
+		if rule.Allow&Admin != 0 && (rule.Prefix != "" || rule.Namespace != "") {
+			return nil, fmt.Errorf("acl rule %d: admin is not key-scoped and cannot have a namespace or prefix", i+1)
+		}
+		rules = append(rules, rule)
+	}
+
+	return &Policy{
+		Authenticator: Chain{NewTokenAuthenticator(tokens), CertAuthenticator{}},
+		ACL:           NewACL(rules),
+	}, nil
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+)
+
+// ErrNoCredentials is returned when a request carries no credentials that an
+// authenticator accepts.
+var ErrNoCredentials = errors.New("no credentials")
+
+// ErrInvalidCredentials is returned when a request carries an unknown token.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Principal is an authenticated caller.
+type Principal struct {
+	Name   string // Name used in ACL rules and logs
+	Method string // How the principal authenticated: "token", "cert" or "node"
+}
+
+// String returns the principal's name, or "anonymous" for the zero value.
+func (p Principal) String() string {
+	if p.Name == "" {
+		return "anonymous"
+	}
+	return p.Name
+}
+
+// Credentials are what a request presents to authenticate.
+type Credentials struct {
+	Token string            // Bearer token, if any
+	Cert  *x509.Certificate // Verified TLS client certificate, if any
+}
+
+// Authenticator maps credentials to a principal. It returns ErrNoCredentials
+// if the credentials it handles are absent, so authenticators can be chained.
+type Authenticator interface {
+	Authenticate(creds Credentials) (Principal, error)
+}
+
+// TokenAuthenticator authenticates static bearer tokens.
+type TokenAuthenticator struct {
+	principals map[[sha256.Size]byte]string // Token hash -> principal
+}
+
+// NewTokenAuthenticator creates an authenticator from a token -> principal map.
+func NewTokenAuthenticator(tokens map[string]string) *TokenAuthenticator {
+	a := &TokenAuthenticator{principals: make(map[[sha256.Size]byte]string, len(tokens))}
+	for token, principal := range tokens {
+		a.principals[sha256.Sum256([]byte(token))] = principal
+	}
+	return a
+}
+
+// Authenticate implements Authenticator. Tokens are looked up by hash, so
+// the lookup time does not depend on how much of a token matches.
+func (a *TokenAuthenticator) Authenticate(creds Credentials) (Principal, error) {
+	if creds.Token == "" {
+		return Principal{}, ErrNoCredentials
+	}
+	name, ok := a.principals[sha256.Sum256([]byte(creds.Token))]
+	if !ok {
+		return Principal{}, ErrInvalidCredentials
+	}
+	return Principal{Name: name, Method: "token"}, nil
+}
+
+// CertAuthenticator authenticates TLS client certificates, already verified
+// during the handshake, as the principal named by their common name.
+type CertAuthenticator struct{}
+
+// Authenticate implements Authenticator.
+func (CertAuthenticator) Authenticate(creds Credentials) (Principal, error) {
+	if creds.Cert == nil || creds.Cert.Subject.CommonName == "" {
+		return Principal{}, ErrNoCredentials
+	}
+	return Principal{Name: creds.Cert.Subject.CommonName, Method: "cert"}, nil
+}
+
+// Chain tries each authenticator in order until one accepts or rejects the
+// credentials.
+type Chain []Authenticator
+
+// Authenticate implements Authenticator.
+func (c Chain) Authenticate(creds Credentials) (Principal, error) {
+	for _, a := range c {
+		p, err := a.Authenticate(creds)
+		if !errors.Is(err, ErrNoCredentials) {
+			return p, err
+		}
+	}
+	return Principal{}, ErrNoCredentials
+}
+
+type principalKey struct{}
+
+// NewContext returns a context carrying the request's principal.
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the request's principal, if it was authenticated.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
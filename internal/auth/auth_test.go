@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"testing"
+)
+
+const testPolicy = `
+tokens:
+  - principal: alice
+    token: alice-secret
+  - principal: ops
+    token: ops-secret
+acl:
+  - principal: alice
+    prefix: "user:alice/"
+    allow: [read, write, delete]
+  - principal: "*"
+    prefix: "public/"
+    allow: [read]
+  - principal: svc
+    prefix: "cache/"
+    allow: [read, write]
+  - principal: ops
+    allow: [admin]
+  - principal: billing
+    namespace: invoices
+    allow: [read, write]
+  - principal: auditor
+    namespace: "*"
+    allow: [read]
+`
+
+func TestPolicy_Authenticate(t *testing.T) {
+	p, err := ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("ParsePolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		creds   Credentials
+		want    Principal
+		wantErr error
+	}{
+		{name: "token", creds: Credentials{Token: "alice-secret"}, want: Principal{Name: "alice", Method: "token"}},
+		{name: "unknown token", creds: Credentials{Token: "guess"}, wantErr: ErrInvalidCredentials},
+		{
+			name:  "client certificate",
+			creds: Credentials{Cert: &x509.Certificate{Subject: pkix.Name{CommonName: "svc"}}},
+			want:  Principal{Name: "svc", Method: "cert"},
+		},
+		{
+			name:  "token wins over certificate",
+			creds: Credentials{Token: "ops-secret", Cert: &x509.Certificate{Subject: pkix.Name{CommonName: "svc"}}},
+			want:  Principal{Name: "ops", Method: "token"},
+		},
+		{name: "no credentials", creds: Credentials{}, wantErr: ErrNoCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.Authenticate(tt.creds)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("Authenticate() = %+v, %v; want %+v, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestACL_Allowed(t *testing.T) {
+	p, err := ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		principal string
+		namespace string
+		key       string
+		perm      Permission
+		want      bool
+	}{
+		{"alice", "", "user:alice/profile", Read, true},
+		{"alice", "", "user:alice/profile", Delete, true},
+		{"alice", "", "user:bob/profile", Read, false},
+		{"alice", "", "public/news", Read, true},
+		{"alice", "", "public/news", Write, false},
+		{"bob", "", "public/news", Read, true},
+		{"svc", "", "cache/x", Read | Write, true},
+		{"svc", "", "cache/x", Delete, false},
+		{"alice", "", "", Admin, false},
+		{"ops", "", "", Admin, true},
+		{"ops", "", "user:alice/profile", Read, false},
+		// Scans are checked on their prefix
+		{"alice", "", "user:", Read, false},
+		{"alice", "", "user:alice/", Read, true},
+		// Rules apply to their namespace only
+		{"alice", "invoices", "user:alice/profile", Read, false},
+		{"billing", "invoices", "2024/1", Write, true},
+		{"billing", "", "2024/1", Read, false},
+		{"auditor", "invoices", "2024/1", Read, true},
+		{"auditor", "", "user:alice/profile", Read, true},
+		{"auditor", "invoices", "2024/1", Write, false},
+	}
+	for _, tt := range tests {
+		if got := p.ACL.Allowed(tt.principal, tt.namespace, tt.key, tt.perm); got != tt.want {
+			t.Errorf("Allowed(%s, %q, %q, %s) = %v, want %v", tt.principal, tt.namespace, tt.key, tt.perm, got, tt.want)
+		}
+	}
+}
+
+func TestParsePolicy_Errors(t *testing.T) {
+	tests := map[string]string{
+		"unknown permission": "acl:\n  - principal: a\n    allow: [execute]\n",
+		"missing allow":      "acl:\n  - principal: a\n    prefix: x\n",
+		"prefixed admin":     "acl:\n  - principal: a\n    prefix: x\n    allow: [admin]\n",
+		"namespaced admin":   "acl:\n  - principal: a\n    namespace: x\n    allow: [admin]\n",
+		"duplicate token":    "tokens:\n  - {principal: a, token: t}\n  - {principal: b, token: t}\n",
+		"missing token":      "tokens:\n  - principal: a\n",
+		"unknown field":      "users: []\n",
+	}
+	for name, input := range tests {
+		if _, err := ParsePolicy([]byte(input)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := ParsePolicy(nil); err != nil {
+		t.Errorf("Expected an empty policy to be valid (deny all), got %v", err)
+	}
+}
+
+func TestContext(t *testing.T) {
+	if _, ok := FromContext(context.Background()); ok {
+		t.Error("Expected no principal in an empty context")
+	}
+	ctx := NewContext(context.Background(), Principal{Name: "alice", Method: "token"})
+	if p, ok := FromContext(ctx); !ok || p.Name != "alice" {
+		t.Errorf("FromContext() = %+v, %v", p, ok)
+	}
+	if (Principal{}).String() != "anonymous" {
+		t.Errorf("Expected zero principal to print as anonymous")
+	}
+}
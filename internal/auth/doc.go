@@ -0,0 +1,5 @@
+// Package auth authenticates client requests and authorizes them against an
+// access control list. Clients authenticate with a static bearer token or a
+// TLS client certificate; the resulting principal is granted read, write,
+// delete or admin permissions on key prefixes.
+package auth
@@ -0,0 +1,5 @@
+// Package causal provides opaque, signed causal-context tokens. A token
+// wraps the vector clock covering every version a client has seen for a key,
+// so clients can echo it back on writes without understanding (or being able
+// to fabricate) clock internals.
+package causal
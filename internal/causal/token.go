@@ -0,0 +1,98 @@
+package causal
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"kvstore/internal/clock"
+)
+
+const (
+	// tokenV1 is the version tag of the current token format.
+	tokenV1 byte = 1
+	// macSize is the number of HMAC-SHA256 bytes kept in a token.
+	macSize = 16
+
+	// InsecureDefaultSecret is used when no secret is configured. It lets a
+	// development cluster work out of the box but offers no protection against
+	// forged contexts; production deployments must configure their own secret.
+	InsecureDefaultSecret = "kvstore-insecure-default-context-secret"
+)
+
+// ErrInvalidToken is returned when a token is malformed, was signed with a
+// different key, or was issued for a different data key.
+var ErrInvalidToken = errors.New("invalid causal context")
+
+// Signer issues and verifies causal-context tokens. All nodes of a cluster
+// must share the same secret so that a token issued by one coordinator is
+// accepted by any other.
+type Signer struct {
+	secret []byte
+}
+
+// NewSigner creates a signer using the given shared secret.
+func NewSigner(secret []byte) *Signer {
+	return &Signer{secret: append([]byte(nil), secret...)}
+}
+
+// Sign returns a token for vc, bound to the data key it was read from.
+//
+// Token format (v1), base64url without padding:
+//
+//	version byte   tokenV1
+//	clock   bytes  clock.Encode(vc, nil)
+//	mac     16 bytes HMAC-SHA256(secret, key || version || clock), truncated
+func (s *Signer) Sign(key string, vc clock.VectorClock) (string, error) {
+	if vc == nil {
+		vc = clock.New()
+	}
+	encoded, err := clock.Encode(vc, nil)
+	if err != nil {
+		return "", err
+	}
+
+	payload := make([]byte, 0, 1+len(encoded)+macSize)
+	payload = append(payload, tokenV1)
+	payload = append(payload, encoded...)
+	payload = append(payload, s.mac(key, payload)...)
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// Verify checks the integrity of token for the given data key and returns
+// the vector clock it carries.
+func (s *Signer) Verify(key string, token string) (clock.VectorClock, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: not base64url", ErrInvalidToken)
+	}
+	if len(payload) < 1+macSize {
+		return nil, fmt.Errorf("%w: too short", ErrInvalidToken)
+	}
+	if payload[0] != tokenV1 {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidToken, payload[0])
+	}
+
+	body, mac := payload[:len(payload)-macSize], payload[len(payload)-macSize:]
+	if !hmac.Equal(mac, s.mac(key, body)) {
+		return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+	}
+
+	vc, _, err := clock.Decode(body[1:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return vc, nil
+}
+
+// mac computes the truncated HMAC of body bound to key.
+func (s *Signer) mac(key string, body []byte) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	// Length-prefix the key so key/body boundaries are unambiguous
+	h.Write([]byte{byte(len(key) >> 24), byte(len(key) >> 16), byte(len(key) >> 8), byte(len(key))})
+	h.Write([]byte(key))
+	h.Write(body)
+	return h.Sum(nil)[:macSize]
+}
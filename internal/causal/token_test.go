@@ -0,0 +1,85 @@
+package causal
+
+import (
+	"errors"
+	"testing"
+
+	"kvstore/internal/clock"
+)
+
+func TestSigner_RoundTrip(t *testing.T) {
+	s := NewSigner([]byte("secret"))
+	vc := clock.VectorClock{"n1": 3, "n2": 1}
+
+	token, err := s.Sign("user:1", vc)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	got, err := s.Verify("user:1", token)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !got.Equal(vc) {
+		t.Errorf("Expected %v, got %v", vc, got)
+	}
+}
+
+func TestSigner_SharedSecretAcrossNodes(t *testing.T) {
+	token, err := NewSigner([]byte("cluster-secret")).Sign("k", clock.VectorClock{"n1": 1})
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if _, err := NewSigner([]byte("cluster-secret")).Verify("k", token); err != nil {
+		t.Errorf("Expected token to verify on another node with the same secret: %v", err)
+	}
+}
+
+func TestSigner_RejectsTampering(t *testing.T) {
+	s := NewSigner([]byte("secret"))
+	token, err := s.Sign("user:1", clock.VectorClock{"n1": 1})
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	// Forged clock signed with a different secret
+	forged, _ := NewSigner([]byte("attacker")).Sign("user:1", clock.VectorClock{"n1": 1000})
+
+	// Flip a byte in the clock payload
+	raw := []byte(token)
+	raw[3] ^= 0x01
+
+	tests := map[string]struct {
+		key   string
+		token string
+	}{
+		"wrong key":      {"user:2", token},
+		"forged secret":  {"user:1", forged},
+		"modified bytes": {"user:1", string(raw)},
+		"not base64":     {"user:1", "!!!"},
+		"too short":      {"user:1", "AQ"},
+		"empty":          {"user:1", ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.Verify(tt.key, tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Expected ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSigner_NilClock(t *testing.T) {
+	s := NewSigner([]byte("secret"))
+	token, err := s.Sign("k", nil)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	vc, err := s.Verify("k", token)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(vc) != 0 {
+		t.Errorf("Expected empty clock, got %v", vc)
+	}
+}
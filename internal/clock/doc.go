@@ -1,5 +1,6 @@
 // Package clock provides vector clock implementation for tracking causality
 // in distributed operations. Vector clocks enable conflict detection and
 // resolution by maintaining per-node counters that capture happened-before
-// relationships.
+// relationships. It also provides a hybrid logical clock (HLC) that assigns
+// wall-clock-ordered timestamps to versions.
 package clock
@@ -0,0 +1,174 @@
+package clock
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+const (
+	// EncodingV1 is the version tag of the current binary clock encoding.
+	EncodingV1 byte = 1
+
+	// flagEntryTimes marks an encoding that carries per-entry times.
+	flagEntryTimes byte = 1 << 0
+)
+
+// ErrUnsupportedEncoding is returned when decoding data written with an
+// unknown encoding version or flags (e.g. by a newer release).
+var ErrUnsupportedEncoding = errors.New("unsupported clock encoding")
+
+// Encode returns a compact, deterministic binary encoding of vc and,
+// if times is non-nil, its per-entry times.
+//
+// Format (v1):
+//
+//	version  byte     EncodingV1
+//	flags    byte     bit 0: entry times present
+//	count    uvarint  number of entries
+//	dict     count × (uvarint length, bytes)  node IDs, sorted
+//	counters count × uvarint                  in dictionary order
+//	times    count × varint                   optional; first absolute (Unix ms),
+//	                                          then delta from the previous entry
+func Encode(vc VectorClock, times EntryTimes) ([]byte, error) {
+	ids := make([]string, 0, len(vc))
+	for nodeID, counter := range vc {
+		if counter < 0 {
+			return nil, fmt.Errorf("cannot encode negative counter %d for node %s", counter, nodeID)
+		}
+		ids = append(ids, nodeID)
+	}
+	sort.Strings(ids)
+
+	var flags byte
+	if times != nil {
+		flags |= flagEntryTimes
+	}
+
+	buf := make([]byte, 0, 2+binary.MaxVarintLen64*(1+2*len(ids)))
+	buf = append(buf, EncodingV1, flags)
+	buf = binary.AppendUvarint(buf, uint64(len(ids)))
+	for _, nodeID := range ids {
+		buf = binary.AppendUvarint(buf, uint64(len(nodeID)))
+		buf = append(buf, nodeID...)
+	}
+	for _, nodeID := range ids {
+		buf = binary.AppendUvarint(buf, uint64(vc[nodeID]))
+	}
+	if times != nil {
+		var prev int64
+		for _, nodeID := range ids {
+			t := times[nodeID]
+			buf = binary.AppendVarint(buf, t-prev)
+			prev = t
+		}
+	}
+	return buf, nil
+}
+
+// Decode decodes data produced by Encode. times is nil if the encoding
+// carries no per-entry times.
+func Decode(data []byte) (VectorClock, EntryTimes, error) {
+	vc, times, n, err := decode(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if n != len(data) {
+		return nil, nil, fmt.Errorf("clock encoding has %d trailing bytes", len(data)-n)
+	}
+	return vc, times, nil
+}
+
+// DecodePrefix decodes a clock encoding at the start of data and returns the
+// number of bytes consumed, for embedding clocks in larger records.
+func DecodePrefix(data []byte) (VectorClock, EntryTimes, int, error) {
+	return decode(data)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (vc VectorClock) MarshalBinary() ([]byte, error) {
+	return Encode(vc, nil)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (vc *VectorClock) UnmarshalBinary(data []byte) error {
+	decoded, _, err := Decode(data)
+	if err != nil {
+		return err
+	}
+	*vc = decoded
+	return nil
+}
+
+// decode decodes a clock at the start of data and returns the bytes consumed.
+func decode(data []byte) (VectorClock, EntryTimes, int, error) {
+	if len(data) < 2 {
+		return nil, nil, 0, fmt.Errorf("clock encoding too short: %d bytes", len(data))
+	}
+	if data[0] != EncodingV1 {
+		return nil, nil, 0, fmt.Errorf("%w: version %d", ErrUnsupportedEncoding, data[0])
+	}
+	flags := data[1]
+	if flags&^flagEntryTimes != 0 {
+		return nil, nil, 0, fmt.Errorf("%w: flags %#x", ErrUnsupportedEncoding, flags)
+	}
+	pos := 2
+
+	readUvarint := func() (uint64, error) {
+		v, n := binary.Uvarint(data[pos:])
+		if n <= 0 {
+			return 0, fmt.Errorf("malformed varint at offset %d", pos)
+		}
+		pos += n
+		return v, nil
+	}
+
+	count, err := readUvarint()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	// Every entry takes at least two bytes (empty ID + counter)
+	if count > uint64(len(data)-pos)/2 {
+		return nil, nil, 0, fmt.Errorf("clock encoding declares %d entries in %d bytes", count, len(data)-pos)
+	}
+
+	ids := make([]string, count)
+	for i := range ids {
+		length, err := readUvarint()
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		if length > uint64(len(data)-pos) {
+			return nil, nil, 0, fmt.Errorf("node ID length %d exceeds remaining %d bytes", length, len(data)-pos)
+		}
+		ids[i] = string(data[pos : pos+int(length)])
+		pos += int(length)
+	}
+
+	vc := make(VectorClock, count)
+	for _, nodeID := range ids {
+		counter, err := readUvarint()
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		vc[nodeID] = int64(counter)
+	}
+
+	var times EntryTimes
+	if flags&flagEntryTimes != 0 {
+		times = make(EntryTimes, count)
+		var prev int64
+		for _, nodeID := range ids {
+			delta, n := binary.Varint(data[pos:])
+			if n <= 0 {
+				return nil, nil, 0, fmt.Errorf("malformed varint at offset %d", pos)
+			}
+			pos += n
+			prev += delta
+			times[nodeID] = prev
+		}
+	}
+
+	return vc, times, pos, nil
+}
@@ -0,0 +1,123 @@
+package clock
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// checkGolden compares got with testdata/name, rewriting it with -update.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("encoding does not match %s:\n got  %x\n want %x", path, got, want)
+	}
+}
+
+func TestEncode_Golden(t *testing.T) {
+	tests := []struct {
+		golden string
+		vc     VectorClock
+		times  EntryTimes
+	}{
+		{"clock_v1_empty.golden", New(), nil},
+		{"clock_v1_three.golden", VectorClock{"n3": 1, "n1": 300, "n2": 7}, nil},
+		{"clock_v1_times.golden", VectorClock{"n1": 2, "n2": 1}, EntryTimes{"n1": 1700000000000, "n2": 1700000000250}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			data, err := Encode(tt.vc, tt.times)
+			if err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+			checkGolden(t, tt.golden, data)
+
+			vc, times, err := Decode(data)
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			if !vc.Equal(tt.vc) {
+				t.Errorf("Round trip clock mismatch: got %v, want %v", vc, tt.vc)
+			}
+			if len(times) != len(tt.times) {
+				t.Fatalf("Round trip times mismatch: got %v, want %v", times, tt.times)
+			}
+			for k, v := range tt.times {
+				if times[k] != v {
+					t.Errorf("Round trip time for %s: got %d, want %d", k, times[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestEncode_Deterministic(t *testing.T) {
+	vc := VectorClock{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5}
+	first, err := vc.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		data, _ := vc.Copy().MarshalBinary()
+		if !bytes.Equal(data, first) {
+			t.Fatalf("Encoding is not deterministic: %x vs %x", data, first)
+		}
+	}
+}
+
+func TestEncode_SmallerThanNaive(t *testing.T) {
+	vc := VectorClock{"node-a": 10, "node-b": 20, "node-c": 30}
+	data, err := vc.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	// IDs (18 bytes) + 1 length byte and 1 counter byte each + 3 header bytes
+	if len(data) != 27 {
+		t.Errorf("Expected 27 bytes, got %d", len(data))
+	}
+}
+
+func TestDecode_RejectsUnknownVersion(t *testing.T) {
+	data, _ := VectorClock{"n1": 1}.MarshalBinary()
+	data[0] = EncodingV1 + 1
+
+	var vc VectorClock
+	err := vc.UnmarshalBinary(data)
+	if !errors.Is(err, ErrUnsupportedEncoding) {
+		t.Errorf("Expected ErrUnsupportedEncoding, got %v", err)
+	}
+}
+
+func TestDecode_RejectsMalformed(t *testing.T) {
+	valid, _ := VectorClock{"n1": 1, "n2": 2}.MarshalBinary()
+	for i := 0; i < len(valid); i++ {
+		if _, _, err := Decode(valid[:i]); err == nil {
+			t.Errorf("Expected error decoding %d-byte prefix", i)
+		}
+	}
+	if _, _, err := Decode(append(valid, 0)); err == nil {
+		t.Error("Expected error for trailing bytes")
+	}
+}
+
+func TestEncode_RejectsNegativeCounter(t *testing.T) {
+	if _, err := Encode(VectorClock{"n1": -1}, nil); err == nil {
+		t.Error("Expected error for negative counter")
+	}
+}
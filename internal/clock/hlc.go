@@ -0,0 +1,149 @@
+package clock
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxOffset is the default maximum tolerated distance between a
+	// remote timestamp and the local physical clock.
+	DefaultMaxOffset = 500 * time.Millisecond
+)
+
+// Timestamp is a hybrid logical clock timestamp. WallTime is a Unix time in
+// milliseconds; Logical orders events that share the same WallTime. NodeID
+// identifies the node that issued the timestamp and is used only as a
+// deterministic tie-breaker (see Newer).
+type Timestamp struct {
+	WallTime int64
+	Logical  int32
+	NodeID   string
+}
+
+// IsZero returns true if the timestamp has not been set.
+func (t Timestamp) IsZero() bool {
+	return t.WallTime == 0 && t.Logical == 0
+}
+
+// Compare returns -1 if t is before other, 1 if t is after other, and 0 if
+// they are equal. NodeID is not considered.
+func (t Timestamp) Compare(other Timestamp) int {
+	switch {
+	case t.WallTime < other.WallTime:
+		return -1
+	case t.WallTime > other.WallTime:
+		return 1
+	case t.Logical < other.Logical:
+		return -1
+	case t.Logical > other.Logical:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Before returns true if t happened before other.
+func (t Timestamp) Before(other Timestamp) bool {
+	return t.Compare(other) < 0
+}
+
+// Newer returns true if t wins over other under last-write-wins ordering:
+// the later timestamp wins, and equal timestamps are broken by the larger
+// node ID so that every node picks the same winner.
+func (t Timestamp) Newer(other Timestamp) bool {
+	if c := t.Compare(other); c != 0 {
+		return c > 0
+	}
+	return t.NodeID > other.NodeID
+}
+
+// Time returns the physical component of the timestamp as a time.Time.
+func (t Timestamp) Time() time.Time {
+	return time.UnixMilli(t.WallTime)
+}
+
+// String returns a string representation of the timestamp.
+func (t Timestamp) String() string {
+	if t.NodeID == "" {
+		return fmt.Sprintf("%d.%d", t.WallTime, t.Logical)
+	}
+	return fmt.Sprintf("%d.%d@%s", t.WallTime, t.Logical, t.NodeID)
+}
+
+// HLC is a hybrid logical clock. It is advanced on every local event (Now)
+// and on receipt of remote timestamps (Update), producing timestamps that
+// respect causality while staying close to physical time.
+// It is safe for concurrent use.
+type HLC struct {
+	mu        sync.Mutex
+	nodeID    string
+	last      Timestamp
+	maxOffset time.Duration
+	physical  func() int64 // Unix milliseconds (overridable in tests)
+}
+
+// NewHLC creates a new hybrid logical clock whose timestamps are issued by
+// nodeID. Remote timestamps more than maxOffset ahead of the local physical
+// clock are rejected by Update.
+func NewHLC(nodeID string, maxOffset time.Duration) *HLC {
+	if maxOffset <= 0 {
+		maxOffset = DefaultMaxOffset
+	}
+	return &HLC{
+		nodeID:    nodeID,
+		last:      Timestamp{NodeID: nodeID},
+		maxOffset: maxOffset,
+		physical:  func() int64 { return time.Now().UnixMilli() },
+	}
+}
+
+// MaxOffset returns the maximum tolerated clock offset.
+func (h *HLC) MaxOffset() time.Duration {
+	return h.maxOffset
+}
+
+// Now advances the clock for a local event and returns the new timestamp.
+func (h *HLC) Now() Timestamp {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	pt := h.physical()
+	if pt > h.last.WallTime {
+		h.last = Timestamp{WallTime: pt, NodeID: h.nodeID}
+	} else {
+		h.last.Logical++
+	}
+	return h.last
+}
+
+// Update advances the clock on receipt of a remote timestamp and returns the
+// new local timestamp. Returns an error (without advancing the clock) if the
+// remote timestamp is more than MaxOffset ahead of the local physical clock.
+func (h *HLC) Update(remote Timestamp) (Timestamp, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	pt := h.physical()
+	if remote.WallTime-pt > h.maxOffset.Milliseconds() {
+		return h.last, fmt.Errorf("remote timestamp %s is %dms ahead of local clock (max offset %v)",
+			remote, remote.WallTime-pt, h.maxOffset)
+	}
+
+	switch {
+	case pt > h.last.WallTime && pt > remote.WallTime:
+		h.last = Timestamp{WallTime: pt, NodeID: h.nodeID}
+	case remote.WallTime > h.last.WallTime:
+		h.last = Timestamp{WallTime: remote.WallTime, Logical: remote.Logical + 1, NodeID: h.nodeID}
+	case h.last.WallTime > remote.WallTime:
+		h.last.Logical++
+	default:
+		// Equal wall times: take the larger logical counter
+		if remote.Logical > h.last.Logical {
+			h.last.Logical = remote.Logical
+		}
+		h.last.Logical++
+	}
+	return h.last, nil
+}
@@ -0,0 +1,146 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestHLC returns an HLC driven by the given physical time (Unix ms).
+func newTestHLC(pt *int64, maxOffset time.Duration) *HLC {
+	h := NewHLC("n1", maxOffset)
+	h.physical = func() int64 { return *pt }
+	return h
+}
+
+func TestHLC_Now_Monotonic(t *testing.T) {
+	pt := int64(1000)
+	h := newTestHLC(&pt, time.Second)
+
+	ts1 := h.Now()
+	if ts1.WallTime != 1000 || ts1.Logical != 0 {
+		t.Errorf("Expected 1000.0, got %s", ts1)
+	}
+	if ts1.NodeID != "n1" {
+		t.Errorf("Expected timestamp issued by n1, got %q", ts1.NodeID)
+	}
+
+	// Physical clock does not move: logical counter advances
+	ts2 := h.Now()
+	if !ts1.Before(ts2) {
+		t.Errorf("Expected %s before %s", ts1, ts2)
+	}
+	if ts2.Logical != 1 {
+		t.Errorf("Expected logical 1, got %d", ts2.Logical)
+	}
+
+	// Physical clock goes backwards: still monotonic
+	pt = 900
+	ts3 := h.Now()
+	if !ts2.Before(ts3) {
+		t.Errorf("Expected %s before %s", ts2, ts3)
+	}
+
+	// Physical clock advances: logical counter resets
+	pt = 2000
+	ts4 := h.Now()
+	if ts4.WallTime != 2000 || ts4.Logical != 0 {
+		t.Errorf("Expected 2000.0, got %s", ts4)
+	}
+}
+
+func TestHLC_Update_AdvancesPastRemote(t *testing.T) {
+	pt := int64(1000)
+	h := newTestHLC(&pt, time.Second)
+	h.Now()
+
+	remote := Timestamp{WallTime: 1500, Logical: 3}
+	ts, err := h.Update(remote)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if !remote.Before(ts) {
+		t.Errorf("Expected %s after remote %s", ts, remote)
+	}
+
+	// Subsequent local events stay after the remote timestamp
+	next := h.Now()
+	if !ts.Before(next) {
+		t.Errorf("Expected %s before %s", ts, next)
+	}
+}
+
+func TestHLC_Update_EqualWallTime(t *testing.T) {
+	pt := int64(1000)
+	h := newTestHLC(&pt, time.Second)
+	h.Now()
+	h.Now() // 1000.1
+
+	ts, err := h.Update(Timestamp{WallTime: 1000, Logical: 5})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if ts.WallTime != 1000 || ts.Logical != 6 {
+		t.Errorf("Expected 1000.6, got %s", ts)
+	}
+}
+
+func TestHLC_Update_RejectsFutureTimestamp(t *testing.T) {
+	pt := int64(1000)
+	h := newTestHLC(&pt, 100*time.Millisecond)
+	before := h.Now()
+
+	_, err := h.Update(Timestamp{WallTime: 1200})
+	if err == nil {
+		t.Fatal("Expected error for timestamp beyond max offset")
+	}
+
+	// Clock must not have been advanced by the rejected timestamp
+	after := h.Now()
+	if after.WallTime != before.WallTime {
+		t.Errorf("Rejected timestamp advanced clock: %s -> %s", before, after)
+	}
+
+	// Within max offset is accepted
+	if _, err := h.Update(Timestamp{WallTime: 1100}); err != nil {
+		t.Errorf("Expected timestamp within max offset to be accepted: %v", err)
+	}
+}
+
+func TestTimestamp_Compare(t *testing.T) {
+	a := Timestamp{WallTime: 1, Logical: 2}
+	b := Timestamp{WallTime: 1, Logical: 3}
+	c := Timestamp{WallTime: 2, Logical: 0}
+
+	if a.Compare(b) != -1 || b.Compare(a) != 1 {
+		t.Error("Expected logical counter to order equal wall times")
+	}
+	if b.Compare(c) != -1 {
+		t.Error("Expected wall time to take precedence over logical counter")
+	}
+	if a.Compare(a) != 0 {
+		t.Error("Expected timestamp to equal itself")
+	}
+	if !(Timestamp{}).IsZero() || a.IsZero() {
+		t.Error("IsZero mismatch")
+	}
+}
+
+func TestTimestamp_Newer_TieBreakOnNodeID(t *testing.T) {
+	a := Timestamp{WallTime: 5, Logical: 1, NodeID: "n1"}
+	b := Timestamp{WallTime: 5, Logical: 1, NodeID: "n2"}
+
+	if !b.Newer(a) || a.Newer(b) {
+		t.Error("Expected larger node ID to win a timestamp tie")
+	}
+
+	// Time takes precedence over node ID
+	c := Timestamp{WallTime: 6, NodeID: "n0"}
+	if !c.Newer(b) {
+		t.Error("Expected later timestamp to win regardless of node ID")
+	}
+
+	// A timestamp is never newer than itself
+	if a.Newer(a) {
+		t.Error("Expected timestamp not to be newer than itself")
+	}
+}
@@ -0,0 +1,227 @@
+package clock
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EntryTimes records, per node ID, when a vector clock entry was last
+// advanced (Unix milliseconds). It is kept alongside a VectorClock to drive
+// age-based pruning.
+type EntryTimes map[string]int64
+
+// Copy creates a deep copy of the entry times.
+func (et EntryTimes) Copy() EntryTimes {
+	if et == nil {
+		return nil
+	}
+	copy := make(EntryTimes, len(et))
+	for k, v := range et {
+		copy[k] = v
+	}
+	return copy
+}
+
+// Advance returns entry times for next: entries whose counter grew relative
+// to prev (or that are new) are stamped with nowMs, the rest keep their time
+// from times. Entries not present in next are dropped.
+func (et EntryTimes) Advance(prev, next VectorClock, nowMs int64) EntryTimes {
+	out := make(EntryTimes, len(next))
+	for nodeID, counter := range next {
+		if t, ok := et[nodeID]; ok && counter <= prev[nodeID] {
+			out[nodeID] = t
+		} else {
+			out[nodeID] = nowMs
+		}
+	}
+	return out
+}
+
+// PruneConfig configures vector clock pruning. An entry is only ever removed
+// if the clock has more than MinEntries entries and the entry is older than
+// MinAge; beyond that, the oldest entries are removed while the clock has
+// more than MaxEntries entries or while they are older than MaxAge.
+// A zero MaxEntries or MaxAge disables that threshold.
+type PruneConfig struct {
+	MinEntries int           // Never prune clocks at or below this size
+	MaxEntries int           // Prune oldest entries down to this size
+	MinAge     time.Duration // Never prune entries advanced more recently than this
+	MaxAge     time.Duration // Prune entries not advanced for longer than this
+}
+
+// DefaultPruneConfig returns conservative pruning thresholds: clocks are
+// only pruned beyond 50 entries or for entries idle for more than a day,
+// never below 10 entries, and never for entries touched in the last 20s.
+func DefaultPruneConfig() PruneConfig {
+	return PruneConfig{
+		MinEntries: 10,
+		MaxEntries: 50,
+		MinAge:     20 * time.Second,
+		MaxAge:     24 * time.Hour,
+	}
+}
+
+// Enabled returns true if any pruning threshold is set.
+func (c PruneConfig) Enabled() bool {
+	return c.MaxEntries > 0 || c.MaxAge > 0
+}
+
+// Validate returns an error if a threshold is negative or a maximum is
+// below its minimum.
+func (c PruneConfig) Validate() error {
+	if c.MinEntries < 0 || c.MaxEntries < 0 || c.MinAge < 0 || c.MaxAge < 0 {
+		return fmt.Errorf("clock prune thresholds must not be negative, got %+v", c)
+	}
+	if c.MaxEntries > 0 && c.MaxEntries < c.MinEntries {
+		return fmt.Errorf("clock prune max entries (%d) must be at least min entries (%d)", c.MaxEntries, c.MinEntries)
+	}
+	if c.MaxAge > 0 && c.MaxAge < c.MinAge {
+		return fmt.Errorf("clock prune max age (%v) must be at least min age (%v)", c.MaxAge, c.MinAge)
+	}
+	return nil
+}
+
+// SizeBucketBounds are the upper bounds of the clock size histogram buckets.
+// The last bucket counts every clock larger than the final bound.
+var SizeBucketBounds = []int{1, 2, 4, 8, 16, 32, 64, 128}
+
+// PruneStats summarizes clock sizes observed on write and pruning activity.
+type PruneStats struct {
+	Observed      uint64   // Clocks observed (one per write)
+	PrunedClocks  uint64   // Clocks that had at least one entry removed
+	PrunedEntries uint64   // Total entries removed
+	MaxSize       int      // Largest clock observed (before pruning)
+	SizeSum       uint64   // Sum of sizes after pruning
+	SizeBuckets   []uint64 // Histogram of sizes after pruning (see SizeBucketBounds)
+}
+
+// Pruner applies a PruneConfig to vector clocks and records statistics.
+// It is safe for concurrent use.
+type Pruner struct {
+	mu    sync.Mutex
+	cfg   PruneConfig
+	stats PruneStats
+}
+
+// NewPruner creates a new pruner with the given configuration.
+func NewPruner(cfg PruneConfig) *Pruner {
+	return &Pruner{
+		cfg: cfg,
+		stats: PruneStats{
+			SizeBuckets: make([]uint64, len(SizeBucketBounds)+1),
+		},
+	}
+}
+
+// Config returns the pruner's configuration.
+func (p *Pruner) Config() PruneConfig {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cfg
+}
+
+// SetConfig replaces the pruner's configuration. Clocks pruned afterwards
+// use the new thresholds; statistics are kept.
+func (p *Pruner) SetConfig(cfg PruneConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cfg = cfg
+}
+
+// Prune removes entries from vc (and times) according to the configuration,
+// oldest first, and returns the removed node IDs. Entries with no recorded
+// time have unknown age: they are never removed by MaxAge and are removed
+// first when enforcing MaxEntries.
+// Pruning can make a clock appear concurrent with (rather than after) clocks
+// that still carry the removed entries, creating false conflicts.
+func (p *Pruner) Prune(vc VectorClock, times EntryTimes, now time.Time) []string {
+	cfg := p.Config()
+	before := len(vc)
+	var removed []string
+	if cfg.Enabled() && len(vc) > cfg.MinEntries {
+		removed = prune(cfg, vc, times, now)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stats.Observed++
+	if before > p.stats.MaxSize {
+		p.stats.MaxSize = before
+	}
+	if len(removed) > 0 {
+		p.stats.PrunedClocks++
+		p.stats.PrunedEntries += uint64(len(removed))
+	}
+	p.stats.SizeSum += uint64(len(vc))
+	p.stats.SizeBuckets[sizeBucket(len(vc))]++
+	return removed
+}
+
+// prune removes entries from vc and times according to cfg and returns the removed node IDs.
+func prune(cfg PruneConfig, vc VectorClock, times EntryTimes, now time.Time) []string {
+	nowMs := now.UnixMilli()
+
+	// Oldest first; unknown age sorts first, ties broken by node ID
+	ids := make([]string, 0, len(vc))
+	for nodeID := range vc {
+		ids = append(ids, nodeID)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		ti, okI := times[ids[i]]
+		tj, okJ := times[ids[j]]
+		if okI != okJ {
+			return !okI
+		}
+		if ti != tj {
+			return ti < tj
+		}
+		return ids[i] < ids[j]
+	})
+
+	var removed []string
+	for _, nodeID := range ids {
+		if len(vc) <= cfg.MinEntries {
+			break
+		}
+
+		t, known := times[nodeID]
+		age := time.Duration(nowMs-t) * time.Millisecond
+		if known && age < cfg.MinAge {
+			// Entries are sorted oldest first: everything after is younger
+			break
+		}
+
+		tooBig := cfg.MaxEntries > 0 && len(vc) > cfg.MaxEntries
+		tooOld := known && cfg.MaxAge > 0 && age > cfg.MaxAge
+		if !tooBig && !tooOld {
+			continue
+		}
+
+		delete(vc, nodeID)
+		delete(times, nodeID)
+		removed = append(removed, nodeID)
+	}
+	return removed
+}
+
+// Stats returns a snapshot of the pruner's statistics.
+func (p *Pruner) Stats() PruneStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := p.stats
+	stats.SizeBuckets = append([]uint64(nil), p.stats.SizeBuckets...)
+	return stats
+}
+
+// sizeBucket returns the histogram bucket index for a clock of the given size.
+func sizeBucket(size int) int {
+	for i, bound := range SizeBucketBounds {
+		if size <= bound {
+			return i
+		}
+	}
+	return len(SizeBucketBounds)
+}
@@ -0,0 +1,199 @@
+package clock
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// buildClock returns a clock with n entries n0..n(n-1), where entry i was
+// last advanced i minutes before now.
+func buildClock(n int, now time.Time) (VectorClock, EntryTimes) {
+	vc := New()
+	times := make(EntryTimes)
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("n%d", i)
+		vc.Set(id, int64(i+1))
+		times[id] = now.Add(-time.Duration(i) * time.Minute).UnixMilli()
+	}
+	return vc, times
+}
+
+func TestPruner_MaxEntriesRemovesOldest(t *testing.T) {
+	now := time.Now()
+	vc, times := buildClock(6, now)
+	p := NewPruner(PruneConfig{MinEntries: 2, MaxEntries: 4})
+
+	removed := p.Prune(vc, times, now)
+
+	if len(vc) != 4 {
+		t.Fatalf("Expected 4 entries after pruning, got %d", len(vc))
+	}
+	// n5 and n4 are the oldest entries
+	if len(removed) != 2 || removed[0] != "n5" || removed[1] != "n4" {
+		t.Errorf("Expected [n5 n4] removed, got %v", removed)
+	}
+	if _, ok := times["n5"]; ok {
+		t.Error("Expected entry time to be removed with the entry")
+	}
+}
+
+func TestPruner_RespectsMinAge(t *testing.T) {
+	now := time.Now()
+	vc, times := buildClock(6, now)
+	// Every entry is younger than MinAge: nothing may be pruned
+	p := NewPruner(PruneConfig{MinEntries: 1, MaxEntries: 2, MinAge: time.Hour})
+
+	if removed := p.Prune(vc, times, now); len(removed) != 0 {
+		t.Errorf("Expected no entries removed, got %v", removed)
+	}
+	if len(vc) != 6 {
+		t.Errorf("Expected 6 entries, got %d", len(vc))
+	}
+}
+
+func TestPruner_RespectsMinEntries(t *testing.T) {
+	now := time.Now()
+	vc, times := buildClock(6, now)
+	p := NewPruner(PruneConfig{MinEntries: 5, MaxAge: time.Second})
+
+	p.Prune(vc, times, now)
+
+	if len(vc) != 5 {
+		t.Errorf("Expected pruning to stop at MinEntries=5, got %d", len(vc))
+	}
+}
+
+func TestPruner_MaxAge(t *testing.T) {
+	now := time.Now()
+	vc, times := buildClock(6, now)
+	p := NewPruner(PruneConfig{MaxAge: 150 * time.Second})
+
+	removed := p.Prune(vc, times, now)
+
+	// n3, n4, n5 were last advanced 3+ minutes ago
+	if len(removed) != 3 {
+		t.Errorf("Expected 3 entries removed, got %v", removed)
+	}
+	for _, id := range []string{"n0", "n1", "n2"} {
+		if vc.Get(id) == 0 {
+			t.Errorf("Expected recent entry %s to be kept", id)
+		}
+	}
+}
+
+func TestPruner_UnknownAgeOnlyPrunedBySize(t *testing.T) {
+	now := time.Now()
+	vc := VectorClock{"a": 1, "b": 1, "c": 1}
+	times := EntryTimes{"c": now.UnixMilli()}
+
+	// Age threshold alone never removes entries of unknown age
+	p := NewPruner(PruneConfig{MaxAge: time.Millisecond})
+	if removed := p.Prune(vc, times, now.Add(time.Hour)); len(removed) != 1 || removed[0] != "c" {
+		t.Errorf("Expected only c to be removed by age, got %v", removed)
+	}
+
+	// Size threshold removes unknown-age entries first
+	vc = VectorClock{"a": 1, "b": 1, "c": 1}
+	times = EntryTimes{"c": now.UnixMilli()}
+	p = NewPruner(PruneConfig{MaxEntries: 2})
+	if removed := p.Prune(vc, times, now); len(removed) != 1 || removed[0] != "a" {
+		t.Errorf("Expected a to be removed by size, got %v", removed)
+	}
+}
+
+func TestPruner_DisabledObservesOnly(t *testing.T) {
+	now := time.Now()
+	vc, times := buildClock(100, now)
+	p := NewPruner(PruneConfig{})
+
+	if removed := p.Prune(vc, times, now); len(removed) != 0 {
+		t.Errorf("Expected disabled pruner to remove nothing, got %d entries", len(removed))
+	}
+
+	stats := p.Stats()
+	if stats.Observed != 1 || stats.PrunedClocks != 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if stats.MaxSize != 100 {
+		t.Errorf("Expected max size 100, got %d", stats.MaxSize)
+	}
+	// 100 entries falls in the <=128 bucket
+	if stats.SizeBuckets[7] != 1 {
+		t.Errorf("Expected size recorded in <=128 bucket, got %v", stats.SizeBuckets)
+	}
+}
+
+func TestPruner_SetConfig(t *testing.T) {
+	now := time.Now()
+	p := NewPruner(PruneConfig{})
+	vc, times := buildClock(6, now)
+	p.Prune(vc, times, now)
+
+	p.SetConfig(PruneConfig{MinEntries: 2, MaxEntries: 4})
+	if removed := p.Prune(vc, times, now); len(removed) != 2 {
+		t.Errorf("Expected the new config to remove 2 entries, got %v", removed)
+	}
+	if stats := p.Stats(); stats.Observed != 2 || stats.PrunedEntries != 2 {
+		t.Errorf("Expected stats to be kept across SetConfig, got %+v", stats)
+	}
+}
+
+func TestPruneConfig_Validate(t *testing.T) {
+	tests := []struct {
+		cfg   PruneConfig
+		valid bool
+	}{
+		{DefaultPruneConfig(), true},
+		{PruneConfig{}, true},
+		{PruneConfig{MinEntries: 10}, true},
+		{PruneConfig{MinEntries: -1}, false},
+		{PruneConfig{MinEntries: 10, MaxEntries: 5}, false},
+		{PruneConfig{MinAge: time.Hour, MaxAge: time.Minute}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.Validate(); (err == nil) != tt.valid {
+			t.Errorf("Validate(%+v) = %v, want valid %v", tt.cfg, err, tt.valid)
+		}
+	}
+}
+
+func TestPruner_Stats(t *testing.T) {
+	now := time.Now()
+	p := NewPruner(PruneConfig{MaxEntries: 3})
+
+	vc, times := buildClock(5, now)
+	p.Prune(vc, times, now)
+	vc, times = buildClock(2, now)
+	p.Prune(vc, times, now)
+
+	stats := p.Stats()
+	if stats.Observed != 2 {
+		t.Errorf("Expected 2 observed, got %d", stats.Observed)
+	}
+	if stats.PrunedClocks != 1 || stats.PrunedEntries != 2 {
+		t.Errorf("Expected 1 pruned clock / 2 entries, got %d / %d", stats.PrunedClocks, stats.PrunedEntries)
+	}
+	// Sizes after pruning: 3 (<=4 bucket) and 2 (<=2 bucket)
+	if stats.SizeBuckets[2] != 1 || stats.SizeBuckets[1] != 1 || stats.SizeSum != 5 {
+		t.Errorf("Unexpected size buckets: %v (sum %d)", stats.SizeBuckets, stats.SizeSum)
+	}
+}
+
+func TestEntryTimes_Advance(t *testing.T) {
+	prev := VectorClock{"n1": 1, "n2": 3}
+	next := VectorClock{"n1": 2, "n2": 3, "n3": 1}
+	times := EntryTimes{"n1": 100, "n2": 200}
+
+	out := times.Advance(prev, next, 500)
+
+	if out["n1"] != 500 {
+		t.Errorf("Expected advanced entry n1 stamped 500, got %d", out["n1"])
+	}
+	if out["n2"] != 200 {
+		t.Errorf("Expected unchanged entry n2 to keep 200, got %d", out["n2"])
+	}
+	if out["n3"] != 500 {
+		t.Errorf("Expected new entry n3 stamped 500, got %d", out["n3"])
+	}
+}
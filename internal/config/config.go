@@ -1,24 +1,420 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
 	"strings"
+	"time"
 
+	"gopkg.in/yaml.v3"
+	"kvstore/internal/admission"
+	"kvstore/internal/clock"
+	"kvstore/internal/conflict"
+	"kvstore/internal/logging"
+	"kvstore/internal/ratelimit"
 	"kvstore/internal/ring"
 )
 
+// Membership modes
+const (
+	MembershipGossip = "gossip" // Peers are seeds; membership is discovered by gossip
+	MembershipStatic = "static" // Peers are the fixed ring
+)
+
+// Trace exporters
+const (
+	TraceExporterStdout = "stdout" // JSON lines on standard output
+	TraceExporterOTLP   = "otlp"   // OTLP over HTTP to Tracing.Endpoint
+)
+
 // Peer represents a peer node in the cluster.
 type Peer struct {
-	ID   string
-	Addr string
+	ID   string `yaml:"id"`
+	Addr string `yaml:"addr"`
+}
+
+// UnmarshalYAML accepts a peer as an "id=addr" string or an {id, addr} mapping.
+func (p *Peer) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		peers, err := ParsePeers(value.Value)
+		if err != nil {
+			return err
+		}
+		if len(peers) != 1 {
+			return fmt.Errorf("invalid peer: %q (expected id=addr)", value.Value)
+		}
+		*p = peers[0]
+		return nil
+	}
+	type plain Peer
+	return value.Decode((*plain)(p))
 }
 
 // Config holds the node configuration.
 type Config struct {
-	NodeID     string
-	ListenAddr string
-	Peers      []Peer
-	VNodes     int
+	NodeID     string `yaml:"node_id"`
+	ListenAddr string `yaml:"listen"`
+	Peers      []Peer `yaml:"peers"`
+	VNodes     int    `yaml:"vnodes"`
+
+	ReplicationFactor int `yaml:"rf"` // N
+	R                 int `yaml:"r"`  // Default read quorum
+	W                 int `yaml:"w"`  // Default write quorum
+
+	Membership string       `yaml:"membership"` // MembershipGossip or MembershipStatic
+	Gossip     GossipConfig `yaml:"gossip"`
+
+	ConflictPolicies string               `yaml:"conflict_policies"` // e.g. "cache/=lww"
+	ContextSecret    string               `yaml:"context_secret"`    // Shared by all nodes
+	Coordinator      string               `yaml:"coordinator"`       // "any" or "replica"
+	ReplicaTimeout   ReplicaTimeoutConfig `yaml:"replica_timeout"`
+	RateLimit        RateLimitConfig      `yaml:"rate_limit"`
+	Admission        AdmissionConfig      `yaml:"admission"`
+	ClockPrune       ClockPruneConfig     `yaml:"clock_prune"`
+
+	TLS      TLSConfig `yaml:"tls"`
+	AuthFile string    `yaml:"auth_file"` // Tokens and ACL (see auth.ParsePolicy); requires TLS
+
+	SnapshotDir     string `yaml:"snapshot_dir"`     // Directory Admin.Snapshot writes to ("" = disabled)
+	RestoreSnapshot string `yaml:"restore_snapshot"` // Snapshot file loaded at startup ("" = none)
+
+	MetricsAddr string        `yaml:"metrics_addr"` // HTTP address serving /metrics ("" = disabled)
+	Tracing     TracingConfig `yaml:"tracing"`
+	Log         LogConfig     `yaml:"log"`
+}
+
+// GossipConfig holds gossip failure detection timing.
+type GossipConfig struct {
+	ProbeInterval  time.Duration `yaml:"probe_interval"`
+	SuspectTimeout time.Duration `yaml:"suspect_timeout"`
+	DeadTimeout    time.Duration `yaml:"dead_timeout"`
+}
+
+// ReplicaTimeoutConfig holds per-replica RPC timeouts (see node.TimeoutConfig).
+type ReplicaTimeoutConfig struct {
+	Mode       string        `yaml:"mode"` // "fixed" or "adaptive"
+	Fixed      time.Duration `yaml:"fixed"`
+	Min        time.Duration `yaml:"min"`
+	Max        time.Duration `yaml:"max"`
+	Percentile float64       `yaml:"percentile"`
+}
+
+// RateLimits are the rates allowed to one client (see ratelimit.Limits).
+type RateLimits struct {
+	Requests     float64 `yaml:"requests"`      // Requests per second (0 = unlimited)
+	RequestBurst int     `yaml:"request_burst"` // 0 = one second's worth
+	Bytes        float64 `yaml:"bytes"`         // Bytes per second (0 = unlimited)
+	ByteBurst    int     `yaml:"byte_burst"`    // 0 = one second's worth
+}
+
+// RateLimitConfig holds the rate limits of every client, with overrides for
+// individual clients by principal or client ID.
+type RateLimitConfig struct {
+	RateLimits `yaml:",inline"`
+	Clients    map[string]RateLimits `yaml:"clients"`
+}
+
+// Config returns the limits in the form used by the node.
+func (c RateLimitConfig) Config() ratelimit.Config {
+	cfg := ratelimit.Config{Default: ratelimit.Limits(c.RateLimits)}
+	if len(c.Clients) > 0 {
+		cfg.Clients = make(map[string]ratelimit.Limits, len(c.Clients))
+		for name, l := range c.Clients {
+			cfg.Clients[name] = ratelimit.Limits(l)
+		}
+	}
+	return cfg
+}
+
+// AdmissionConfig bounds the work a node accepts (see admission.Config).
+type AdmissionConfig struct {
+	MaxRequests        int           `yaml:"max_requests"`         // Client requests coordinated at once (0 = unlimited)
+	MaxReplicaRequests int           `yaml:"max_replica_requests"` // Replica RPCs served at once (0 = unlimited)
+	MaxQueue           int           `yaml:"max_queue"`            // Requests waiting for a slot
+	TargetDelay        time.Duration `yaml:"target_delay"`         // Queueing delay above which waiting requests are shed
+	MaxWait            time.Duration `yaml:"max_wait"`             // Longest a request waits for a slot otherwise
+}
+
+// Coordinator returns the limits of client requests.
+func (c AdmissionConfig) Coordinator() admission.Config {
+	return admission.Config{MaxInFlight: c.MaxRequests, MaxQueue: c.MaxQueue, TargetDelay: c.TargetDelay, MaxWait: c.MaxWait}
+}
+
+// Replica returns the limits of replica RPCs.
+func (c AdmissionConfig) Replica() admission.Config {
+	return admission.Config{MaxInFlight: c.MaxReplicaRequests, MaxQueue: c.MaxQueue, TargetDelay: c.TargetDelay, MaxWait: c.MaxWait}
+}
+
+// ClockPruneConfig bounds the size and age of vector clock entries (see
+// clock.PruneConfig). A zero MaxEntries or MaxAge disables that limit.
+type ClockPruneConfig struct {
+	MinEntries int           `yaml:"min_entries"` // Never prune clocks at or below this size
+	MaxEntries int           `yaml:"max_entries"` // Prune oldest entries down to this size
+	MinAge     time.Duration `yaml:"min_age"`     // Never prune entries advanced more recently than this
+	MaxAge     time.Duration `yaml:"max_age"`     // Prune entries not advanced for longer than this
+}
+
+// Config returns the limits in the form used by the node.
+func (c ClockPruneConfig) Config() clock.PruneConfig {
+	return clock.PruneConfig(c)
+}
+
+// TLSConfig holds certificate paths. Setting Cert enables TLS for clients and
+// mutual TLS between nodes; certificates are reloaded when the files change.
+type TLSConfig struct {
+	Cert           string        `yaml:"cert"`            // Node certificate (CN or DNS name = node ID)
+	Key            string        `yaml:"key"`             // Private key of Cert
+	CA             string        `yaml:"ca"`              // CA signing node certificates
+	ClientCA       string        `yaml:"client_ca"`       // CA signing client certificates (optional)
+	ReloadInterval time.Duration `yaml:"reload_interval"` // How often the files are checked for changes
+}
+
+// Enabled reports whether TLS is configured.
+func (t TLSConfig) Enabled() bool {
+	return t.Cert != ""
+}
+
+// TracingConfig selects where traces are exported.
+type TracingConfig struct {
+	Exporter   string  `yaml:"exporter"`    // "", TraceExporterStdout or TraceExporterOTLP
+	Endpoint   string  `yaml:"endpoint"`    // OTLP/HTTP collector URL, e.g. http://localhost:4318
+	SampleRate float64 `yaml:"sample_rate"` // Fraction of new traces recorded
+}
+
+// LogConfig selects the log format, levels and sampling.
+type LogConfig struct {
+	Format      string            `yaml:"format"`       // logging.FormatText or logging.FormatJSON
+	Level       string            `yaml:"level"`        // debug, info, warn or error
+	Levels      map[string]string `yaml:"levels"`       // Level per subsystem, e.g. gossip: debug
+	SampleBurst int               `yaml:"sample_burst"` // Records below warn logged per message per second (0 = all)
+}
+
+// Config returns the levels and sampling in the form used by the node.
+func (c LogConfig) Config() (logging.Config, error) {
+	level, err := logging.ParseLevel(c.Level)
+	if err != nil {
+		return logging.Config{}, err
+	}
+	cfg := logging.Config{Level: level, SampleBurst: c.SampleBurst}
+	if len(c.Levels) > 0 {
+		cfg.Levels = make(map[string]slog.Level, len(c.Levels))
+		for name, value := range c.Levels {
+			if cfg.Levels[name], err = logging.ParseLevel(value); err != nil {
+				return logging.Config{}, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+	return cfg, cfg.Validate()
+}
+
+// Default returns the default configuration: a gossip node on :50051 with
+// N=3, R=2, W=2 and 128 virtual nodes. Gossip and replica timeout defaults
+// match node.DefaultGossipConfig and node.DefaultTimeoutConfig.
+func Default() *Config {
+	return &Config{
+		ListenAddr:        ":50051",
+		VNodes:            128,
+		ReplicationFactor: 3,
+		R:                 2,
+		W:                 2,
+		Membership:        MembershipGossip,
+		Gossip: GossipConfig{
+			ProbeInterval:  1 * time.Second,
+			SuspectTimeout: 3 * time.Second,
+			DeadTimeout:    10 * time.Second,
+		},
+		Coordinator: "any",
+		ReplicaTimeout: ReplicaTimeoutConfig{
+			Mode:       "fixed",
+			Fixed:      2 * time.Second,
+			Min:        50 * time.Millisecond,
+			Max:        2 * time.Second,
+			Percentile: 0.99,
+		},
+		Admission: AdmissionConfig{
+			MaxRequests:        1024,
+			MaxReplicaRequests: 2048,
+			MaxQueue:           1024,
+			TargetDelay:        20 * time.Millisecond,
+			MaxWait:            1 * time.Second,
+		},
+		ClockPrune: ClockPruneConfig(clock.DefaultPruneConfig()),
+		TLS: TLSConfig{
+			ReloadInterval: 1 * time.Minute,
+		},
+		Tracing: TracingConfig{
+			SampleRate: 1,
+		},
+		Log: LogConfig{
+			Format:      logging.FormatText,
+			Level:       "info",
+			SampleBurst: logging.DefaultSampleBurst,
+		},
+	}
+}
+
+// Validate checks that the configuration is complete and consistent.
+// Replica timeouts and the coordinator policy are validated by the node.
+func (c *Config) Validate() error {
+	if c.NodeID == "" {
+		return errors.New("node ID is required")
+	}
+	if c.ListenAddr == "" {
+		return errors.New("listen address is required")
+	}
+	if c.MetricsAddr != "" && c.MetricsAddr == c.ListenAddr {
+		return fmt.Errorf("metrics address must differ from the listen address %s", c.ListenAddr)
+	}
+	if c.VNodes <= 0 {
+		return fmt.Errorf("vnodes must be positive, got %d", c.VNodes)
+	}
+	if c.ReplicationFactor < 1 {
+		return fmt.Errorf("replication factor must be at least 1, got %d", c.ReplicationFactor)
+	}
+	if c.R < 1 || c.R > c.ReplicationFactor {
+		return fmt.Errorf("r must be between 1 and rf (%d), got %d", c.ReplicationFactor, c.R)
+	}
+	if c.W < 1 || c.W > c.ReplicationFactor {
+		return fmt.Errorf("w must be between 1 and rf (%d), got %d", c.ReplicationFactor, c.W)
+	}
+
+	seen := make(map[string]string, len(c.Peers))
+	for _, p := range c.Peers {
+		if addr, ok := seen[p.ID]; ok && addr != p.Addr {
+			return fmt.Errorf("peer %s listed with two addresses: %s and %s", p.ID, addr, p.Addr)
+		}
+		seen[p.ID] = p.Addr
+	}
+
+	switch c.Membership {
+	case MembershipGossip:
+		g := c.Gossip
+		if g.ProbeInterval <= 0 {
+			return fmt.Errorf("gossip probe interval must be positive, got %v", g.ProbeInterval)
+		}
+		if g.SuspectTimeout <= g.ProbeInterval {
+			return fmt.Errorf("gossip suspect timeout (%v) must exceed the probe interval (%v)", g.SuspectTimeout, g.ProbeInterval)
+		}
+		if g.DeadTimeout <= g.SuspectTimeout {
+			return fmt.Errorf("gossip dead timeout (%v) must exceed the suspect timeout (%v)", g.DeadTimeout, g.SuspectTimeout)
+		}
+	case MembershipStatic:
+		if len(c.BuildRingNodes()) < c.ReplicationFactor {
+			return fmt.Errorf("static membership has %d nodes, fewer than rf (%d)", len(c.BuildRingNodes()), c.ReplicationFactor)
+		}
+	default:
+		return fmt.Errorf("unknown membership mode %q (expected %s or %s)", c.Membership, MembershipGossip, MembershipStatic)
+	}
+
+	if _, err := conflict.ParsePolicies(c.ConflictPolicies); err != nil {
+		return fmt.Errorf("conflict policies: %w", err)
+	}
+	if err := c.RateLimit.Config().Validate(); err != nil {
+		return fmt.Errorf("rate limit: %w", err)
+	}
+	if err := c.Admission.Coordinator().Validate(); err != nil {
+		return err
+	}
+	if err := c.Admission.Replica().Validate(); err != nil {
+		return err
+	}
+	if err := c.ClockPrune.Config().Validate(); err != nil {
+		return err
+	}
+
+	switch c.Tracing.Exporter {
+	case "", TraceExporterStdout:
+	case TraceExporterOTLP:
+		if c.Tracing.Endpoint == "" {
+			return errors.New("tracing: the otlp exporter requires an endpoint")
+		}
+	default:
+		return fmt.Errorf("unknown trace exporter %q (expected %s or %s)", c.Tracing.Exporter, TraceExporterStdout, TraceExporterOTLP)
+	}
+	if c.Tracing.SampleRate < 0 || c.Tracing.SampleRate > 1 {
+		return fmt.Errorf("trace sample rate must be between 0 and 1, got %v", c.Tracing.SampleRate)
+	}
+
+	switch c.Log.Format {
+	case logging.FormatText, logging.FormatJSON:
+	default:
+		return fmt.Errorf("unknown log format %q (expected %s or %s)", c.Log.Format, logging.FormatText, logging.FormatJSON)
+	}
+	if _, err := c.Log.Config(); err != nil {
+		return fmt.Errorf("log: %w", err)
+	}
+
+	t := c.TLS
+	if t.Enabled() {
+		if t.Key == "" || t.CA == "" {
+			return errors.New("tls: cert requires key and ca")
+		}
+		if t.ReloadInterval <= 0 {
+			return fmt.Errorf("tls reload interval must be positive, got %v", t.ReloadInterval)
+		}
+	} else if t.Key != "" || t.CA != "" || t.ClientCA != "" {
+		return errors.New("tls: key, ca and client_ca require cert")
+	}
+	if c.AuthFile != "" && !t.Enabled() {
+		return errors.New("auth_file requires tls, so that credentials are encrypted and internal RPCs need a node certificate")
+	}
+	return nil
+}
+
+// RestartRequired returns the names of the settings that differ between c and
+// next and only take effect on restart. The others (quorums, gossip timing,
+// coordinator, replica timeouts, rate limits, admission limits, clock
+// pruning and log levels) can be reloaded at runtime, as can the contents of the TLS and auth files.
+func (c *Config) RestartRequired(next *Config) []string {
+	var names []string
+	if c.NodeID != next.NodeID {
+		names = append(names, "node_id")
+	}
+	if c.ListenAddr != next.ListenAddr {
+		names = append(names, "listen")
+	}
+	if !slices.Equal(c.Peers, next.Peers) {
+		names = append(names, "peers")
+	}
+	if c.VNodes != next.VNodes {
+		names = append(names, "vnodes")
+	}
+	if c.ReplicationFactor != next.ReplicationFactor {
+		names = append(names, "rf")
+	}
+	if c.Membership != next.Membership {
+		names = append(names, "membership")
+	}
+	if c.ConflictPolicies != next.ConflictPolicies {
+		names = append(names, "conflict_policies")
+	}
+	if c.ContextSecret != next.ContextSecret {
+		names = append(names, "context_secret")
+	}
+	if c.TLS != next.TLS {
+		names = append(names, "tls")
+	}
+	if c.AuthFile != next.AuthFile {
+		names = append(names, "auth_file")
+	}
+	if c.SnapshotDir != next.SnapshotDir {
+		names = append(names, "snapshot_dir")
+	}
+	if c.RestoreSnapshot != next.RestoreSnapshot {
+		names = append(names, "restore_snapshot")
+	}
+	if c.MetricsAddr != next.MetricsAddr {
+		names = append(names, "metrics_addr")
+	}
+	if c.Tracing != next.Tracing {
+		names = append(names, "tracing")
+	}
+	if c.Log.Format != next.Log.Format {
+		names = append(names, "log.format")
+	}
+	return names
 }
 
 // ParsePeers parses a comma-separated list of peers in the format:
@@ -58,6 +454,17 @@ func ParsePeers(peersStr string) ([]Peer, error) {
 	return peers, nil
 }
 
+// Seeds returns the peers other than self as ring nodes, for gossip membership.
+func (c *Config) Seeds() []ring.Node {
+	seeds := make([]ring.Node, 0, len(c.Peers))
+	for _, peer := range c.Peers {
+		if peer.ID != c.NodeID {
+			seeds = append(seeds, ring.Node{ID: peer.ID, Addr: peer.Addr})
+		}
+	}
+	return seeds
+}
+
 // BuildRingNodes converts config peers + self into ring.Node slice.
 // Includes self node in the list.
 func (c *Config) BuildRingNodes() []ring.Node {
@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 )
 
 func TestParsePeers(t *testing.T) {
@@ -105,3 +106,121 @@ func TestConfig_BuildRingNodes(t *testing.T) {
 		t.Error("Self node not found in ring nodes")
 	}
 }
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr bool
+	}{
+		{name: "defaults", modify: func(c *Config) {}},
+		{name: "r equals rf", modify: func(c *Config) { c.R = 3 }},
+		{name: "zero r", modify: func(c *Config) { c.R = 0 }, wantErr: true},
+		{name: "w above rf", modify: func(c *Config) { c.W = 4 }, wantErr: true},
+		{name: "zero vnodes", modify: func(c *Config) { c.VNodes = 0 }, wantErr: true},
+		{name: "suspect before probe", modify: func(c *Config) { c.Gossip.SuspectTimeout = c.Gossip.ProbeInterval }, wantErr: true},
+		{name: "dead before suspect", modify: func(c *Config) { c.Gossip.DeadTimeout = time.Second }, wantErr: true},
+		{name: "unknown membership", modify: func(c *Config) { c.Membership = "raft" }, wantErr: true},
+		{name: "static with too few nodes", modify: func(c *Config) { c.Membership = MembershipStatic }, wantErr: true},
+		{
+			name: "static with rf nodes",
+			modify: func(c *Config) {
+				c.Membership = MembershipStatic
+				c.Peers = []Peer{{ID: "n2", Addr: "b:1"}, {ID: "n3", Addr: "c:1"}}
+			},
+		},
+		{
+			name:    "peer with two addresses",
+			modify:  func(c *Config) { c.Peers = []Peer{{ID: "n2", Addr: "b:1"}, {ID: "n2", Addr: "b:2"}} },
+			wantErr: true,
+		},
+		{name: "bad conflict policy", modify: func(c *Config) { c.ConflictPolicies = "cache/=newest" }, wantErr: true},
+		{name: "rate limit", modify: func(c *Config) { c.RateLimit.Requests, c.RateLimit.Bytes = 100, 1<<20 }},
+		{name: "unlimited admission", modify: func(c *Config) { c.Admission.MaxRequests, c.Admission.MaxReplicaRequests = 0, 0 }},
+		{name: "admission target above max wait", modify: func(c *Config) { c.Admission.TargetDelay = 2 * time.Second }, wantErr: true},
+		{name: "clock pruning disabled", modify: func(c *Config) { c.ClockPrune.MaxEntries, c.ClockPrune.MaxAge = 0, 0 }},
+		{name: "clock prune max below min", modify: func(c *Config) { c.ClockPrune.MaxEntries = 5 }, wantErr: true},
+		{
+			name:    "negative client rate limit",
+			modify:  func(c *Config) { c.RateLimit.Clients = map[string]RateLimits{"batch": {Requests: -1}} },
+			wantErr: true,
+		},
+		{
+			name: "tls",
+			modify: func(c *Config) {
+				c.TLS = TLSConfig{Cert: "n1.crt", Key: "n1.key", CA: "ca.crt", ReloadInterval: time.Minute}
+			},
+		},
+		{name: "tls without ca", modify: func(c *Config) { c.TLS.Cert, c.TLS.Key = "n1.crt", "n1.key" }, wantErr: true},
+		{name: "tls ca without cert", modify: func(c *Config) { c.TLS.CA = "ca.crt" }, wantErr: true},
+		{
+			name: "auth with tls",
+			modify: func(c *Config) {
+				c.TLS = TLSConfig{Cert: "n1.crt", Key: "n1.key", CA: "ca.crt", ReloadInterval: time.Minute}
+				c.AuthFile = "auth.yaml"
+			},
+		},
+		{name: "auth without tls", modify: func(c *Config) { c.AuthFile = "auth.yaml" }, wantErr: true},
+		{name: "metrics address", modify: func(c *Config) { c.MetricsAddr = ":9100" }},
+		{name: "metrics on listen address", modify: func(c *Config) { c.MetricsAddr = c.ListenAddr }, wantErr: true},
+		{name: "stdout tracing", modify: func(c *Config) { c.Tracing.Exporter = TraceExporterStdout }},
+		{
+			name: "otlp tracing",
+			modify: func(c *Config) {
+				c.Tracing = TracingConfig{Exporter: TraceExporterOTLP, Endpoint: "http://localhost:4318", SampleRate: 0.1}
+			},
+		},
+		{name: "otlp without endpoint", modify: func(c *Config) { c.Tracing.Exporter = TraceExporterOTLP }, wantErr: true},
+		{name: "unknown exporter", modify: func(c *Config) { c.Tracing.Exporter = "jaeger" }, wantErr: true},
+		{name: "sample rate above 1", modify: func(c *Config) { c.Tracing.SampleRate = 1.5 }, wantErr: true},
+		{
+			name: "json logs with subsystem levels",
+			modify: func(c *Config) {
+				c.Log = LogConfig{Format: "json", Level: "warn", Levels: map[string]string{"gossip": "debug"}}
+			},
+		},
+		{name: "unknown log format", modify: func(c *Config) { c.Log.Format = "logfmt" }, wantErr: true},
+		{name: "unknown log level", modify: func(c *Config) { c.Log.Level = "verbose" }, wantErr: true},
+		{name: "unknown log subsystem", modify: func(c *Config) { c.Log.Levels = map[string]string{"disk": "debug"} }, wantErr: true},
+		{name: "negative log sample burst", modify: func(c *Config) { c.Log.SampleBurst = -1 }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.NodeID = "n1"
+			tt.modify(cfg)
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_RestartRequired(t *testing.T) {
+	cfg := Default()
+	cfg.NodeID = "n1"
+
+	next := *cfg
+	next.R, next.W = 1, 3
+	next.Gossip.ProbeInterval = 2 * time.Second
+	next.RateLimit.Requests = 50
+	next.Log.Level = "debug"
+	next.Log.Levels = map[string]string{"gossip": "warn"}
+	if got := cfg.RestartRequired(&next); len(got) != 0 {
+		t.Errorf("Expected runtime settings to be reloadable, got %v", got)
+	}
+
+	next.ReplicationFactor = 5
+	next.Peers = []Peer{{ID: "n2", Addr: "b:1"}}
+	got := cfg.RestartRequired(&next)
+	if len(got) != 2 || got[0] != "peers" || got[1] != "rf" {
+		t.Errorf("RestartRequired() = %v, want [peers rf]", got)
+	}
+
+	next = *cfg
+	next.Log.Format = "json"
+	if got := cfg.RestartRequired(&next); len(got) != 1 || got[0] != "log.format" {
+		t.Errorf("RestartRequired() = %v, want [log.format]", got)
+	}
+}
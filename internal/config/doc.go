@@ -1,3 +1,6 @@
 // Package config provides configuration parsing for node startup.
-// It handles node ID, listen address, peer list, and virtual node count.
+// It handles node ID, listen address, peer list, virtual node count, quorum
+// settings, gossip timing and request handling options, loaded from defaults,
+// a YAML or TOML file, KVSTORE_* environment variables and flags, in
+// increasing order of precedence.
 package config
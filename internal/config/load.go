@@ -0,0 +1,221 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"kvstore/internal/logging"
+)
+
+// EnvPrefix prefixes the environment variable of every option: the option
+// --gossip-probe-interval is read from KVSTORE_GOSSIP_PROBE_INTERVAL.
+const EnvPrefix = "KVSTORE_"
+
+// option is a configuration setting that can be given as a flag or an
+// environment variable.
+type option struct {
+	name  string
+	usage string
+	set   func(c *Config, value string) error
+}
+
+var options = []option{
+	{"node-id", "Node ID (required)", func(c *Config, v string) error { c.NodeID = v; return nil }},
+	{"listen", "Listen address, also advertised to peers", func(c *Config, v string) error { c.ListenAddr = v; return nil }},
+	{"peers", "Peers as id=addr,id=addr (seeds with gossip membership)", setPeers},
+	{"vnodes", "Virtual nodes per member", intOption(func(c *Config) *int { return &c.VNodes })},
+	{"rf", "Replication factor (N)", intOption(func(c *Config) *int { return &c.ReplicationFactor })},
+	{"r", "Default read quorum (R <= N)", intOption(func(c *Config) *int { return &c.R })},
+	{"w", "Default write quorum (W <= N)", intOption(func(c *Config) *int { return &c.W })},
+	{"membership", "Membership mode: gossip or static", func(c *Config, v string) error { c.Membership = v; return nil }},
+	{"gossip-probe-interval", "Interval between gossip probes", durationOption(func(c *Config) *time.Duration { return &c.Gossip.ProbeInterval })},
+	{"gossip-suspect-timeout", "Time without contact before a member is suspect", durationOption(func(c *Config) *time.Duration { return &c.Gossip.SuspectTimeout })},
+	{"gossip-dead-timeout", "Time without contact before a member is dead", durationOption(func(c *Config) *time.Duration { return &c.Gossip.DeadTimeout })},
+	{"conflict-policies", "Conflict policy per key prefix, e.g. cache/=lww", func(c *Config, v string) error { c.ConflictPolicies = v; return nil }},
+	{"context-secret", "Secret signing causal contexts, shared by all nodes (prefer the environment variable)", func(c *Config, v string) error { c.ContextSecret = v; return nil }},
+	{"coordinator", "Coordinator policy: any or replica", func(c *Config, v string) error { c.Coordinator = v; return nil }},
+	{"replica-timeout-mode", "Replica timeout mode: fixed or adaptive", func(c *Config, v string) error { c.ReplicaTimeout.Mode = v; return nil }},
+	{"replica-timeout", "Replica timeout in fixed mode", durationOption(func(c *Config) *time.Duration { return &c.ReplicaTimeout.Fixed })},
+	{"replica-timeout-min", "Minimum replica timeout in adaptive mode", durationOption(func(c *Config) *time.Duration { return &c.ReplicaTimeout.Min })},
+	{"replica-timeout-max", "Maximum replica timeout in adaptive mode", durationOption(func(c *Config) *time.Duration { return &c.ReplicaTimeout.Max })},
+	{"replica-timeout-percentile", "Latency percentile used in adaptive mode", floatOption(func(c *Config) *float64 { return &c.ReplicaTimeout.Percentile })},
+	{"rate-limit-requests", "Requests per second per client (0 = unlimited)", floatOption(func(c *Config) *float64 { return &c.RateLimit.Requests })},
+	{"rate-limit-request-burst", "Requests a client may send at once (0 = one second's worth)", intOption(func(c *Config) *int { return &c.RateLimit.RequestBurst })},
+	{"rate-limit-bytes", "Bytes per second per client (0 = unlimited)", floatOption(func(c *Config) *float64 { return &c.RateLimit.Bytes })},
+	{"rate-limit-byte-burst", "Bytes a client may send at once (0 = one second's worth)", intOption(func(c *Config) *int { return &c.RateLimit.ByteBurst })},
+	{"admission-max-requests", "Client requests coordinated at once (0 = unlimited)", intOption(func(c *Config) *int { return &c.Admission.MaxRequests })},
+	{"admission-max-replica-requests", "Replica RPCs served at once (0 = unlimited)", intOption(func(c *Config) *int { return &c.Admission.MaxReplicaRequests })},
+	{"admission-max-queue", "Requests waiting for a slot", intOption(func(c *Config) *int { return &c.Admission.MaxQueue })},
+	{"admission-target-delay", "Queueing delay above which waiting requests are shed", durationOption(func(c *Config) *time.Duration { return &c.Admission.TargetDelay })},
+	{"admission-max-wait", "Longest a request waits for a slot when the node is not overloaded", durationOption(func(c *Config) *time.Duration { return &c.Admission.MaxWait })},
+	{"clock-prune-min-entries", "Vector clock size at or below which entries are never pruned", intOption(func(c *Config) *int { return &c.ClockPrune.MinEntries })},
+	{"clock-prune-max-entries", "Vector clock size above which the oldest entries are pruned (0 = unlimited)", intOption(func(c *Config) *int { return &c.ClockPrune.MaxEntries })},
+	{"clock-prune-min-age", "Age below which vector clock entries are never pruned", durationOption(func(c *Config) *time.Duration { return &c.ClockPrune.MinAge })},
+	{"clock-prune-max-age", "Age above which vector clock entries are pruned (0 = unlimited)", durationOption(func(c *Config) *time.Duration { return &c.ClockPrune.MaxAge })},
+	{"tls-cert", "Node certificate; enables TLS for clients and mutual TLS between nodes", func(c *Config, v string) error { c.TLS.Cert = v; return nil }},
+	{"tls-key", "Private key of the node certificate", func(c *Config, v string) error { c.TLS.Key = v; return nil }},
+	{"tls-ca", "CA signing node certificates", func(c *Config, v string) error { c.TLS.CA = v; return nil }},
+	{"tls-client-ca", "CA signing client certificates", func(c *Config, v string) error { c.TLS.ClientCA = v; return nil }},
+	{"tls-reload-interval", "How often certificate files are checked for changes", durationOption(func(c *Config) *time.Duration { return &c.TLS.ReloadInterval })},
+	{"auth-file", "Tokens and ACL for client authentication and authorization; requires TLS", func(c *Config, v string) error { c.AuthFile = v; return nil }},
+	{"snapshot-dir", "Directory Admin.Snapshot writes snapshots to (empty = snapshots disabled)", func(c *Config, v string) error { c.SnapshotDir = v; return nil }},
+	{"restore-snapshot", "Snapshot file loaded into the store at startup", func(c *Config, v string) error { c.RestoreSnapshot = v; return nil }},
+	{"metrics-addr", "HTTP address serving Prometheus metrics on /metrics (empty = disabled)", func(c *Config, v string) error { c.MetricsAddr = v; return nil }},
+	{"tracing-exporter", "Trace exporter: stdout or otlp (empty = tracing disabled)", func(c *Config, v string) error { c.Tracing.Exporter = v; return nil }},
+	{"tracing-endpoint", "OTLP/HTTP collector URL, e.g. http://localhost:4318", func(c *Config, v string) error { c.Tracing.Endpoint = v; return nil }},
+	{"tracing-sample-rate", "Fraction of new traces recorded, 0 to 1", floatOption(func(c *Config) *float64 { return &c.Tracing.SampleRate })},
+	{"log-format", "Log format: text or json", func(c *Config, v string) error { c.Log.Format = v; return nil }},
+	{"log-level", "Log level: debug, info, warn or error", func(c *Config, v string) error { c.Log.Level = v; return nil }},
+	{"log-levels", "Log level per subsystem, e.g. gossip=debug,server=warn", setLogLevels},
+	{"log-sample-burst", "Log records below warn written per message per second (0 = all)", intOption(func(c *Config) *int { return &c.Log.SampleBurst })},
+}
+
+// Load builds the configuration from defaults, then the config file (--config
+// or KVSTORE_CONFIG), then KVSTORE_* environment variables, then flags, and
+// validates the result. It returns flag.ErrHelp if help was requested.
+func Load(args []string, getenv func(string) string) (*Config, error) {
+	fs := flag.NewFlagSet("kvstore", flag.ContinueOnError)
+	configPath := fs.String("config", getenv(EnvPrefix+"CONFIG"), "Config file (.yaml, .yml or .toml)")
+
+	// Flag values are applied last, so record them in order of appearance
+	type setting struct {
+		opt   option
+		value string
+	}
+	var flags []setting
+	for _, opt := range options {
+		fs.Func(opt.name, opt.usage+" (env "+envName(opt.name)+")", func(v string) error {
+			flags = append(flags, setting{opt, v})
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	cfg := Default()
+	if *configPath != "" {
+		if err := cfg.loadFile(*configPath); err != nil {
+			return nil, err
+		}
+	}
+	for _, opt := range options {
+		if v := getenv(envName(opt.name)); v != "" {
+			if err := opt.set(cfg, v); err != nil {
+				return nil, fmt.Errorf("%s: %w", envName(opt.name), err)
+			}
+		}
+	}
+	for _, f := range flags {
+		if err := f.opt.set(cfg, f.value); err != nil {
+			return nil, fmt.Errorf("--%s: %w", f.opt.name, err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadFile overlays a YAML or TOML config file, chosen by extension. Unknown
+// settings are rejected so that typos do not go unnoticed.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+	case ".toml":
+		// TOML maps onto the same structure, so it is decoded through YAML
+		doc, err := parseTOML(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if data, err = yaml.Marshal(doc); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("unsupported config file %s (expected .yaml, .yml or .toml)", path)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// envName returns the environment variable of an option.
+func envName(name string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+func setPeers(c *Config, v string) error {
+	peers, err := ParsePeers(v)
+	if err != nil {
+		return err
+	}
+	c.Peers = peers
+	return nil
+}
+
+// setLogLevels replaces the per-subsystem log levels with
+// "subsystem=level,..." pairs.
+func setLogLevels(c *Config, v string) error {
+	levels, err := logging.ParseLevels(v)
+	if err != nil {
+		return err
+	}
+	c.Log.Levels = make(map[string]string, len(levels))
+	for name, level := range levels {
+		c.Log.Levels[name] = strings.ToLower(level.String())
+	}
+	return nil
+}
+
+func intOption(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", v)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func floatOption(field func(*Config) *float64) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", v)
+		}
+		*field(c) = f
+		return nil
+	}
+}
+
+func durationOption(field func(*Config) *time.Duration) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", v)
+		}
+		*field(c) = d
+		return nil
+	}
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := Load([]string{"--node-id", "n1"}, env(nil))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.ListenAddr != ":50051" || cfg.VNodes != 128 {
+		t.Errorf("listen/vnodes = %s/%d, want :50051/128", cfg.ListenAddr, cfg.VNodes)
+	}
+	if cfg.ReplicationFactor != 3 || cfg.R != 2 || cfg.W != 2 {
+		t.Errorf("N/R/W = %d/%d/%d, want 3/2/2", cfg.ReplicationFactor, cfg.R, cfg.W)
+	}
+	if cfg.Membership != MembershipGossip || cfg.Gossip.ProbeInterval != time.Second {
+		t.Errorf("membership = %s, probe interval = %v", cfg.Membership, cfg.Gossip.ProbeInterval)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, "kvstore.yaml", `
+node_id: file
+listen: ":6000"
+rf: 5
+r: 3
+w: 3
+vnodes: 64
+`)
+	getenv := env(map[string]string{
+		"KVSTORE_CONFIG":     path,
+		"KVSTORE_LISTEN":     ":7000",
+		"KVSTORE_R":          "4",
+		"KVSTORE_LOG_LEVELS": "server=warn",
+	})
+
+	cfg, err := Load([]string{"--r", "1", "--node-id", "flag"}, getenv)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.NodeID != "flag" {
+		t.Errorf("NodeID = %s, want flag (flag over file)", cfg.NodeID)
+	}
+	if cfg.ListenAddr != ":7000" {
+		t.Errorf("ListenAddr = %s, want :7000 (env over file)", cfg.ListenAddr)
+	}
+	if cfg.R != 1 {
+		t.Errorf("R = %d, want 1 (flag over env)", cfg.R)
+	}
+	if cfg.ReplicationFactor != 5 || cfg.W != 3 || cfg.VNodes != 64 {
+		t.Errorf("N/W/vnodes = %d/%d/%d, want 5/3/64 from file", cfg.ReplicationFactor, cfg.W, cfg.VNodes)
+	}
+	if cfg.Log.Levels["server"] != "warn" {
+		t.Errorf("Log.Levels = %v, want server=warn from env", cfg.Log.Levels)
+	}
+}
+
+func TestLoad_YAMLFile(t *testing.T) {
+	path := writeFile(t, "kvstore.yml", `
+node_id: n1
+listen: 127.0.0.1:50051
+peers:
+  - n2=127.0.0.1:50052
+  - id: n3
+    addr: 127.0.0.1:50053
+gossip:
+  probe_interval: 500ms
+  suspect_timeout: 2s
+  dead_timeout: 6s
+replica_timeout:
+  mode: adaptive
+  percentile: 0.95
+rate_limit:
+  requests: 100
+  bytes: 1048576
+  clients:
+    batch: {requests: 10}
+clock_prune:
+  max_entries: 20
+  max_age: 1h
+log:
+  format: json
+  levels:
+    gossip: debug
+`)
+	cfg, err := Load([]string{"--config", path}, env(nil))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.Peers) != 2 || cfg.Peers[0] != (Peer{ID: "n2", Addr: "127.0.0.1:50052"}) || cfg.Peers[1] != (Peer{ID: "n3", Addr: "127.0.0.1:50053"}) {
+		t.Errorf("Peers = %v", cfg.Peers)
+	}
+	want := GossipConfig{ProbeInterval: 500 * time.Millisecond, SuspectTimeout: 2 * time.Second, DeadTimeout: 6 * time.Second}
+	if cfg.Gossip != want {
+		t.Errorf("Gossip = %+v, want %+v", cfg.Gossip, want)
+	}
+	if cfg.ReplicaTimeout.Mode != "adaptive" || cfg.ReplicaTimeout.Percentile != 0.95 || cfg.ReplicaTimeout.Fixed != 2*time.Second {
+		t.Errorf("ReplicaTimeout = %+v", cfg.ReplicaTimeout)
+	}
+	limits := cfg.RateLimit.Config()
+	if limits.Default.Requests != 100 || limits.Default.Bytes != 1<<20 || limits.For("batch").Requests != 10 || limits.For("batch").Bytes != 0 {
+		t.Errorf("RateLimit = %+v", limits)
+	}
+	if p := cfg.ClockPrune.Config(); p.MaxEntries != 20 || p.MaxAge != time.Hour || p.MinEntries != 10 {
+		t.Errorf("ClockPrune = %+v", p)
+	}
+	logs, err := cfg.Log.Config()
+	if err != nil || cfg.Log.Format != "json" || logs.Level != slog.LevelInfo || logs.LevelOf("gossip") != slog.LevelDebug {
+		t.Errorf("Log = %+v (%v)", cfg.Log, err)
+	}
+}
+
+func TestLoad_TOMLFile(t *testing.T) {
+	path := writeFile(t, "kvstore.toml", `
+# Node n1
+node_id = "n1"
+listen = '127.0.0.1:50051'
+rf = 3
+conflict_policies = "cache/=lww"
+peers = [
+  "n2=127.0.0.1:50052",
+  { id = "n3", addr = "127.0.0.1:50053" },
+]
+
+[gossip]
+probe_interval = "250ms" # faster failure detection
+suspect_timeout = "1s"
+dead_timeout = "5s"
+
+[replica_timeout]
+mode = "adaptive"
+percentile = 0.9
+`)
+	cfg, err := Load([]string{"--config", path}, env(nil))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.NodeID != "n1" || cfg.ListenAddr != "127.0.0.1:50051" || cfg.ConflictPolicies != "cache/=lww" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if len(cfg.Peers) != 2 || cfg.Peers[1] != (Peer{ID: "n3", Addr: "127.0.0.1:50053"}) {
+		t.Errorf("Peers = %v", cfg.Peers)
+	}
+	if cfg.Gossip.ProbeInterval != 250*time.Millisecond || cfg.Gossip.DeadTimeout != 5*time.Second {
+		t.Errorf("Gossip = %+v", cfg.Gossip)
+	}
+	if cfg.ReplicaTimeout.Mode != "adaptive" || cfg.ReplicaTimeout.Percentile != 0.9 {
+		t.Errorf("ReplicaTimeout = %+v", cfg.ReplicaTimeout)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		file    string // kvstore.yaml content, passed with --config
+		wantErr string
+	}{
+		{name: "missing node id", args: nil, wantErr: "node ID is required"},
+		{name: "r above rf", args: []string{"--node-id", "n1", "--r", "4"}, wantErr: "r must be between"},
+		{name: "w above rf from env", args: []string{"--node-id", "n1"}, env: map[string]string{"KVSTORE_W": "5"}, wantErr: "w must be between"},
+		{name: "bad integer", args: []string{"--node-id", "n1", "--rf", "three"}, wantErr: "invalid integer"},
+		{name: "bad env duration", args: []string{"--node-id", "n1"}, env: map[string]string{"KVSTORE_GOSSIP_DEAD_TIMEOUT": "10"}, wantErr: "KVSTORE_GOSSIP_DEAD_TIMEOUT"},
+		{name: "unknown file setting", args: []string{"--node-id", "n1"}, file: "replicas: 3\n", wantErr: "field replicas not found"},
+		{name: "bad log levels", args: []string{"--node-id", "n1", "--log-levels", "gossip"}, wantErr: "--log-levels"},
+		{name: "unexpected argument", args: []string{"--node-id", "n1", "extra"}, wantErr: "unexpected arguments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"--config", writeFile(t, "kvstore.yaml", tt.file)}, args...)
+			}
+			_, err := Load(args, env(tt.env))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_Help(t *testing.T) {
+	_, err := Load([]string{"-h"}, env(nil))
+	if !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("Load(-h) error = %v, want flag.ErrHelp", err)
+	}
+}
+
+func TestParseTOML_Errors(t *testing.T) {
+	tests := []string{
+		"key",
+		"key = ",
+		"key = \"unterminated",
+		"key = 1\nkey = 2",
+		"[t]\n[t]",
+		"key = [1, 2",
+		"key = 1 2",
+		"key = 1979-05-27",
+	}
+	for _, input := range tests {
+		if _, err := parseTOML([]byte(input)); err == nil {
+			t.Errorf("parseTOML(%q) succeeded, want error", input)
+		}
+	}
+}
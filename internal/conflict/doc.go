@@ -0,0 +1,5 @@
+// Package conflict defines conflict resolution policies for concurrent
+// versions. Policies are assigned per key space (key prefix) and decide
+// whether concurrent writes surface to clients as siblings or are resolved
+// by last-write-wins on HLC timestamps.
+package conflict
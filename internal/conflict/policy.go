@@ -0,0 +1,140 @@
+package conflict
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Policy determines how concurrent versions of a key are resolved.
+type Policy int
+
+const (
+	// Siblings returns all concurrent versions to the client for resolution.
+	Siblings Policy = iota
+	// LWW picks a single winner by HLC timestamp, breaking ties on node ID.
+	LWW
+)
+
+// String returns the string representation of the Policy.
+func (p Policy) String() string {
+	switch p {
+	case Siblings:
+		return "siblings"
+	case LWW:
+		return "lww"
+	default:
+		return "unknown"
+	}
+}
+
+// ParsePolicy parses a policy name ("siblings" or "lww").
+func ParsePolicy(s string) (Policy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "siblings", "":
+		return Siblings, nil
+	case "lww":
+		return LWW, nil
+	default:
+		return Siblings, fmt.Errorf("unknown conflict policy: %s (expected siblings or lww)", s)
+	}
+}
+
+// prefixPolicy assigns a policy to all keys starting with prefix.
+type prefixPolicy struct {
+	prefix string
+	policy Policy
+}
+
+// Policies maps key spaces (key prefixes) to conflict resolution policies.
+// The longest matching prefix wins; keys with no matching prefix use the
+// default policy. A nil *Policies resolves every key with Siblings.
+type Policies struct {
+	defaultPolicy Policy
+	prefixes      []prefixPolicy // sorted by descending prefix length
+}
+
+// NewPolicies creates a policy set with the given default and per-prefix policies.
+func NewPolicies(defaultPolicy Policy, prefixes map[string]Policy) *Policies {
+	p := &Policies{
+		defaultPolicy: defaultPolicy,
+		prefixes:      make([]prefixPolicy, 0, len(prefixes)),
+	}
+	for prefix, policy := range prefixes {
+		p.prefixes = append(p.prefixes, prefixPolicy{prefix: prefix, policy: policy})
+	}
+	// Longest prefix first (ties broken lexically for determinism)
+	sort.Slice(p.prefixes, func(i, j int) bool {
+		if len(p.prefixes[i].prefix) != len(p.prefixes[j].prefix) {
+			return len(p.prefixes[i].prefix) > len(p.prefixes[j].prefix)
+		}
+		return p.prefixes[i].prefix < p.prefixes[j].prefix
+	})
+	return p
+}
+
+// ParsePolicies parses a comma-separated list of prefix policies in the format:
+// "prefix1=lww,prefix2=siblings". An entry without "=" sets the default policy.
+func ParsePolicies(spec string) (*Policies, error) {
+	defaultPolicy := Siblings
+	prefixes := make(map[string]Policy)
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) == 1 {
+			policy, err := ParsePolicy(kv[0])
+			if err != nil {
+				return nil, err
+			}
+			defaultPolicy = policy
+			continue
+		}
+
+		prefix := strings.TrimSpace(kv[0])
+		if prefix == "" {
+			return nil, fmt.Errorf("key prefix cannot be empty: %s", part)
+		}
+		policy, err := ParsePolicy(kv[1])
+		if err != nil {
+			return nil, err
+		}
+		prefixes[prefix] = policy
+	}
+
+	return NewPolicies(defaultPolicy, prefixes), nil
+}
+
+// For returns the policy that applies to key.
+func (p *Policies) For(key string) Policy {
+	if p == nil {
+		return Siblings
+	}
+	for _, pp := range p.prefixes {
+		if strings.HasPrefix(key, pp.prefix) {
+			return pp.policy
+		}
+	}
+	return p.defaultPolicy
+}
+
+// With returns a copy of p with additional per-prefix policies, which
+// replace any existing policies for the same prefixes.
+func (p *Policies) With(prefixes map[string]Policy) *Policies {
+	merged := make(map[string]Policy, len(prefixes))
+	defaultPolicy := Siblings
+	if p != nil {
+		defaultPolicy = p.defaultPolicy
+		for _, pp := range p.prefixes {
+			merged[pp.prefix] = pp.policy
+		}
+	}
+	for prefix, policy := range prefixes {
+		merged[prefix] = policy
+	}
+	return NewPolicies(defaultPolicy, merged)
+}
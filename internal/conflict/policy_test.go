@@ -0,0 +1,73 @@
+package conflict
+
+import (
+	"testing"
+)
+
+func TestParsePolicies(t *testing.T) {
+	p, err := ParsePolicies("cache/=lww, cache/pinned/=siblings")
+	if err != nil {
+		t.Fatalf("ParsePolicies failed: %v", err)
+	}
+
+	tests := []struct {
+		key  string
+		want Policy
+	}{
+		{"cache/user:1", LWW},
+		{"cache/pinned/user:1", Siblings}, // longest prefix wins
+		{"user:1", Siblings},              // default
+	}
+	for _, tt := range tests {
+		if got := p.For(tt.key); got != tt.want {
+			t.Errorf("For(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestParsePolicies_Default(t *testing.T) {
+	p, err := ParsePolicies("lww,audit/=siblings")
+	if err != nil {
+		t.Fatalf("ParsePolicies failed: %v", err)
+	}
+	if p.For("anything") != LWW {
+		t.Error("Expected default policy LWW")
+	}
+	if p.For("audit/log") != Siblings {
+		t.Error("Expected audit/ prefix to use siblings")
+	}
+}
+
+func TestParsePolicies_Invalid(t *testing.T) {
+	for _, spec := range []string{"cache/=newest", "=lww", "bogus"} {
+		if _, err := ParsePolicies(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
+
+func TestPolicies_NilIsSiblings(t *testing.T) {
+	var p *Policies
+	if p.For("key") != Siblings {
+		t.Error("Expected nil policies to return siblings")
+	}
+}
+
+func TestPolicies_With(t *testing.T) {
+	p, err := ParsePolicies("lww,cache/=siblings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := p.With(map[string]Policy{"cache/": LWW, "audit/": Siblings})
+	if q.For("cache/x") != LWW || q.For("audit/x") != Siblings || q.For("other") != LWW {
+		t.Error("Expected added prefixes to override and keep the default")
+	}
+	if p.For("cache/x") != Siblings {
+		t.Error("Expected With to leave the original unchanged")
+	}
+
+	var nilPolicies *Policies
+	if nilPolicies.With(map[string]Policy{"a/": LWW}).For("b") != Siblings {
+		t.Error("Expected nil policies to keep the siblings default")
+	}
+}
@@ -0,0 +1,6 @@
+// Package dedup provides a bounded, TTL'd table of request results used to
+// make retried writes idempotent. Entries are keyed by the client's
+// authenticated principal and the client-supplied client_id and request_id;
+// a duplicate request returns the recorded result instead of being applied
+// again.
+package dedup
@@ -0,0 +1,191 @@
+package dedup
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCapacity is the default maximum number of entries in a table.
+	DefaultCapacity = 100000
+	// DefaultTTL is the default time an entry is remembered. Clients must
+	// not retry a request later than this after the first attempt.
+	DefaultTTL = 10 * time.Minute
+)
+
+// Key returns the table key for a request, or "" if the request carries no
+// request ID and therefore cannot be deduplicated. principal is the
+// authenticated principal of the client ("" without authentication), so
+// clients of different principals never share entries even if they choose
+// the same client and request IDs. Additional parts (e.g. the data key)
+// scope the entry further.
+func Key(principal, clientID, requestID string, parts ...string) string {
+	if requestID == "" {
+		return ""
+	}
+	key := principal + "\x00" + clientID + "\x00" + requestID
+	for _, p := range parts {
+		key += "\x00" + p
+	}
+	return key
+}
+
+// Stats summarizes table activity.
+type Stats struct {
+	Entries   int
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64 // Entries dropped to stay within capacity
+}
+
+type entry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+// Table is a bounded map from request key to result with a fixed TTL.
+// When full, the oldest entry is evicted. It is safe for concurrent use.
+type Table[V any] struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	entries  map[string]*list.Element
+	order    *list.List // Oldest first; all entries share the TTL, so this is also expiry order
+	stats    Stats
+	now      func() time.Time // overridable in tests
+}
+
+// NewTable creates a new table. Non-positive arguments select the defaults.
+func NewTable[V any](capacity int, ttl time.Duration) *Table[V] {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Table[V]{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+// Get returns the value recorded for key, if any and not expired.
+func (t *Table[V]) Get(key string) (V, bool) {
+	var zero V
+	if key == "" {
+		return zero, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expire()
+	elem, ok := t.entries[key]
+	if !ok {
+		t.stats.Misses++
+		return zero, false
+	}
+	t.stats.Hits++
+	return elem.Value.(*entry[V]).value, true
+}
+
+// Put records value for key, replacing any previous value and restarting
+// its TTL. An empty key is ignored.
+func (t *Table[V]) Put(key string, value V) {
+	if key == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expire()
+	if elem, ok := t.entries[key]; ok {
+		t.order.Remove(elem)
+		delete(t.entries, key)
+	}
+	t.insert(key, value)
+}
+
+// GetOrPut returns the value recorded for key and true if there is one;
+// otherwise it records value and returns it and false. The lookup and the
+// insertion are atomic, so of several concurrent callers with the same key
+// exactly one records its value. An empty key is never recorded.
+func (t *Table[V]) GetOrPut(key string, value V) (actual V, loaded bool) {
+	if key == "" {
+		return value, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expire()
+	if elem, ok := t.entries[key]; ok {
+		t.stats.Hits++
+		return elem.Value.(*entry[V]).value, true
+	}
+	t.stats.Misses++
+	t.insert(key, value)
+	return value, false
+}
+
+// insert adds an entry for a key that has none, evicting the oldest entries
+// to stay within capacity. Must be called with mu held.
+func (t *Table[V]) insert(key string, value V) {
+	for t.order.Len() >= t.capacity {
+		t.removeOldest()
+		t.stats.Evictions++
+	}
+	t.entries[key] = t.order.PushBack(&entry[V]{
+		key:       key,
+		value:     value,
+		expiresAt: t.now().Add(t.ttl),
+	})
+}
+
+// Delete removes the entry for key.
+func (t *Table[V]) Delete(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if elem, ok := t.entries[key]; ok {
+		t.order.Remove(elem)
+		delete(t.entries, key)
+	}
+}
+
+// Stats returns a snapshot of the table's statistics.
+func (t *Table[V]) Stats() Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := t.stats
+	stats.Entries = len(t.entries)
+	return stats
+}
+
+// expire removes expired entries. Must be called with mu held.
+func (t *Table[V]) expire() {
+	now := t.now()
+	for front := t.order.Front(); front != nil; front = t.order.Front() {
+		if now.Before(front.Value.(*entry[V]).expiresAt) {
+			return
+		}
+		t.removeOldest()
+	}
+}
+
+// removeOldest removes the oldest entry. Must be called with mu held.
+func (t *Table[V]) removeOldest() {
+	front := t.order.Front()
+	if front == nil {
+		return
+	}
+	t.order.Remove(front)
+	delete(t.entries, front.Value.(*entry[V]).key)
+}
@@ -0,0 +1,133 @@
+package dedup
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTable_GetPut(t *testing.T) {
+	tbl := NewTable[string](10, time.Minute)
+
+	if _, ok := tbl.Get("a"); ok {
+		t.Error("Expected miss on empty table")
+	}
+	tbl.Put("a", "result")
+	if v, ok := tbl.Get("a"); !ok || v != "result" {
+		t.Errorf("Expected recorded result, got %q, %v", v, ok)
+	}
+
+	stats := tbl.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestTable_GetOrPut(t *testing.T) {
+	tbl := NewTable[int](10, time.Minute)
+
+	if v, loaded := tbl.GetOrPut("a", 1); loaded || v != 1 {
+		t.Errorf("GetOrPut(new) = %d, %v; want 1, false", v, loaded)
+	}
+	if v, loaded := tbl.GetOrPut("a", 2); !loaded || v != 1 {
+		t.Errorf("GetOrPut(existing) = %d, %v; want 1, true", v, loaded)
+	}
+	if _, loaded := tbl.GetOrPut("", 3); loaded || tbl.Stats().Entries != 1 {
+		t.Error("Expected an empty key not to be recorded")
+	}
+
+	// Exactly one of many concurrent callers records its value
+	var wg sync.WaitGroup
+	var recorded atomic.Int32
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, loaded := tbl.GetOrPut("b", i); !loaded {
+				recorded.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if recorded.Load() != 1 {
+		t.Errorf("Expected exactly one caller to record b, got %d", recorded.Load())
+	}
+}
+
+func TestTable_TTL(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tbl := NewTable[int](10, time.Minute)
+	tbl.now = func() time.Time { return now }
+
+	tbl.Put("a", 1)
+	now = now.Add(30 * time.Second)
+	tbl.Put("b", 2)
+
+	now = now.Add(31 * time.Second)
+	if _, ok := tbl.Get("a"); ok {
+		t.Error("Expected a to have expired")
+	}
+	if _, ok := tbl.Get("b"); !ok {
+		t.Error("Expected b to still be present")
+	}
+	if n := tbl.Stats().Entries; n != 1 {
+		t.Errorf("Expected expired entries to be removed, got %d entries", n)
+	}
+}
+
+func TestTable_CapacityEvictsOldest(t *testing.T) {
+	tbl := NewTable[int](3, time.Minute)
+	for i := 0; i < 5; i++ {
+		tbl.Put(fmt.Sprintf("k%d", i), i)
+	}
+
+	for _, k := range []string{"k0", "k1"} {
+		if _, ok := tbl.Get(k); ok {
+			t.Errorf("Expected %s to be evicted", k)
+		}
+	}
+	for _, k := range []string{"k2", "k3", "k4"} {
+		if _, ok := tbl.Get(k); !ok {
+			t.Errorf("Expected %s to be present", k)
+		}
+	}
+	if stats := tbl.Stats(); stats.Evictions != 2 || stats.Entries != 3 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestTable_PutReplaces(t *testing.T) {
+	tbl := NewTable[int](2, time.Minute)
+	tbl.Put("a", 1)
+	tbl.Put("b", 2)
+	tbl.Put("a", 3) // refreshes a: b is now the oldest
+	tbl.Put("c", 4)
+
+	if v, ok := tbl.Get("a"); !ok || v != 3 {
+		t.Errorf("Expected a=3, got %d, %v", v, ok)
+	}
+	if _, ok := tbl.Get("b"); ok {
+		t.Error("Expected b to be evicted")
+	}
+}
+
+func TestKey(t *testing.T) {
+	if Key("alice", "client", "") != "" {
+		t.Error("Expected empty key without a request ID")
+	}
+	if Key("", "c1", "r1") == Key("", "c2", "r1") {
+		t.Error("Expected different clients to have different keys")
+	}
+	if Key("alice", "c1", "r1") == Key("bob", "c1", "r1") {
+		t.Error("Expected different principals to have different keys")
+	}
+	if Key("", "c1", "r1", "k1") == Key("", "c1", "r1", "k2") {
+		t.Error("Expected extra parts to scope the key")
+	}
+	// Separator prevents ambiguous concatenation
+	if Key("", "ab", "c") == Key("", "a", "bc") || Key("ab", "c", "r") == Key("a", "bc", "r") {
+		t.Error("Expected unambiguous keys")
+	}
+}
@@ -2,12 +2,13 @@ package gossip
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"math/rand"
 	"sync"
 	"time"
 
 	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/logging"
 	"kvstore/internal/ring"
 )
 
@@ -18,6 +19,9 @@ const (
 	Alive MemberStatus = iota
 	Suspect
 	Dead
+	// Left is a decommissioned member. It is final: a member that left is
+	// never marked alive again.
+	Left
 )
 
 // String returns the string representation of MemberStatus.
@@ -29,6 +33,8 @@ func (s MemberStatus) String() string {
 		return "SUSPECT"
 	case Dead:
 		return "DEAD"
+	case Left:
+		return "LEFT"
 	default:
 		return "UNKNOWN"
 	}
@@ -43,6 +49,8 @@ func (s MemberStatus) ToProto() kvstorepb.MemberStatus {
 		return kvstorepb.MemberStatus_SUSPECT
 	case Dead:
 		return kvstorepb.MemberStatus_DEAD
+	case Left:
+		return kvstorepb.MemberStatus_LEFT
 	default:
 		return kvstorepb.MemberStatus_ALIVE
 	}
@@ -57,6 +65,8 @@ func FromProto(s kvstorepb.MemberStatus) MemberStatus {
 		return Suspect
 	case kvstorepb.MemberStatus_DEAD:
 		return Dead
+	case kvstorepb.MemberStatus_LEFT:
+		return Left
 	default:
 		return Alive
 	}
@@ -75,6 +85,7 @@ type Member struct {
 type Membership struct {
 	mu          sync.RWMutex
 	localID     string
+	log         *slog.Logger
 	localAddr   string
 	members     map[string]*Member // id -> Member
 	incarnation map[string]uint64  // id -> incarnation (for local tracking)
@@ -109,6 +120,7 @@ func NewMembership(localID, localAddr string, probeInterval, suspectTimeout, dea
 
 	m := &Membership{
 		localID:        localID,
+		log:            logging.For(logging.Gossip).With(logging.NodeID(localID)),
 		localAddr:      localAddr,
 		members:        make(map[string]*Member),
 		incarnation:    make(map[string]uint64),
@@ -146,7 +158,8 @@ func (m *Membership) Start(probeFn func(ctx context.Context, addr string) error,
 	// Probe loop
 	go func() {
 		defer m.wg.Done()
-		ticker := time.NewTicker(m.probeInterval)
+		interval := m.ProbeInterval()
+		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 
 		for {
@@ -155,6 +168,7 @@ func (m *Membership) Start(probeFn func(ctx context.Context, addr string) error,
 				return
 			case <-ticker.C:
 				m.probe(probeFn)
+				interval = m.resetTicker(ticker, interval, 1)
 			}
 		}
 	}()
@@ -162,7 +176,8 @@ func (m *Membership) Start(probeFn func(ctx context.Context, addr string) error,
 	// Gossip loop
 	go func() {
 		defer m.wg.Done()
-		ticker := time.NewTicker(m.probeInterval * 2) // Gossip less frequently
+		interval := m.ProbeInterval()
+		ticker := time.NewTicker(interval * 2) // Gossip less frequently
 		defer ticker.Stop()
 
 		for {
@@ -171,6 +186,7 @@ func (m *Membership) Start(probeFn func(ctx context.Context, addr string) error,
 				return
 			case <-ticker.C:
 				m.gossip(gossipFn)
+				interval = m.resetTicker(ticker, interval, 2)
 			}
 		}
 	}()
@@ -193,6 +209,33 @@ func (m *Membership) Start(probeFn func(ctx context.Context, addr string) error,
 	}()
 }
 
+// SetTimeouts changes the failure detection timing while the protocol runs.
+// The probe and gossip loops pick up a new interval after their next tick.
+func (m *Membership) SetTimeouts(probeInterval, suspectTimeout, deadTimeout time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.probeInterval = probeInterval
+	m.suspectTimeout = suspectTimeout
+	m.deadTimeout = deadTimeout
+}
+
+// ProbeInterval returns the interval between probes.
+func (m *Membership) ProbeInterval() time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.probeInterval
+}
+
+// resetTicker resets a loop ticker to factor times the probe interval if the
+// interval changed from current, and returns the interval in effect.
+func (m *Membership) resetTicker(ticker *time.Ticker, current time.Duration, factor time.Duration) time.Duration {
+	interval := m.ProbeInterval()
+	if interval != current {
+		ticker.Reset(interval * factor)
+	}
+	return interval
+}
+
 // Stop stops the membership protocol.
 func (m *Membership) Stop() {
 	m.cancel()
@@ -224,7 +267,7 @@ func (m *Membership) probe(probeFn func(ctx context.Context, addr string) error)
 	target := candidates[rand.Intn(len(candidates))]
 
 	// Probe with timeout
-	ctx, cancel := context.WithTimeout(m.ctx, m.probeInterval)
+	ctx, cancel := context.WithTimeout(m.ctx, m.ProbeInterval())
 	defer cancel()
 
 	err := probeFn(ctx, target.Addr)
@@ -233,14 +276,17 @@ func (m *Membership) probe(probeFn func(ctx context.Context, addr string) error)
 
 	if err == nil {
 		// Success - mark as Alive
-		if member, exists := m.members[target.ID]; exists {
+		if member, exists := m.members[target.ID]; exists && member.Status != Left {
+			changed := member.Status != Alive
 			member.Status = Alive
 			member.LastSeen = time.Now()
 			if member.Incarnation < m.incarnation[target.ID] {
 				member.Incarnation = m.incarnation[target.ID]
 			}
+			if changed {
+				m.notifyMembershipChanged()
+			}
 		}
-		m.notifyMembershipChanged()
 	} else {
 		// Failure - mark as Suspect
 		if member, exists := m.members[target.ID]; exists && member.Status == Alive {
@@ -248,7 +294,7 @@ func (m *Membership) probe(probeFn func(ctx context.Context, addr string) error)
 			member.Status = Suspect
 			member.Incarnation = m.incarnation[target.ID]
 			member.LastSeen = time.Now()
-			log.Printf("[%s] Marked %s as SUSPECT (probe failed)", m.localID, target.ID)
+			m.log.Info("Marked member SUSPECT, probe failed", slog.String("member", target.ID))
 			m.notifyMembershipChanged()
 		}
 	}
@@ -256,14 +302,7 @@ func (m *Membership) probe(probeFn func(ctx context.Context, addr string) error)
 
 // gossip propagates membership information to a random peer.
 func (m *Membership) gossip(gossipFn func(ctx context.Context, addr string, members []*Member) error) {
-	m.mu.RLock()
 	snapshot := m.Snapshot()
-	allMembers := make([]*Member, 0, len(m.members))
-	for _, member := range m.members {
-		allMembers = append(allMembers, member)
-	}
-	m.mu.RUnlock()
-
 	if len(snapshot) == 0 {
 		return
 	}
@@ -274,10 +313,10 @@ func (m *Membership) gossip(gossipFn func(ctx context.Context, addr string, memb
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(m.ctx, m.probeInterval)
+	ctx, cancel := context.WithTimeout(m.ctx, m.ProbeInterval())
 	defer cancel()
 
-	_ = gossipFn(ctx, target.Addr, allMembers) // Best effort
+	_ = gossipFn(ctx, target.Addr, snapshot) // Best effort
 }
 
 // checkTimeouts checks for suspect/dead timeouts.
@@ -300,7 +339,7 @@ func (m *Membership) checkTimeouts() {
 			m.incarnation[id]++
 			member.Status = Dead
 			member.Incarnation = m.incarnation[id]
-			log.Printf("[%s] Marked %s as DEAD (suspect timeout)", m.localID, id)
+			m.log.Info("Marked member DEAD, suspect timeout", slog.String("member", id))
 			changed = true
 		} else if member.Status == Dead && elapsed > m.deadTimeout {
 			// Remove dead nodes after deadTimeout (optional cleanup)
@@ -326,6 +365,19 @@ func (m *Membership) ApplyGossip(remoteMembers []*Member) {
 
 		local, exists := m.members[remote.ID]
 
+		if exists && local.Status == Left {
+			continue // Left is final
+		}
+		if exists && remote.Status == Left {
+			local.Status = Left
+			local.Incarnation = max(local.Incarnation, remote.Incarnation)
+			local.LastSeen = time.Now()
+			m.incarnation[remote.ID] = local.Incarnation
+			changed = true
+			m.log.Info("Member left the cluster", slog.String("member", remote.ID))
+			continue
+		}
+
 		if !exists {
 			// New member
 			m.members[remote.ID] = &Member{
@@ -337,7 +389,7 @@ func (m *Membership) ApplyGossip(remoteMembers []*Member) {
 			}
 			m.incarnation[remote.ID] = remote.Incarnation
 			changed = true
-			log.Printf("[%s] Discovered new member: %s (%s)", m.localID, remote.ID, remote.Status)
+			m.log.Info("Discovered new member", slog.String("member", remote.ID), slog.String("status", remote.Status.String()))
 		} else {
 			// Merge: higher incarnation wins
 			if remote.Incarnation > local.Incarnation {
@@ -346,7 +398,7 @@ func (m *Membership) ApplyGossip(remoteMembers []*Member) {
 				local.LastSeen = time.Now()
 				m.incarnation[remote.ID] = remote.Incarnation
 				changed = true
-				log.Printf("[%s] Updated %s: incarnation=%d status=%s", m.localID, remote.ID, remote.Incarnation, remote.Status)
+				m.log.Info("Updated member", slog.String("member", remote.ID), slog.Uint64("incarnation", remote.Incarnation), slog.String("status", remote.Status.String()))
 			} else if remote.Incarnation == local.Incarnation {
 				// Same incarnation: prefer Alive > Suspect > Dead
 				if shouldUpdateStatus(local.Status, remote.Status) {
@@ -381,11 +433,11 @@ func (m *Membership) MarkAlive(id string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if member, exists := m.members[id]; exists {
+	if member, exists := m.members[id]; exists && member.Status != Left {
 		if member.Status != Alive {
 			member.Status = Alive
 			member.LastSeen = time.Now()
-			log.Printf("[%s] Marked %s as ALIVE", m.localID, id)
+			m.log.Info("Marked member ALIVE", slog.String("member", id))
 			m.notifyMembershipChanged()
 		} else {
 			member.LastSeen = time.Now()
@@ -415,7 +467,12 @@ func (m *Membership) Snapshot() []*Member {
 func (m *Membership) AliveNodes() []ring.Node {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
+	return m.aliveNodes()
+}
 
+// aliveNodes returns Alive members as ring.Node slice (must be called with
+// lock held).
+func (m *Membership) aliveNodes() []ring.Node {
 	nodes := make([]ring.Node, 0)
 	for _, member := range m.members {
 		if member.Status == Alive {
@@ -428,6 +485,32 @@ func (m *Membership) AliveNodes() []ring.Node {
 	return nodes
 }
 
+// IsAlive reports whether the member with the given ID is known and Alive.
+func (m *Membership) IsAlive(id string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	member, ok := m.members[id]
+	return ok && member.Status == Alive
+}
+
+// Leave marks the local node as Left, so that gossip removes it from the
+// rings of the other members. Left is final: the other members never mark
+// the node alive again, so a decommissioned node rejoins under a new ID.
+func (m *Membership) Leave() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	self := m.members[m.localID]
+	if self.Status == Left {
+		return
+	}
+	m.incarnation[m.localID]++
+	self.Status = Left
+	self.Incarnation = m.incarnation[m.localID]
+	m.log.Info("Left the cluster")
+	m.notifyMembershipChanged()
+}
+
 // GetMembership returns current membership state (for debug endpoint).
 func (m *Membership) GetMembership() []*Member {
 	return m.Snapshot()
@@ -467,10 +550,11 @@ func (m *Membership) getAliveMembers() []*Member {
 	return alive
 }
 
-// notifyMembershipChanged invokes the callback if set.
+// notifyMembershipChanged invokes the callback if set (must be called with
+// lock held).
 func (m *Membership) notifyMembershipChanged() {
 	if m.onMembershipChanged != nil {
-		alive := m.AliveNodes()
+		alive := m.aliveNodes()
 		go m.onMembershipChanged(alive) // Async to avoid blocking
 	}
 }
@@ -1,6 +1,7 @@
 package gossip
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -80,6 +81,12 @@ func TestMembership_AliveNodes(t *testing.T) {
 			t.Error("Suspect node should not be in alive nodes")
 		}
 	}
+
+	for id, want := range map[string]bool{"local": true, "node1": true, "node2": false, "node3": false, "unknown": false} {
+		if got := m.IsAlive(id); got != want {
+			t.Errorf("IsAlive(%q) = %v, want %v", id, got, want)
+		}
+	}
 }
 
 func TestMembership_StateTransitions(t *testing.T) {
@@ -135,3 +142,88 @@ func TestMembership_AddSeedMembers(t *testing.T) {
 		t.Error("Expected seed2 to be added")
 	}
 }
+
+func TestMembership_SetTimeouts(t *testing.T) {
+	m := NewMembership("local", "127.0.0.1:50051", 1*time.Second, 30*time.Second, 60*time.Second)
+	m.ApplyGossip([]*Member{{ID: "node1", Addr: "127.0.0.1:50052", Status: Suspect, Incarnation: 1}})
+	m.members["node1"].LastSeen = time.Now().Add(-5 * time.Second)
+
+	m.checkTimeouts()
+	if m.members["node1"].Status != Suspect {
+		t.Fatalf("Expected node1 to stay SUSPECT within the 30s suspect timeout, got %v", m.members["node1"].Status)
+	}
+
+	m.SetTimeouts(500*time.Millisecond, 2*time.Second, 4*time.Second)
+	if got := m.ProbeInterval(); got != 500*time.Millisecond {
+		t.Errorf("Expected probe interval 500ms, got %v", got)
+	}
+	m.checkTimeouts()
+	if m.members["node1"].Status != Dead {
+		t.Errorf("Expected node1 DEAD after lowering the suspect timeout to 2s, got %v", m.members["node1"].Status)
+	}
+}
+
+func TestMembership_NotifiesWithoutDeadlock(t *testing.T) {
+	m := NewMembership("local", "127.0.0.1:50051", 1*time.Second, 3*time.Second, 10*time.Second)
+	changed := make(chan []ring.Node, 4)
+	m.SetOnMembershipChanged(func(nodes []ring.Node) { changed <- nodes })
+
+	done := make(chan struct{})
+	go func() {
+		m.AddSeedMembers([]ring.Node{{ID: "node1", Addr: "127.0.0.1:50052"}})
+		m.mu.Lock()
+		m.members["node1"].Status = Suspect
+		m.mu.Unlock()
+		m.MarkAlive("node1")
+		m.probe(func(ctx context.Context, addr string) error { return nil }) // Still alive: no change
+		m.AliveNodes()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Membership deadlocked notifying a change")
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case nodes := <-changed:
+			if len(nodes) != 2 {
+				t.Errorf("Expected 2 alive nodes in the change notification, got %v", nodes)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected a membership change notification")
+		}
+	}
+	select {
+	case nodes := <-changed:
+		t.Errorf("Expected no notification from a probe that changed nothing, got %v", nodes)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestMembership_LeftIsFinal(t *testing.T) {
+	m := NewMembership("local", "127.0.0.1:50051", 1*time.Second, 3*time.Second, 10*time.Second)
+	m.ApplyGossip([]*Member{{ID: "node1", Addr: "127.0.0.1:50052", Status: Alive, Incarnation: 1}})
+
+	m.ApplyGossip([]*Member{{ID: "node1", Addr: "127.0.0.1:50052", Status: Left, Incarnation: 1}})
+	if got := m.members["node1"].Status; got != Left {
+		t.Fatalf("Expected node1 LEFT after gossip, got %v", got)
+	}
+
+	// Neither a newer incarnation nor a successful probe brings it back
+	m.ApplyGossip([]*Member{{ID: "node1", Addr: "127.0.0.1:50052", Status: Alive, Incarnation: 5}})
+	m.MarkAlive("node1")
+	if got := m.members["node1"].Status; got != Left {
+		t.Errorf("Expected node1 to stay LEFT, got %v", got)
+	}
+	if nodes := m.AliveNodes(); len(nodes) != 1 {
+		t.Errorf("Expected only the local node alive, got %v", nodes)
+	}
+
+	m.Leave()
+	for _, member := range m.Snapshot() {
+		if member.ID == "local" && member.Status != Left {
+			t.Errorf("Expected the local member LEFT after Leave, got %v", member.Status)
+		}
+	}
+}
@@ -2,7 +2,7 @@ package gossip
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"time"
 
 	kvstorepb "kvstore/internal/gen/api"
@@ -50,7 +50,7 @@ func (s *Server) Ping(ctx context.Context, req *kvstorepb.PingRequest) (*kvstore
 
 // Gossip handles gossip requests for membership propagation.
 func (s *Server) Gossip(ctx context.Context, req *kvstorepb.GossipRequest) (*kvstorepb.GossipResponse, error) {
-	log.Printf("[%s] Received gossip from %s with %d members", s.membership.localID, req.FromId, len(req.Membership))
+	s.membership.log.Debug("Received gossip", slog.String("from", req.FromId), slog.Int("members", len(req.Membership)))
 
 	// Apply received membership
 	members := protoToMembers(req.Membership)
@@ -83,6 +83,7 @@ func (s *Server) GetRing(ctx context.Context, req *kvstorepb.GetRingRequest) (*k
 		return &kvstorepb.GetRingResponse{
 			AliveMembers:      int32(len(aliveNodes)),
 			ReplicationFactor: int32(s.replicationFactor),
+			Vnodes:            int32(rng.GetVNodes()),
 		}, nil
 	}
 
@@ -92,6 +93,7 @@ func (s *Server) GetRing(ctx context.Context, req *kvstorepb.GetRingRequest) (*k
 		return &kvstorepb.GetRingResponse{
 			AliveMembers:      int32(len(aliveNodes)),
 			ReplicationFactor: int32(s.replicationFactor),
+			Vnodes:            int32(rng.GetVNodes()),
 		}, nil
 	}
 
@@ -111,6 +113,7 @@ func (s *Server) GetRing(ctx context.Context, req *kvstorepb.GetRingRequest) (*k
 		ReplicaAddrs:      replicaAddrs,
 		AliveMembers:      int32(len(aliveNodes)),
 		ReplicationFactor: int32(s.replicationFactor),
+		Vnodes:            int32(rng.GetVNodes()),
 	}, nil
 }
 
@@ -33,6 +33,34 @@ type Node struct {
 	healthClient kvstorepb.MembershipClient
 }
 
+var (
+	buildOnce   sync.Once
+	builtBinary string
+	buildErr    error
+)
+
+// Binary returns the kvstore binary to test: $KVSTORE_BINARY if set,
+// otherwise cmd/kvstore built once per test run into .local/bin.
+func Binary() (string, error) {
+	if path := os.Getenv("KVSTORE_BINARY"); path != "" {
+		return path, nil
+	}
+	buildOnce.Do(func() {
+		path, err := filepath.Abs(filepath.Join(".local", "bin", "kvstore"))
+		if err != nil {
+			buildErr = err
+			return
+		}
+		out, err := exec.Command("go", "build", "-o", path, "kvstore/cmd/kvstore").CombinedOutput()
+		if err != nil {
+			buildErr = fmt.Errorf("failed to build kvstore: %w\n%s", err, out)
+			return
+		}
+		builtBinary = path
+	})
+	return builtBinary, buildErr
+}
+
 // NewCluster creates a new test cluster harness
 func NewCluster(binaryPath string) (*Cluster, error) {
 	logDir := filepath.Join(".local", "it-logs")
@@ -129,6 +157,50 @@ func (c *Cluster) StartNode(ctx context.Context, nodeID string, port int, seeds
 	return nil
 }
 
+// waitForMembers waits until every node sees all nodes of the cluster alive,
+// so that every ring holds all of them.
+func (c *Cluster) waitForMembers(ctx context.Context, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if time.Now().After(deadline) {
+				return fmt.Errorf("timeout waiting for %d nodes to see each other alive", len(c.nodes))
+			}
+			if c.converged(ctx) {
+				return nil
+			}
+		}
+	}
+}
+
+// converged reports whether every node sees all nodes alive.
+func (c *Cluster) converged(ctx context.Context) bool {
+	for _, node := range c.nodes {
+		memberCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		resp, err := node.healthClient.GetMembership(memberCtx, &kvstorepb.GetMembershipRequest{})
+		cancel()
+		if err != nil {
+			return false
+		}
+		alive := 0
+		for _, m := range resp.Members {
+			if m.Status == kvstorepb.MemberStatus_ALIVE {
+				alive++
+			}
+		}
+		if alive != len(c.nodes) {
+			return false
+		}
+	}
+	return true
+}
+
 // waitForReady waits for a node to be ready by checking health endpoint
 func (c *Cluster) waitForReady(ctx context.Context, node *Node, timeout time.Duration) error {
 	deadline := time.Now().Add(timeout)
@@ -189,12 +261,12 @@ func (n *Node) GetHealthClient() kvstorepb.MembershipClient {
 
 // StartCluster starts a 3-node cluster with default settings
 func (c *Cluster) StartCluster(ctx context.Context) error {
-	// Build binary if needed
 	if c.binaryPath == "" {
-		c.binaryPath = "./kvstore"
-	}
-	if _, err := os.Stat(c.binaryPath); os.IsNotExist(err) {
-		return fmt.Errorf("binary not found at %s, build it first with 'go build -o kvstore ./cmd/kvstore'", c.binaryPath)
+		path, err := Binary()
+		if err != nil {
+			return err
+		}
+		c.binaryPath = path
 	}
 
 	// Start nodes sequentially
@@ -221,6 +293,10 @@ func (c *Cluster) StartCluster(ctx context.Context) error {
 		time.Sleep(1 * time.Second)
 	}
 
+	if err := c.waitForMembers(ctx, 15*time.Second); err != nil {
+		c.Stop()
+		return err
+	}
 	return nil
 }
 
@@ -2,7 +2,6 @@ package it
 
 import (
 	"context"
-	"os"
 	"testing"
 	"time"
 
@@ -12,11 +11,11 @@ import (
 )
 
 func TestSmoke_PutGetDelete_SingleKey(t *testing.T) {
-	// Build binary if needed
-	binaryPath := "./kvstore"
-	if _, err := os.Stat(binaryPath); os.IsNotExist(err) {
-		t.Skip("Binary not found, skipping integration test. Build with: go build -o kvstore ./cmd/kvstore")
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
 	}
+	binaryPath, err := Binary()
+	require.NoError(t, err)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
@@ -87,16 +86,51 @@ func TestSmoke_PutGetDelete_SingleKey(t *testing.T) {
 		getResp2.Status == kvstorepb.GetResponse_NOT_FOUND ||
 			(getResp2.Value != nil && getResp2.Value.Deleted),
 		"Expected NOT_FOUND or deleted=true after delete")
+
+	// A value written with a TTL expires on every replica
+	ttlCtx, ttlCancel := context.WithTimeout(ctx, 10*time.Second)
+	ttlResp, err := client.Put(ttlCtx, &kvstorepb.PutRequest{
+		Key:          "ttl-key",
+		Value:        []byte("ttl-value"),
+		TtlMs:        500,
+		ConsistencyW: 3,
+		ClientId:     "test-client",
+		RequestId:    "req-6",
+	})
+	ttlCancel()
+	require.NoError(t, err)
+	require.Equal(t, kvstorepb.PutResponse_SUCCESS, ttlResp.Status)
+	time.Sleep(time.Second)
+	getCtx4, getCancel4 := context.WithTimeout(ctx, 10*time.Second)
+	getResp4, err := client.Get(getCtx4, &kvstorepb.GetRequest{
+		Key:          "ttl-key",
+		ConsistencyR: 3,
+		ClientId:     "test-client",
+		RequestId:    "req-7",
+	})
+	getCancel4()
+	require.NoError(t, err)
+	assert.Equal(t, kvstorepb.GetResponse_NOT_FOUND, getResp4.Status)
+
+	// Get a key that was never written
+	getCtx3, getCancel3 := context.WithTimeout(ctx, 10*time.Second)
+	getResp3, err := client.Get(getCtx3, &kvstorepb.GetRequest{
+		Key:          "missing-key",
+		ConsistencyR: 2,
+		ClientId:     "test-client",
+		RequestId:    "req-5",
+	})
+	getCancel3()
+	require.NoError(t, err)
+	assert.Equal(t, kvstorepb.GetResponse_NOT_FOUND, getResp3.Status)
 }
 
 func TestQuorum_ToleratesOneNodeDown(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
 	}
-	binaryPath := "./kvstore"
-	if _, err := os.Stat(binaryPath); os.IsNotExist(err) {
-		t.Skip("Binary not found, skipping integration test")
-	}
+	binaryPath, err := Binary()
+	require.NoError(t, err)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
 	defer cancel()
@@ -177,10 +211,8 @@ func TestConflicts_ConcurrentWrites_ReturnSiblings(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
 	}
-	binaryPath := "./kvstore"
-	if _, err := os.Stat(binaryPath); os.IsNotExist(err) {
-		t.Skip("Binary not found, skipping integration test")
-	}
+	binaryPath, err := Binary()
+	require.NoError(t, err)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
 	defer cancel()
@@ -248,35 +280,10 @@ func TestConflicts_ConcurrentWrites_ReturnSiblings(t *testing.T) {
 		t.Logf("Got single winner instead of conflicts (timing-dependent), proceeding with resolution")
 	}
 
-	// Resolve conflicts by writing with version context
-	// Merge all sibling versions
-	mergedVersion := &kvstorepb.VectorClock{}
-	if len(getResp.Conflicts) > 0 {
-		// Use first conflict's version as base
-		mergedVersion = getResp.Conflicts[0].Version
-		// Merge other conflicts
-		for i := 1; i < len(getResp.Conflicts); i++ {
-			// Simple merge: take max of each node's counter
-			existing := make(map[string]int64)
-			for _, e := range mergedVersion.Entries {
-				existing[e.NodeId] = e.Counter
-			}
-			for _, e := range getResp.Conflicts[i].Version.Entries {
-				if existing[e.NodeId] < e.Counter {
-					existing[e.NodeId] = e.Counter
-				}
-			}
-			mergedVersion.Entries = make([]*kvstorepb.VectorClockEntry, 0, len(existing))
-			for nodeID, counter := range existing {
-				mergedVersion.Entries = append(mergedVersion.Entries, &kvstorepb.VectorClockEntry{
-					NodeId:  nodeID,
-					Counter: counter,
-				})
-			}
-		}
-	}
+	// Resolve conflicts by writing with the causal context, which covers all siblings
+	require.NotEmpty(t, getResp.Context, "Expected causal context in Get response")
 
-	// Write resolved value with merged version context
+	// Write resolved value with the causal context
 	resolveCtx, resolveCancel := context.WithTimeout(ctx, 10*time.Second)
 	resolveResp, err := client1.Put(resolveCtx, &kvstorepb.PutRequest{
 		Key:          "conflict-key",
@@ -284,7 +291,7 @@ func TestConflicts_ConcurrentWrites_ReturnSiblings(t *testing.T) {
 		ConsistencyW: 2,
 		ClientId:     "client3",
 		RequestId:    "req-4",
-		Version:      mergedVersion,
+		Context:      getResp.Context,
 	})
 	resolveCancel()
 	require.NoError(t, err)
@@ -310,10 +317,8 @@ func TestReadRepair_RepairsStaleReplica(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
 	}
-	binaryPath := "./kvstore"
-	if _, err := os.Stat(binaryPath); os.IsNotExist(err) {
-		t.Skip("Binary not found, skipping integration test")
-	}
+	binaryPath, err := Binary()
+	require.NoError(t, err)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
 	defer cancel()
@@ -0,0 +1,14 @@
+// Package logging provides the structured, leveled loggers of a node, built
+// on log/slog.
+//
+// Every logger belongs to a subsystem, such as gossip or replica, whose
+// level can be changed while the node runs. Records carry their fields as
+// attributes (node_id, request_id, client_id, key_hash) and are written as
+// JSON or as text. Keys are logged as a hash, so that logs can be correlated
+// by key without exposing it.
+//
+// Per-request logs are at debug level. To keep them affordable when enabled,
+// records below warn are sampled: each message is logged at most a burst of
+// times per second, and the number dropped is reported on the next one
+// logged.
+package logging
@@ -0,0 +1,280 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Subsystems
+const (
+	Node    = "node"    // Lifecycle, configuration and namespaces
+	Server  = "server"  // Client requests coordinated by the node
+	Replica = "replica" // Replica RPCs served to coordinators
+	Repair  = "repair"  // Read repair
+	Gossip  = "gossip"  // Membership and failure detection
+	Storage = "storage" // The local store
+	TLS     = "tls"     // Certificate reloads
+	Tracing = "tracing" // Trace export
+)
+
+// Subsystems lists every subsystem.
+var Subsystems = []string{Node, Server, Replica, Repair, Gossip, Storage, TLS, Tracing}
+
+// Output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// DefaultSampleBurst is the default number of records below warn logged per
+// message per second.
+const DefaultSampleBurst = 100
+
+// Config holds the settings that can change while the node runs.
+type Config struct {
+	Level       slog.Level            // Level of subsystems not in Levels
+	Levels      map[string]slog.Level // Level per subsystem
+	SampleBurst int                   // Records below warn logged per message per second (0 = all)
+}
+
+// DefaultConfig logs at info level with the default sampling.
+func DefaultConfig() Config {
+	return Config{Level: slog.LevelInfo, SampleBurst: DefaultSampleBurst}
+}
+
+// Validate checks the subsystem names and the sample burst.
+func (c Config) Validate() error {
+	for name := range c.Levels {
+		if !validSubsystem(name) {
+			return fmt.Errorf("unknown log subsystem %q (expected one of %s)", name, strings.Join(Subsystems, ", "))
+		}
+	}
+	if c.SampleBurst < 0 {
+		return fmt.Errorf("log sample burst must not be negative, got %d", c.SampleBurst)
+	}
+	return nil
+}
+
+// LevelOf returns the level of a subsystem.
+func (c Config) LevelOf(subsystem string) slog.Level {
+	if level, ok := c.Levels[subsystem]; ok {
+		return level
+	}
+	return c.Level
+}
+
+// String formats the levels as ParseLevels accepts them, e.g.
+// "info,gossip=debug".
+func (c Config) String() string {
+	parts := []string{strings.ToLower(c.Level.String())}
+	names := make([]string, 0, len(c.Levels))
+	for name := range c.Levels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		parts = append(parts, name+"="+strings.ToLower(c.Levels[name].String()))
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseLevel parses debug, info, warn or error, case-insensitively.
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return 0, fmt.Errorf("invalid log level %q (expected debug, info, warn or error)", s)
+	}
+	return level, nil
+}
+
+// ParseLevels parses comma-separated subsystem=level pairs, e.g.
+// "gossip=debug,server=warn".
+func ParseLevels(s string) (map[string]slog.Level, error) {
+	levels := make(map[string]slog.Level)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid log level %q (expected subsystem=level)", part)
+		}
+		name = strings.TrimSpace(name)
+		if !validSubsystem(name) {
+			return nil, fmt.Errorf("unknown log subsystem %q (expected one of %s)", name, strings.Join(Subsystems, ", "))
+		}
+		level, err := ParseLevel(value)
+		if err != nil {
+			return nil, err
+		}
+		levels[name] = level
+	}
+	return levels, nil
+}
+
+func validSubsystem(name string) bool {
+	for _, s := range Subsystems {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Logs holds the loggers of every subsystem and their levels.
+type Logs struct {
+	base    slog.Handler
+	levels  map[string]*slog.LevelVar
+	sampler *sampler
+	config  atomic.Pointer[Config]
+}
+
+// New creates loggers writing to w in the given format.
+func New(w io.Writer, format string, cfg Config) (*Logs, error) {
+	var base slog.Handler
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug} // Levels are checked per subsystem
+	switch format {
+	case FormatText:
+		base = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		base = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q (expected %s or %s)", format, FormatText, FormatJSON)
+	}
+	l := &Logs{base: base, levels: make(map[string]*slog.LevelVar), sampler: newSampler()}
+	for _, name := range Subsystems {
+		l.levels[name] = new(slog.LevelVar)
+	}
+	if err := l.SetConfig(cfg); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// SetConfig changes the levels and sampling. It is safe to call while
+// logging.
+func (l *Logs) SetConfig(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	for name, level := range l.levels {
+		level.Set(cfg.LevelOf(name))
+	}
+	l.sampler.setBurst(cfg.SampleBurst)
+	l.config.Store(&cfg)
+	return nil
+}
+
+// Config returns the active levels and sampling.
+func (l *Logs) Config() Config {
+	return *l.config.Load()
+}
+
+// Logger returns the logger of a subsystem, which must be one of Subsystems.
+func (l *Logs) Logger(subsystem string) *slog.Logger {
+	level, ok := l.levels[subsystem]
+	if !ok {
+		panic(fmt.Sprintf("logging: unknown subsystem %q", subsystem))
+	}
+	h := &handler{base: l.base, level: level, sampler: l.sampler, subsystem: subsystem}
+	return slog.New(h).With("subsystem", subsystem)
+}
+
+var defaultLogs atomic.Pointer[Logs]
+
+func init() {
+	l, _ := New(os.Stderr, FormatText, DefaultConfig())
+	defaultLogs.Store(l)
+}
+
+// Default returns the process-wide loggers that components log to. They
+// write text to standard error at info level until SetDefault replaces them.
+func Default() *Logs {
+	return defaultLogs.Load()
+}
+
+// SetDefault replaces the process-wide loggers. Components created
+// afterwards log to l.
+func SetDefault(l *Logs) {
+	defaultLogs.Store(l)
+}
+
+// For returns the logger of a subsystem of the default loggers.
+func For(subsystem string) *slog.Logger {
+	return Default().Logger(subsystem)
+}
+
+// handler checks the level of its subsystem and samples records below warn
+// before passing them on.
+type handler struct {
+	base      slog.Handler
+	level     *slog.LevelVar
+	sampler   *sampler
+	subsystem string
+	group     string // Prefix of sampled messages from WithGroup
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelWarn {
+		ok, dropped := h.sampler.allow(h.subsystem + "\x00" + r.Message)
+		if !ok {
+			return nil
+		}
+		if dropped > 0 {
+			r.AddAttrs(slog.Int("dropped", dropped))
+		}
+	}
+	return h.base.Handle(ctx, r)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.base = h.base.WithAttrs(attrs)
+	return &c
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	c := *h
+	c.base = h.base.WithGroup(name)
+	return &c
+}
+
+// NodeID returns the node_id field.
+func NodeID(id string) slog.Attr { return slog.String("node_id", id) }
+
+// RequestID returns the request_id field.
+func RequestID(id string) slog.Attr { return slog.String("request_id", id) }
+
+// ClientID returns the client_id field.
+func ClientID(id string) slog.Attr { return slog.String("client_id", id) }
+
+// Key returns the key_hash field of key (see KeyHash).
+func Key(key string) slog.Attr { return slog.String("key_hash", KeyHash(key)) }
+
+// KeyHash returns a short hash identifying key in logs: 16 hex digits of its
+// 64-bit FNV-1a hash.
+func KeyHash(key string) string {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// Err returns the error field.
+func Err(err error) slog.Attr {
+	if err == nil {
+		return slog.String("error", "")
+	}
+	return slog.String("error", err.Error())
+}
@@ -0,0 +1,116 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevels(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]slog.Level
+		wantErr bool
+	}{
+		{name: "empty", input: "", want: map[string]slog.Level{}},
+		{name: "single", input: "gossip=debug", want: map[string]slog.Level{Gossip: slog.LevelDebug}},
+		{name: "several", input: "gossip=debug, server=WARN", want: map[string]slog.Level{Gossip: slog.LevelDebug, Server: slog.LevelWarn}},
+		{name: "unknown subsystem", input: "disk=debug", wantErr: true},
+		{name: "unknown level", input: "gossip=loud", wantErr: true},
+		{name: "missing level", input: "gossip", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLevels(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConfig_String(t *testing.T) {
+	cfg := Config{Level: slog.LevelInfo, Levels: map[string]slog.Level{Server: slog.LevelWarn, Gossip: slog.LevelDebug}}
+	assert.Equal(t, "info,gossip=debug,server=warn", cfg.String())
+
+	levels, err := ParseLevels(strings.TrimPrefix(cfg.String(), "info,"))
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Levels, levels)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, DefaultConfig().Validate())
+	assert.Error(t, Config{Levels: map[string]slog.Level{"disk": slog.LevelInfo}}.Validate())
+	assert.Error(t, Config{SampleBurst: -1}.Validate())
+}
+
+func TestNew_UnknownFormat(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, "xml", DefaultConfig())
+	assert.Error(t, err)
+}
+
+func TestLogs_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	logs, err := New(&buf, FormatJSON, DefaultConfig())
+	require.NoError(t, err)
+
+	logs.Logger(Server).With(NodeID("node1")).Info("Put", RequestID("r1"), ClientID("c1"), Key("user:1"))
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "Put", record["msg"])
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, Server, record["subsystem"])
+	assert.Equal(t, "node1", record["node_id"])
+	assert.Equal(t, "r1", record["request_id"])
+	assert.Equal(t, "c1", record["client_id"])
+	assert.Equal(t, KeyHash("user:1"), record["key_hash"])
+	assert.NotContains(t, buf.String(), "user:1")
+}
+
+func TestLogs_SubsystemLevels(t *testing.T) {
+	var buf bytes.Buffer
+	logs, err := New(&buf, FormatText, Config{Level: slog.LevelInfo, Levels: map[string]slog.Level{Gossip: slog.LevelDebug}})
+	require.NoError(t, err)
+
+	logs.Logger(Gossip).Debug("gossip debug")
+	logs.Logger(Server).Debug("server debug")
+	logs.Logger(Server).Info("server info")
+
+	assert.Contains(t, buf.String(), "gossip debug")
+	assert.NotContains(t, buf.String(), "server debug")
+	assert.Contains(t, buf.String(), "server info")
+}
+
+func TestLogs_SetConfig(t *testing.T) {
+	var buf bytes.Buffer
+	logs, err := New(&buf, FormatText, DefaultConfig())
+	require.NoError(t, err)
+	logger := logs.Logger(Replica)
+
+	logger.Debug("before")
+	require.NoError(t, logs.SetConfig(Config{Level: slog.LevelDebug}))
+	logger.Debug("after")
+
+	assert.NotContains(t, buf.String(), "before")
+	assert.Contains(t, buf.String(), "after")
+	assert.Equal(t, slog.LevelDebug, logs.Config().Level)
+
+	assert.Error(t, logs.SetConfig(Config{SampleBurst: -1}))
+	assert.Equal(t, slog.LevelDebug, logs.Config().Level, "invalid config must not be applied")
+}
+
+func TestKeyHash(t *testing.T) {
+	assert.Len(t, KeyHash("key"), 16)
+	assert.Equal(t, KeyHash("key"), KeyHash("key"))
+	assert.NotEqual(t, KeyHash("key"), KeyHash("other"))
+}
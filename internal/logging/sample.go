@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// sampler limits the records logged per message to a burst per second.
+type sampler struct {
+	burst atomic.Int64 // 0 = unlimited
+	now   func() time.Time
+
+	mu       sync.Mutex
+	messages map[string]*window
+}
+
+// window counts the records of one message in the current second.
+type window struct {
+	second  int64
+	logged  int64
+	dropped int64
+}
+
+func newSampler() *sampler {
+	return &sampler{now: time.Now, messages: make(map[string]*window)}
+}
+
+func (s *sampler) setBurst(burst int) {
+	s.burst.Store(int64(burst))
+}
+
+// allow reports whether a record of message may be logged, and if so how
+// many records of it were dropped in the previous second.
+func (s *sampler) allow(message string) (ok bool, dropped int) {
+	burst := s.burst.Load()
+	if burst == 0 {
+		return true, 0
+	}
+	second := s.now().Unix()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, exists := s.messages[message]
+	if !exists {
+		w = &window{second: second}
+		s.messages[message] = w
+	}
+	var carried int64
+	if w.second != second {
+		carried = w.dropped
+		*w = window{second: second}
+	}
+	if w.logged >= burst {
+		w.dropped++
+		return false, 0
+	}
+	w.logged++
+	return true, int(carried)
+}
@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampler(t *testing.T) {
+	now := time.Unix(100, 0)
+	s := newSampler()
+	s.now = func() time.Time { return now }
+	s.setBurst(2)
+
+	for i := 0; i < 2; i++ {
+		ok, dropped := s.allow("a")
+		assert.True(t, ok)
+		assert.Zero(t, dropped)
+	}
+	for i := 0; i < 3; i++ {
+		ok, _ := s.allow("a")
+		assert.False(t, ok)
+	}
+	ok, _ := s.allow("b")
+	assert.True(t, ok, "messages are sampled separately")
+
+	now = now.Add(time.Second)
+	ok, dropped := s.allow("a")
+	assert.True(t, ok)
+	assert.Equal(t, 3, dropped, "drops are reported on the next record logged")
+	_, dropped = s.allow("a")
+	assert.Zero(t, dropped)
+}
+
+func TestSampler_Unlimited(t *testing.T) {
+	s := newSampler()
+	for i := 0; i < 1000; i++ {
+		ok, _ := s.allow("a")
+		require.True(t, ok)
+	}
+}
+
+func TestLogs_Sampling(t *testing.T) {
+	var buf bytes.Buffer
+	logs, err := New(&buf, FormatText, Config{Level: slog.LevelDebug, SampleBurst: 3})
+	require.NoError(t, err)
+	logger := logs.Logger(Server)
+
+	for i := 0; i < 10; i++ {
+		logger.Debug("hot")
+		logger.Warn("failing")
+	}
+
+	assert.LessOrEqual(t, strings.Count(buf.String(), "msg=hot"), 6, "at most a burst per second")
+	assert.Equal(t, 10, strings.Count(buf.String(), "msg=failing"), "warnings are never sampled")
+}
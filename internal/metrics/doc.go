@@ -0,0 +1,8 @@
+// Package metrics holds the Prometheus helpers shared by the node: a registry
+// served with promhttp, default latency buckets, and collectors for values
+// that are already tracked elsewhere.
+//
+// Values such as the size of the store or the counts of a component's Stats
+// are not copied into metrics as they change; they are registered with
+// NewCounterFunc, NewGaugeFunc or NewHistogramFunc and read on every scrape.
+package metrics
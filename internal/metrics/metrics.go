@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultBuckets are latency buckets in seconds, from 1ms to 10s.
+var DefaultBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *prometheus.Registry {
+	return prometheus.NewRegistry()
+}
+
+// Handler serves the metrics of reg. Errors collecting a metric are reported
+// in the response rather than failing the scrape.
+func Handler(reg *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})
+}
+
+// Emit reports one series of a collected metric.
+type Emit func(value float64, labelValues ...string)
+
+// NewCounterFunc returns a counter with the given label names whose series
+// are reported by collect on every scrape.
+func NewCounterFunc(name, help string, labels []string, collect func(Emit)) prometheus.Collector {
+	return &funcCollector{desc: prometheus.NewDesc(name, help, labels, nil), kind: prometheus.CounterValue, collect: collect}
+}
+
+// NewGaugeFunc returns a gauge with the given label names whose series are
+// reported by collect on every scrape.
+func NewGaugeFunc(name, help string, labels []string, collect func(Emit)) prometheus.Collector {
+	return &funcCollector{desc: prometheus.NewDesc(name, help, labels, nil), kind: prometheus.GaugeValue, collect: collect}
+}
+
+// NewHistogramFunc returns a histogram without labels whose sample count,
+// sum and cumulative bucket counts (keyed by upper bound) are reported by
+// collect on every scrape.
+func NewHistogramFunc(name, help string, collect func() (count uint64, sum float64, buckets map[float64]uint64)) prometheus.Collector {
+	return &histogramFuncCollector{desc: prometheus.NewDesc(name, help, nil, nil), collect: collect}
+}
+
+// funcCollector collects the series of one metric from a function.
+type funcCollector struct {
+	desc    *prometheus.Desc
+	kind    prometheus.ValueType
+	collect func(Emit)
+}
+
+func (c *funcCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *funcCollector) Collect(ch chan<- prometheus.Metric) {
+	c.collect(func(value float64, labelValues ...string) {
+		ch <- prometheus.MustNewConstMetric(c.desc, c.kind, value, labelValues...)
+	})
+}
+
+// histogramFuncCollector collects one histogram from a function.
+type histogramFuncCollector struct {
+	desc    *prometheus.Desc
+	collect func() (uint64, float64, map[float64]uint64)
+}
+
+func (c *histogramFuncCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *histogramFuncCollector) Collect(ch chan<- prometheus.Metric) {
+	count, sum, buckets := c.collect()
+	ch <- prometheus.MustNewConstHistogram(c.desc, count, sum, buckets)
+}
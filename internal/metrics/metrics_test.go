@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestFuncCollectors(t *testing.T) {
+	members := NewGaugeFunc("test_members", "Members by status.", []string{"status"}, func(emit Emit) {
+		emit(1, "suspect")
+		emit(2, "alive")
+	})
+	if err := testutil.CollectAndCompare(members, strings.NewReader(`
+# HELP test_members Members by status.
+# TYPE test_members gauge
+test_members{status="alive"} 2
+test_members{status="suspect"} 1
+`)); err != nil {
+		t.Error(err)
+	}
+
+	repairs := NewCounterFunc("test_repairs_total", "Repairs.", nil, func(emit Emit) { emit(7) })
+	if err := testutil.CollectAndCompare(repairs, strings.NewReader(`
+# HELP test_repairs_total Repairs.
+# TYPE test_repairs_total counter
+test_repairs_total 7
+`)); err != nil {
+		t.Error(err)
+	}
+
+	sizes := NewHistogramFunc("test_size", "Sizes.", func() (uint64, float64, map[float64]uint64) {
+		return 3, 10, map[float64]uint64{2: 1, 4: 2}
+	})
+	if err := testutil.CollectAndCompare(sizes, strings.NewReader(`
+# HELP test_size Sizes.
+# TYPE test_size histogram
+test_size_bucket{le="2"} 1
+test_size_bucket{le="4"} 2
+test_size_bucket{le="+Inf"} 3
+test_size_sum 10
+test_size_count 3
+`)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	reg := NewRegistry()
+	reg.MustRegister(NewCounterFunc("test_total", "Collected.", nil, func(emit Emit) { emit(7) }))
+	latency := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "test_duration_seconds", Help: "Request latency.", Buckets: []float64{0.01, 0.1},
+	}, []string{"method"})
+	reg.MustRegister(latency)
+	latency.WithLabelValues("Get").Observe(0.05)
+
+	rec := httptest.NewRecorder()
+	Handler(reg).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("Content-Type = %q, want the text format", rec.Header().Get("Content-Type"))
+	}
+	for _, want := range []string{
+		"test_total 7\n",
+		`test_duration_seconds_bucket{method="Get",le="0.1"} 1` + "\n",
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("body missing %q:\n%s", want, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,9 @@
+// Package namespace defines namespaces: named key spaces with their own
+// replication factor, default quorums, TTL, conflict policy and quotas.
+//
+// Namespace definitions are cluster metadata. Every node holds them in a
+// Registry, and nodes exchange their registries so that changes made through
+// any node reach all of them. Each definition carries the HLC timestamp of its
+// last change; the newest definition of a name wins, and deleted namespaces
+// remain as tombstones so that a deletion is not undone by a stale copy.
+package namespace
@@ -0,0 +1,99 @@
+package namespace
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"kvstore/internal/clock"
+	"kvstore/internal/conflict"
+	"kvstore/internal/storage"
+)
+
+// ErrQuotaExceeded is returned for writes that exceed a namespace quota.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// Namespace is a named key space with its own settings.
+type Namespace struct {
+	Name              string
+	ReplicationFactor int             // N
+	R                 int             // Default read quorum (0 = majority of N)
+	W                 int             // Default write quorum (0 = majority of N)
+	DefaultTTL        time.Duration   // Values expire this long after they are written (0 = never)
+	ConflictPolicy    conflict.Policy // Resolution of concurrent versions
+	MaxKeys           int64           // Keys each node stores for the namespace (0 = unlimited)
+	MaxValueBytes     int64           // Size of a single value (0 = unlimited)
+	MaxBytes          int64           // Bytes of keys and values each node stores for the namespace (0 = unlimited)
+
+	Deleted   bool            // Tombstone of a deleted namespace
+	UpdatedAt clock.Timestamp // Time of the last change (newest wins)
+}
+
+// Validate checks the settings of a namespace.
+func (ns Namespace) Validate() error {
+	if !validName.MatchString(ns.Name) {
+		return fmt.Errorf("invalid namespace name %q (expected 1-63 lowercase letters, digits, '-' or '_')", ns.Name)
+	}
+	if ns.ReplicationFactor < 1 {
+		return fmt.Errorf("namespace %s: replication factor must be at least 1, got %d", ns.Name, ns.ReplicationFactor)
+	}
+	if ns.R < 0 || ns.R > ns.ReplicationFactor {
+		return fmt.Errorf("namespace %s: r must be between 0 (majority) and N (%d), got %d", ns.Name, ns.ReplicationFactor, ns.R)
+	}
+	if ns.W < 0 || ns.W > ns.ReplicationFactor {
+		return fmt.Errorf("namespace %s: w must be between 0 (majority) and N (%d), got %d", ns.Name, ns.ReplicationFactor, ns.W)
+	}
+	if ns.DefaultTTL < 0 {
+		return fmt.Errorf("namespace %s: ttl cannot be negative", ns.Name)
+	}
+	if ns.MaxKeys < 0 || ns.MaxValueBytes < 0 || ns.MaxBytes < 0 {
+		return fmt.Errorf("namespace %s: quotas cannot be negative", ns.Name)
+	}
+	return nil
+}
+
+// Quorums returns the default read and write quorums.
+func (ns Namespace) Quorums() (r, w int) {
+	majority := ns.ReplicationFactor/2 + 1
+	r, w = ns.R, ns.W
+	if r == 0 {
+		r = majority
+	}
+	if w == 0 {
+		w = majority
+	}
+	return r, w
+}
+
+// Expired reports whether a value written at ts has outlived the TTL.
+func (ns Namespace) Expired(ts clock.Timestamp, now time.Time) bool {
+	return ns.DefaultTTL > 0 && !ts.IsZero() && now.Sub(ts.Time()) >= ns.DefaultTTL
+}
+
+// Admit checks that a node whose storage of the namespace is usage may store
+// value under key, replacing existing (nil if the key is not stored). Writes
+// that do not grow the usage are always admitted, so a namespace over its
+// quota can still be cleaned up.
+func (ns Namespace) Admit(usage storage.Usage, key string, existing *storage.VersionedValue, value []byte) error {
+	if ns.MaxValueBytes > 0 && int64(len(value)) > ns.MaxValueBytes {
+		return fmt.Errorf("%w: value of %d bytes exceeds the %d byte limit of namespace %s",
+			ErrQuotaExceeded, len(value), ns.MaxValueBytes, ns.Name)
+	}
+	live := existing != nil && !existing.Deleted
+	if !live && ns.MaxKeys > 0 && int64(usage.Keys) >= ns.MaxKeys {
+		return fmt.Errorf("%w: namespace %s has reached its limit of %d keys", ErrQuotaExceeded, ns.Name, ns.MaxKeys)
+	}
+	if ns.MaxBytes > 0 {
+		grow := int64(len(key) + len(value))
+		if live {
+			grow -= int64(len(key) + len(existing.Value))
+		}
+		if grow > 0 && usage.Bytes+grow > ns.MaxBytes {
+			return fmt.Errorf("%w: namespace %s has reached its limit of %d bytes", ErrQuotaExceeded, ns.Name, ns.MaxBytes)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package namespace
+
+import (
+	"sort"
+	"sync"
+)
+
+// Registry holds the namespace definitions known to a node. It is safe for
+// concurrent use.
+type Registry struct {
+	mu         sync.RWMutex
+	namespaces map[string]Namespace // Including tombstones
+	onChange   func()
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{namespaces: make(map[string]Namespace)}
+}
+
+// SetOnChange sets a callback invoked after Merge changes the registry.
+func (r *Registry) SetOnChange(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onChange = fn
+}
+
+// Get returns a namespace, unless it does not exist or was deleted.
+func (r *Registry) Get(name string) (Namespace, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ns, ok := r.namespaces[name]
+	if !ok || ns.Deleted {
+		return Namespace{}, false
+	}
+	return ns, true
+}
+
+// List returns the namespaces that were not deleted, sorted by name.
+func (r *Registry) List() []Namespace {
+	var list []Namespace
+	for _, ns := range r.All() {
+		if !ns.Deleted {
+			list = append(list, ns)
+		}
+	}
+	return list
+}
+
+// All returns every definition, including tombstones, sorted by name.
+func (r *Registry) All() []Namespace {
+	r.mu.RLock()
+	list := make([]Namespace, 0, len(r.namespaces))
+	for _, ns := range r.namespaces {
+		list = append(list, ns)
+	}
+	r.mu.RUnlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Merge applies definitions from another node or an admin change. A
+// definition replaces the known one if its UpdatedAt is newer. It reports
+// whether anything changed.
+func (r *Registry) Merge(defs ...Namespace) bool {
+	r.mu.Lock()
+	changed := false
+	for _, ns := range defs {
+		if cur, ok := r.namespaces[ns.Name]; ok && !ns.UpdatedAt.Newer(cur.UpdatedAt) {
+			continue
+		}
+		r.namespaces[ns.Name] = ns
+		changed = true
+	}
+	onChange := r.onChange
+	r.mu.Unlock()
+
+	if changed && onChange != nil {
+		onChange()
+	}
+	return changed
+}
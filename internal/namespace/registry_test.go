@@ -0,0 +1,121 @@
+package namespace
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"kvstore/internal/clock"
+	"kvstore/internal/storage"
+)
+
+func TestRegistry_Merge(t *testing.T) {
+	r := NewRegistry()
+	changes := 0
+	r.SetOnChange(func() { changes++ })
+
+	v1 := Namespace{Name: "orders", ReplicationFactor: 3, UpdatedAt: clock.Timestamp{WallTime: 100, NodeID: "n1"}}
+	v2 := Namespace{Name: "orders", ReplicationFactor: 5, UpdatedAt: clock.Timestamp{WallTime: 200, NodeID: "n2"}}
+
+	if !r.Merge(v2) {
+		t.Fatal("Expected a new namespace to change the registry")
+	}
+	if r.Merge(v1) {
+		t.Error("Expected an older definition to be ignored")
+	}
+	if ns, _ := r.Get("orders"); ns.ReplicationFactor != 5 {
+		t.Errorf("Expected the newest definition to win, got N=%d", ns.ReplicationFactor)
+	}
+	if r.Merge(v2) {
+		t.Error("Expected merging the same definition to be a no-op")
+	}
+
+	// A deletion is kept as a tombstone and wins over older definitions
+	deleted := Namespace{Name: "orders", Deleted: true, UpdatedAt: clock.Timestamp{WallTime: 300, NodeID: "n1"}}
+	r.Merge(deleted)
+	r.Merge(v2)
+	if _, ok := r.Get("orders"); ok {
+		t.Error("Expected deleted namespace to stay deleted")
+	}
+	if len(r.List()) != 0 || len(r.All()) != 1 {
+		t.Errorf("Expected only a tombstone, got List=%v All=%v", r.List(), r.All())
+	}
+	if changes != 2 {
+		t.Errorf("Expected 2 change callbacks, got %d", changes)
+	}
+}
+
+func TestNamespace_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		ns      Namespace
+		wantErr bool
+	}{
+		{"valid", Namespace{Name: "orders-eu", ReplicationFactor: 3, R: 1, W: 3}, false},
+		{"bad name", Namespace{Name: "Orders", ReplicationFactor: 3}, true},
+		{"empty name", Namespace{ReplicationFactor: 3}, true},
+		{"zero rf", Namespace{Name: "a"}, true},
+		{"w above n", Namespace{Name: "a", ReplicationFactor: 1, W: 2}, true},
+		{"negative ttl", Namespace{Name: "a", ReplicationFactor: 1, DefaultTTL: -time.Second}, true},
+		{"negative quota", Namespace{Name: "a", ReplicationFactor: 1, MaxKeys: -1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.ns.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNamespace_QuorumsAndExpiry(t *testing.T) {
+	ns := Namespace{Name: "a", ReplicationFactor: 5, W: 5, DefaultTTL: time.Minute}
+	if r, w := ns.Quorums(); r != 3 || w != 5 {
+		t.Errorf("Quorums() = %d, %d, want 3, 5", r, w)
+	}
+
+	now := time.Now()
+	written := clock.Timestamp{WallTime: now.Add(-2 * time.Minute).UnixMilli()}
+	if !ns.Expired(written, now) {
+		t.Error("Expected value older than the TTL to be expired")
+	}
+	if ns.Expired(clock.Timestamp{WallTime: now.UnixMilli()}, now) {
+		t.Error("Expected fresh value not to be expired")
+	}
+	ns.DefaultTTL = 0
+	if ns.Expired(written, now) {
+		t.Error("Expected values never to expire without a TTL")
+	}
+}
+
+func TestNamespace_Admit(t *testing.T) {
+	ns := Namespace{Name: "a", ReplicationFactor: 1, MaxKeys: 2, MaxValueBytes: 10, MaxBytes: 20}
+	existing := &storage.VersionedValue{Value: []byte("12345")}
+	tests := []struct {
+		name     string
+		usage    storage.Usage
+		existing *storage.VersionedValue
+		value    string
+		wantErr  bool
+	}{
+		{"within quotas", storage.Usage{Keys: 1, Bytes: 6}, nil, "12345", false},
+		{"value too large", storage.Usage{}, nil, "12345678901", true},
+		{"key limit reached", storage.Usage{Keys: 2, Bytes: 12}, nil, "1", true},
+		{"overwrite at key limit", storage.Usage{Keys: 2, Bytes: 12}, existing, "1", false},
+		{"recreate deleted key at key limit", storage.Usage{Keys: 2, Bytes: 12}, &storage.VersionedValue{Deleted: true}, "1", true},
+		{"byte limit reached", storage.Usage{Keys: 1, Bytes: 18}, nil, "12", true},
+		{"shrinking overwrite over byte limit", storage.Usage{Keys: 2, Bytes: 25}, existing, "1", false},
+		{"growing overwrite over byte limit", storage.Usage{Keys: 2, Bytes: 18}, existing, "12345678", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ns.Admit(tt.usage, "k", tt.existing, []byte(tt.value))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Admit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrQuotaExceeded) {
+				t.Errorf("Expected ErrQuotaExceeded, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package node
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	kvstorepb "kvstore/internal/gen/api"
+)
+
+// AdminServer implements the Admin gRPC service.
+type AdminServer struct {
+	kvstorepb.UnimplementedAdminServer
+	node *Node
+}
+
+// NewAdminServer creates an admin server for a node.
+func NewAdminServer(n *Node) *AdminServer {
+	return &AdminServer{node: n}
+}
+
+// GetConfig returns the active runtime configuration.
+func (s *AdminServer) GetConfig(ctx context.Context, req *kvstorepb.GetConfigRequest) (*kvstorepb.GetConfigResponse, error) {
+	cfg, version := s.node.RuntimeConfig()
+	return &kvstorepb.GetConfigResponse{
+		NodeId: s.node.nodeID,
+		Config: runtimeConfigToProto(cfg, version, s.node.rf),
+	}, nil
+}
+
+// ReloadConfig rereads the configuration from its sources and applies it, as
+// on SIGHUP. An invalid configuration is rejected and the active one is kept.
+func (s *AdminServer) ReloadConfig(ctx context.Context, req *kvstorepb.ReloadConfigRequest) (*kvstorepb.ReloadConfigResponse, error) {
+	if s.node.reloadFn == nil {
+		return nil, status.Error(codes.FailedPrecondition, "node has no configuration source to reload from")
+	}
+	_, changed, err := s.node.ReloadFromSource()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	cfg, version := s.node.RuntimeConfig()
+	return &kvstorepb.ReloadConfigResponse{
+		NodeId:  s.node.nodeID,
+		Config:  runtimeConfigToProto(cfg, version, s.node.rf),
+		Changed: changed,
+	}, nil
+}
+
+// ListNamespaces returns the namespaces known to the node.
+func (s *AdminServer) ListNamespaces(ctx context.Context, req *kvstorepb.ListNamespacesRequest) (*kvstorepb.ListNamespacesResponse, error) {
+	return &kvstorepb.ListNamespacesResponse{
+		Namespaces: namespacesToProto(s.node.namespaces.List()),
+	}, nil
+}
+
+// PutNamespace creates or updates a namespace and distributes it to all
+// nodes. Changes apply to new requests; stored keys are not re-replicated.
+func (s *AdminServer) PutNamespace(ctx context.Context, req *kvstorepb.PutNamespaceRequest) (*kvstorepb.PutNamespaceResponse, error) {
+	if req.Namespace == nil {
+		return nil, status.Error(codes.InvalidArgument, "namespace is required")
+	}
+	ns, err := protoToNamespace(req.Namespace)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	ns, err = s.node.PutNamespace(ctx, ns)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &kvstorepb.PutNamespaceResponse{Namespace: namespaceToProto(ns)}, nil
+}
+
+// DeleteNamespace deletes a namespace on all nodes.
+func (s *AdminServer) DeleteNamespace(ctx context.Context, req *kvstorepb.DeleteNamespaceRequest) (*kvstorepb.DeleteNamespaceResponse, error) {
+	if _, ok := s.node.namespaces.Get(req.Name); !ok {
+		return nil, status.Errorf(codes.NotFound, "namespace %q does not exist", req.Name)
+	}
+	s.node.DeleteNamespace(ctx, req.Name)
+	return &kvstorepb.DeleteNamespaceResponse{}, nil
+}
+
+// maxRepairFailures bounds the failed keys a Repair response lists.
+const maxRepairFailures = 100
+
+// GetNodeStatus returns the status of the node.
+func (s *AdminServer) GetNodeStatus(ctx context.Context, req *kvstorepb.GetNodeStatusRequest) (*kvstorepb.GetNodeStatusResponse, error) {
+	return &kvstorepb.GetNodeStatusResponse{Status: s.node.Status()}, nil
+}
+
+// GetClusterStatus returns the status of every member known to the node.
+// Members that cannot be reached are listed with the error.
+func (s *AdminServer) GetClusterStatus(ctx context.Context, req *kvstorepb.GetClusterStatusRequest) (*kvstorepb.GetClusterStatusResponse, error) {
+	return s.node.ClusterStatus(ctx), nil
+}
+
+// Compact removes expired values and old tombstones from the node's store.
+func (s *AdminServer) Compact(ctx context.Context, req *kvstorepb.CompactRequest) (*kvstorepb.CompactResponse, error) {
+	if req.TombstoneGraceMs < 0 {
+		return nil, status.Error(codes.InvalidArgument, "tombstone grace must not be negative")
+	}
+	st := s.node.Compact(time.Duration(req.TombstoneGraceMs) * time.Millisecond)
+	return &kvstorepb.CompactResponse{
+		NodeId:     s.node.nodeID,
+		Expired:    int64(st.Expired),
+		Tombstones: int64(st.Tombstones),
+	}, nil
+}
+
+// Repair reads the keys the node stores at consistency ALL, repairing their
+// replicas, and returns once every key was read.
+func (s *AdminServer) Repair(ctx context.Context, req *kvstorepb.RepairRequest) (*kvstorepb.RepairResponse, error) {
+	checked, failed, err := s.node.Repair(ctx, req.Namespace, req.Prefix)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+		return nil, err
+	}
+	resp := &kvstorepb.RepairResponse{
+		NodeId:      s.node.nodeID,
+		Checked:     int64(checked),
+		Failed:      failed,
+		FailedCount: int64(len(failed)),
+	}
+	if len(failed) > maxRepairFailures {
+		resp.Failed = failed[:maxRepairFailures]
+	}
+	return resp, nil
+}
+
+// Snapshot writes the node's store to a file in its snapshot directory.
+func (s *AdminServer) Snapshot(ctx context.Context, req *kvstorepb.SnapshotRequest) (*kvstorepb.SnapshotResponse, error) {
+	path, records, size, err := s.node.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &kvstorepb.SnapshotResponse{
+		NodeId:  s.node.nodeID,
+		Path:    path,
+		Records: int64(records),
+		Bytes:   size,
+	}, nil
+}
+
+// Decommission removes the node from the cluster and hands its keys to their
+// new replicas, and returns once every key was handed off.
+func (s *AdminServer) Decommission(ctx context.Context, req *kvstorepb.DecommissionRequest) (*kvstorepb.DecommissionResponse, error) {
+	handedOff, failed, err := s.node.Decommission(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+		return nil, err
+	}
+	resp := &kvstorepb.DecommissionResponse{
+		NodeId:      s.node.nodeID,
+		HandedOff:   int64(handedOff),
+		Failed:      failed,
+		FailedCount: int64(len(failed)),
+	}
+	if len(failed) > maxRepairFailures {
+		resp.Failed = failed[:maxRepairFailures]
+	}
+	return resp, nil
+}
+
+func runtimeConfigToProto(cfg RuntimeConfig, version uint64, rf int) *kvstorepb.RuntimeConfig {
+	return &kvstorepb.RuntimeConfig{
+		Version:                     version,
+		ReplicationFactor:           int32(rf),
+		DefaultR:                    int32(cfg.R),
+		DefaultW:                    int32(cfg.W),
+		Coordinator:                 cfg.Coordinator.String(),
+		ReplicaTimeoutMode:          cfg.Timeouts.Mode.String(),
+		ReplicaTimeoutMs:            cfg.Timeouts.Fixed.Milliseconds(),
+		ReplicaTimeoutMinMs:         cfg.Timeouts.Min.Milliseconds(),
+		ReplicaTimeoutMaxMs:         cfg.Timeouts.Max.Milliseconds(),
+		ReplicaTimeoutPercentile:    cfg.Timeouts.Percentile,
+		GossipProbeIntervalMs:       cfg.Gossip.ProbeInterval.Milliseconds(),
+		GossipSuspectTimeoutMs:      cfg.Gossip.SuspectTimeout.Milliseconds(),
+		GossipDeadTimeoutMs:         cfg.Gossip.DeadTimeout.Milliseconds(),
+		RateLimitRequests:           cfg.RateLimits.Default.Requests,
+		RateLimitRequestBurst:       int32(cfg.RateLimits.Default.RequestBurst),
+		RateLimitBytes:              cfg.RateLimits.Default.Bytes,
+		RateLimitByteBurst:          int32(cfg.RateLimits.Default.ByteBurst),
+		RateLimitOverrides:          int32(len(cfg.RateLimits.Clients)),
+		AdmissionMaxRequests:        int32(cfg.Admission.Coordinator.MaxInFlight),
+		AdmissionMaxReplicaRequests: int32(cfg.Admission.Replica.MaxInFlight),
+		AdmissionTargetDelayMs:      cfg.Admission.Coordinator.TargetDelay.Milliseconds(),
+		LogLevels:                   cfg.Logging.String(),
+		ClockPruneMinEntries:        int32(cfg.ClockPrune.MinEntries),
+		ClockPruneMaxEntries:        int32(cfg.ClockPrune.MaxEntries),
+		ClockPruneMinAgeMs:          cfg.ClockPrune.MinAge.Milliseconds(),
+		ClockPruneMaxAgeMs:          cfg.ClockPrune.MaxAge.Milliseconds(),
+	}
+}
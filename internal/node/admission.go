@@ -0,0 +1,86 @@
+package node
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"kvstore/internal/admission"
+	kvstorepb "kvstore/internal/gen/api"
+)
+
+// AdmissionConfig bounds the work a node accepts.
+type AdmissionConfig struct {
+	Coordinator admission.Config // Client requests, read repair and anti-entropy started by this node
+	Replica     admission.Config // Replica RPCs from coordinators
+}
+
+// Validate checks both sets of limits.
+func (c AdmissionConfig) Validate() error {
+	if err := c.Coordinator.Validate(); err != nil {
+		return err
+	}
+	return c.Replica.Validate()
+}
+
+// SetAdmission sets the admission limits. Must be called before Start; use
+// Reload afterwards.
+func (n *Node) SetAdmission(cfg AdmissionConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	n.runtime.Admission = cfg
+	n.coordinatorAdmission.SetConfig(cfg.Coordinator)
+	n.replicaAdmission.SetConfig(cfg.Replica)
+	return nil
+}
+
+// AdmissionStats returns the in-flight, admitted and shed operations of
+// client requests and of replica RPCs.
+func (n *Node) AdmissionStats() (coordinator, replica admission.Stats) {
+	return n.coordinatorAdmission.Stats(), n.replicaAdmission.Stats()
+}
+
+// admit holds a slot of the matching admission controller for the duration
+// of data and replica requests. Gossip and admin requests are never shed, so
+// that an overloaded node is not also declared dead.
+func (n *Node) admit(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	controller, priority, ok := n.admissionFor(info.FullMethod, req)
+	if !ok {
+		return handler(ctx, req)
+	}
+	release, err := controller.Acquire(ctx, priority)
+	if err != nil {
+		if errors.Is(err, admission.ErrOverloaded) {
+			return nil, status.Errorf(codes.Unavailable, "node %s is overloaded: %v", n.nodeID, err)
+		}
+		return nil, status.FromContextError(err).Err()
+	}
+	defer release()
+	return handler(ctx, req)
+}
+
+// admissionFor returns the controller and priority of a method, or false if
+// it is not subject to admission control.
+func (n *Node) admissionFor(fullMethod string, req any) (*admission.Controller, admission.Priority, bool) {
+	switch fullMethod {
+	case "/kvstore.KVStore/Get", "/kvstore.KVStore/Scan":
+		return n.coordinatorAdmission, admission.Read, true
+	case "/kvstore.KVStore/Put", "/kvstore.KVStore/Delete":
+		return n.coordinatorAdmission, admission.Write, true
+	case "/kvstore.KVInternal/ReplicaGet":
+		return n.replicaAdmission, admission.Read, true
+	case "/kvstore.KVInternal/ReplicaPut":
+		if r, ok := req.(*kvstorepb.ReplicaPutRequest); ok && r.IsRepair {
+			return n.replicaAdmission, admission.Repair, true
+		}
+		return n.replicaAdmission, admission.Write, true
+	case "/kvstore.KVInternal/ReplicaDelete":
+		return n.replicaAdmission, admission.Write, true
+	case "/kvstore.KVInternal/SyncNamespaces":
+		return n.replicaAdmission, admission.AntiEntropy, true
+	}
+	return nil, 0, false
+}
@@ -0,0 +1,61 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"kvstore/internal/admission"
+	kvstorepb "kvstore/internal/gen/api"
+)
+
+func TestNode_Admit(t *testing.T) {
+	cfg := admission.Config{MaxInFlight: 1, MaxQueue: 1, TargetDelay: time.Millisecond, MaxWait: 10 * time.Millisecond}
+	n := &Node{
+		nodeID:               "n1",
+		coordinatorAdmission: admission.NewController(cfg),
+		replicaAdmission:     admission.NewController(cfg),
+	}
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	call := func(method string, req any) codes.Code {
+		_, err := n.admit(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return status.Code(err)
+	}
+
+	if code := call("/kvstore.KVStore/Get", &kvstorepb.GetRequest{Key: "k"}); code != codes.OK {
+		t.Fatalf("Expected request on an idle node to pass, got %v", code)
+	}
+
+	// Fill the coordinator's only slot
+	release, err := n.coordinatorAdmission.Acquire(context.Background(), admission.Read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	if code := call("/kvstore.KVStore/Put", &kvstorepb.PutRequest{Key: "k"}); code != codes.Unavailable {
+		t.Errorf("Expected client request on a full node to be shed, got %v", code)
+	}
+
+	// Replica RPCs have their own limit, and gossip and admin are never shed
+	tests := []struct {
+		method string
+		req    any
+	}{
+		{"/kvstore.KVInternal/ReplicaGet", &kvstorepb.ReplicaGetRequest{Key: "k"}},
+		{"/kvstore.Membership/Ping", &kvstorepb.PingRequest{}},
+		{"/kvstore.Admin/GetConfig", &kvstorepb.GetConfigRequest{}},
+	}
+	for _, tt := range tests {
+		if code := call(tt.method, tt.req); code != codes.OK {
+			t.Errorf("%s: expected OK, got %v", tt.method, code)
+		}
+	}
+
+	coordinator, replica := n.AdmissionStats()
+	if coordinator.Shed[admission.Write] != 1 || replica.Admitted[admission.Read] != 1 {
+		t.Errorf("Unexpected stats: coordinator %+v, replica %+v", coordinator, replica)
+	}
+}
@@ -0,0 +1,122 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"kvstore/internal/auth"
+	kvstorepb "kvstore/internal/gen/api"
+)
+
+// Metadata key carrying the principal of a forwarded request
+const principalMetadataKey = "x-principal"
+
+// SetAuthPolicy enables authentication and authorization of client requests
+// with policy, or disables them if policy is nil. It is safe to call while
+// serving requests; requires TLS (see SetTLS) to take effect.
+func (n *Node) SetAuthPolicy(policy *auth.Policy) {
+	n.authPolicy.Store(policy)
+}
+
+// authorize authenticates client requests and checks them against the ACL.
+// Node-only methods are authenticated by requireNodeCert instead. Requests
+// forwarded by another node were authorized by the coordinator that
+// received them and run as the principal it forwards.
+func (n *Node) authorize(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	policy := n.authPolicy.Load()
+	if policy == nil || nodeOnly(info.FullMethod) {
+		return handler(ctx, req)
+	}
+
+	if isForwarded(ctx) {
+		if _, err := n.peerNodeCert(ctx); err == nil {
+			return handler(auth.NewContext(ctx, forwardedPrincipal(ctx)), req)
+		}
+	}
+
+	principal, err := policy.Authenticate(requestCredentials(ctx))
+	if err != nil {
+		if errors.Is(err, auth.ErrNoCredentials) {
+			return nil, status.Error(codes.Unauthenticated, "authentication required")
+		}
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if perm, ns, key, ok := requiredPermission(info.FullMethod, req); ok && !policy.ACL.Allowed(principal.Name, ns, key, perm) {
+		if ns != "" {
+			return nil, status.Errorf(codes.PermissionDenied, "%s may not %s %q in namespace %s", principal, perm, key, ns)
+		}
+		return nil, status.Errorf(codes.PermissionDenied, "%s may not %s %q", principal, perm, key)
+	}
+	return handler(auth.NewContext(ctx, principal), req)
+}
+
+// requiredPermission returns the permission, namespace and key a method
+// needs. Methods without one (membership queries, reflection) only need
+// authentication.
+func requiredPermission(fullMethod string, req any) (auth.Permission, string, string, bool) {
+	if strings.HasPrefix(fullMethod, "/kvstore.Admin/") {
+		return auth.Admin, "", "", true
+	}
+	switch r := req.(type) {
+	case *kvstorepb.PutRequest:
+		return auth.Write, r.Namespace, r.Key, true
+	case *kvstorepb.GetRequest:
+		return auth.Read, r.Namespace, r.Key, true
+	case *kvstorepb.DeleteRequest:
+		return auth.Delete, r.Namespace, r.Key, true
+	case *kvstorepb.ScanRequest:
+		return auth.Read, r.Namespace, r.Prefix, true
+	}
+	return 0, "", "", false
+}
+
+// requestCredentials extracts the bearer token and verified TLS client
+// certificate of a request.
+func requestCredentials(ctx context.Context) auth.Credentials {
+	var creds auth.Credentials
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, v := range md.Get("authorization") {
+			if token, ok := strings.CutPrefix(v, "Bearer "); ok {
+				creds.Token = token
+				break
+			}
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) > 0 {
+			creds.Cert = info.State.VerifiedChains[0][0]
+		}
+	}
+	return creds
+}
+
+// forwardedPrincipal returns the principal a forwarding node sent.
+func forwardedPrincipal(ctx context.Context) auth.Principal {
+	p := auth.Principal{Method: "node"}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(principalMetadataKey); len(v) > 0 {
+			p.Name = v[0]
+		}
+	}
+	return p
+}
+
+// principalName returns the name of the principal a request authenticated
+// as, or "" without authentication. It scopes client-chosen request IDs.
+func principalName(ctx context.Context) string {
+	p, _ := auth.FromContext(ctx)
+	return p.Name
+}
+
+// principalOf returns the principal of a request for logging.
+func principalOf(ctx context.Context) string {
+	p, _ := auth.FromContext(ctx)
+	return p.String()
+}
@@ -0,0 +1,89 @@
+package node
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"kvstore/internal/auth"
+	kvstorepb "kvstore/internal/gen/api"
+)
+
+func TestNode_Authorize(t *testing.T) {
+	policy, err := auth.ParsePolicy([]byte(`
+tokens:
+  - {principal: alice, token: alice-secret}
+acl:
+  - {principal: alice, prefix: "user:alice/", allow: [read, write]}
+  - {principal: alice, namespace: orders, allow: [read]}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := &Node{}
+
+	var principal auth.Principal
+	handler := func(ctx context.Context, req any) (any, error) {
+		principal, _ = auth.FromContext(ctx)
+		return "ok", nil
+	}
+	call := func(token, method string, req any) codes.Code {
+		ctx := context.Background()
+		if token != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+token))
+		}
+		_, err := n.authorize(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return status.Code(err)
+	}
+
+	// Without a policy every request is allowed
+	if code := call("", "/kvstore.KVStore/Put", &kvstorepb.PutRequest{Key: "k"}); code != codes.OK {
+		t.Errorf("Expected OK without a policy, got %v", code)
+	}
+
+	n.SetAuthPolicy(policy)
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		req    any
+		want   codes.Code
+	}{
+		{"allowed put", "alice-secret", "/kvstore.KVStore/Put", &kvstorepb.PutRequest{Key: "user:alice/a"}, codes.OK},
+		{"put outside prefix", "alice-secret", "/kvstore.KVStore/Put", &kvstorepb.PutRequest{Key: "user:bob/a"}, codes.PermissionDenied},
+		{"delete not granted", "alice-secret", "/kvstore.KVStore/Delete", &kvstorepb.DeleteRequest{Key: "user:alice/a"}, codes.PermissionDenied},
+		{"scan of granted prefix", "alice-secret", "/kvstore.KVStore/Scan", &kvstorepb.ScanRequest{Prefix: "user:alice/"}, codes.OK},
+		{"scan of all keys", "alice-secret", "/kvstore.KVStore/Scan", &kvstorepb.ScanRequest{}, codes.PermissionDenied},
+		{"read in granted namespace", "alice-secret", "/kvstore.KVStore/Get", &kvstorepb.GetRequest{Namespace: "orders", Key: "o1"}, codes.OK},
+		{"write in read-only namespace", "alice-secret", "/kvstore.KVStore/Put", &kvstorepb.PutRequest{Namespace: "orders", Key: "o1"}, codes.PermissionDenied},
+		{"prefix rule in other namespace", "alice-secret", "/kvstore.KVStore/Put", &kvstorepb.PutRequest{Namespace: "carts", Key: "user:alice/a"}, codes.PermissionDenied},
+		{"admin not granted", "alice-secret", "/kvstore.Admin/ReloadConfig", &kvstorepb.ReloadConfigRequest{}, codes.PermissionDenied},
+		{"membership query", "alice-secret", "/kvstore.Membership/GetRing", &kvstorepb.GetRingRequest{}, codes.OK},
+		{"no token", "", "/kvstore.KVStore/Get", &kvstorepb.GetRequest{Key: "user:alice/a"}, codes.Unauthenticated},
+		{"wrong token", "guess", "/kvstore.KVStore/Get", &kvstorepb.GetRequest{Key: "user:alice/a"}, codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := call(tt.token, tt.method, tt.req); code != tt.want {
+				t.Errorf("authorize() = %v, want %v", code, tt.want)
+			}
+		})
+	}
+
+	// The handler sees the authenticated principal
+	principal = auth.Principal{}
+	call("alice-secret", "/kvstore.KVStore/Get", &kvstorepb.GetRequest{Key: "user:alice/a"})
+	if principal.Name != "alice" || principal.Method != "token" {
+		t.Errorf("Expected handler to run as alice, got %+v", principal)
+	}
+
+	// A forwarded marker from a client without a node certificate is ignored
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(forwardedMetadataKey, forwardedValue, principalMetadataKey, "alice"))
+	_, err = n.authorize(ctx, &kvstorepb.GetRequest{Key: "user:alice/a"}, &grpc.UnaryServerInfo{FullMethod: "/kvstore.KVStore/Get"}, handler)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected spoofed forwarded request to be unauthenticated, got %v", err)
+	}
+}
@@ -1,22 +1,19 @@
 package node
 
 import (
-	"context"
 	"fmt"
 	"sync"
-	"time"
 
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/tlsutil"
 )
 
 const (
 	// Metadata key for forwarded requests
 	forwardedMetadataKey = "x-forwarded"
 	forwardedValue       = "true"
-	// Connection timeout
-	dialTimeout = 5 * time.Second
 )
 
 // ClientManager manages gRPC clients to peer nodes.
@@ -25,6 +22,18 @@ type ClientManager struct {
 	clients           map[string]kvstorepb.KVStoreClient
 	internalClients   map[string]kvstorepb.KVInternalClient
 	membershipClients map[string]kvstorepb.MembershipClient
+
+	// Mutual TLS to peers (see tls.go); nil for insecure connections
+	certs  *tlsutil.Certs
+	peerID func(addr string) string
+
+	// Per-replica read latency, used to rank replicas and hedge reads (see latency.go)
+	latencyMu       sync.Mutex
+	latencies       map[string]*latencyHistogram
+	latencyErrors   map[string]uint64
+	hedgePercentile float64
+
+	tracer trace.TracerProvider // nil = not traced
 }
 
 // NewClientManager creates a new client manager.
@@ -33,11 +42,15 @@ func NewClientManager() *ClientManager {
 		clients:           make(map[string]kvstorepb.KVStoreClient),
 		internalClients:   make(map[string]kvstorepb.KVInternalClient),
 		membershipClients: make(map[string]kvstorepb.MembershipClient),
+		latencies:         make(map[string]*latencyHistogram),
+		latencyErrors:     make(map[string]uint64),
+		hedgePercentile:   DefaultHedgePercentile,
 	}
 }
 
-// GetClient returns a gRPC client for the given node address.
-// Creates a new connection if one doesn't exist.
+// GetClient returns a gRPC client for the given node address, used to
+// forward client requests. Creates a new connection if one doesn't exist;
+// the connection is established on first use.
 func (cm *ClientManager) GetClient(addr string) (kvstorepb.KVStoreClient, error) {
 	cm.mu.RLock()
 	client, exists := cm.clients[addr]
@@ -56,15 +69,11 @@ func (cm *ClientManager) GetClient(addr string) (kvstorepb.KVStoreClient, error)
 		return client, nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
-	defer cancel()
-
-	conn, err := grpc.DialContext(ctx, addr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithBlock(),
-	)
+	// Connect lazily: forwarding must not hold the lock, or the request,
+	// for a blocking dial to a replica that may be down
+	conn, err := grpc.NewClient(addr, cm.dialOptions(addr)...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to dial %s: %w", addr, err)
+		return nil, fmt.Errorf("failed to create client for %s: %w", addr, err)
 	}
 
 	client = kvstorepb.NewKVStoreClient(conn)
@@ -91,15 +100,11 @@ func (cm *ClientManager) GetInternalClient(addr string) (kvstorepb.KVInternalCli
 		return client, nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
-	defer cancel()
-
-	conn, err := grpc.DialContext(ctx, addr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithBlock(),
-	)
+	// Connect lazily: a blocking dial to a peer that is down would hold the
+	// lock and stall requests to every other peer
+	conn, err := grpc.NewClient(addr, cm.dialOptions(addr)...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to dial %s: %w", addr, err)
+		return nil, fmt.Errorf("failed to create internal client for %s: %w", addr, err)
 	}
 
 	client = kvstorepb.NewKVInternalClient(conn)
@@ -126,15 +131,11 @@ func (cm *ClientManager) GetMembershipClient(addr string) (kvstorepb.MembershipC
 		return client, nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
-	defer cancel()
-
-	conn, err := grpc.DialContext(ctx, addr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithBlock(),
-	)
+	// Connect lazily: a blocking dial to a peer that is down would hold the
+	// lock and stall requests to every other peer
+	conn, err := grpc.NewClient(addr, cm.dialOptions(addr)...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to dial membership %s: %w", addr, err)
+		return nil, fmt.Errorf("failed to create membership client for %s: %w", addr, err)
 	}
 
 	client = kvstorepb.NewMembershipClient(conn)
@@ -0,0 +1,48 @@
+package node
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/quorum"
+)
+
+// requiredReplicas resolves the number of replica responses an operation
+// needs from either a named consistency level or a raw R/W value (falling
+// back to def), validated against the replication factor rf and the
+// available replicas. Errors are gRPC status errors: InvalidArgument for
+// bad requests and Unavailable when the cluster cannot satisfy the level.
+func requiredReplicas(level kvstorepb.ConsistencyLevel, raw int32, def, rf, available int) (int, error) {
+	if level != kvstorepb.ConsistencyLevel_CONSISTENCY_LEVEL_UNSPECIFIED {
+		if raw > 0 {
+			return 0, status.Error(codes.InvalidArgument,
+				"set either a consistency level or a raw consistency_r/consistency_w, not both")
+		}
+		ql := protoToLevel(level)
+		if ql == quorum.LevelUnspecified {
+			return 0, status.Error(codes.InvalidArgument, fmt.Sprintf("unknown consistency level %v", level))
+		}
+		// Without zone-aware placement all rf replicas are local
+		required, err := quorum.Required(ql, rf, available, rf)
+		if err != nil {
+			return 0, status.Error(codes.Unavailable, err.Error())
+		}
+		return required, nil
+	}
+
+	required := int(raw)
+	if required <= 0 {
+		required = def
+	}
+	if required > rf {
+		return 0, status.Error(codes.InvalidArgument,
+			fmt.Sprintf("requested %d replicas exceeds replication factor N=%d", required, rf))
+	}
+	if required > available {
+		return 0, status.Error(codes.Unavailable,
+			fmt.Sprintf("requested %d replicas, only %d available", required, available))
+	}
+	return required, nil
+}
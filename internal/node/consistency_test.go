@@ -0,0 +1,25 @@
+package node
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	kvstorepb "kvstore/internal/gen/api"
+)
+
+func TestRequiredReplicas(t *testing.T) {
+	// LOCAL_QUORUM is a majority of N, like QUORUM, however many replicas are up
+	if got, err := requiredReplicas(kvstorepb.ConsistencyLevel_LOCAL_QUORUM, 0, 2, 3, 2); err != nil || got != 2 {
+		t.Errorf("LOCAL_QUORUM with N=3 and 2 available = %d, %v; want 2", got, err)
+	}
+	if _, err := requiredReplicas(kvstorepb.ConsistencyLevel_LOCAL_QUORUM, 0, 2, 3, 1); status.Code(err) != codes.Unavailable {
+		t.Errorf("LOCAL_QUORUM with N=3 and 1 available = %v, want Unavailable", err)
+	}
+	if _, err := requiredReplicas(kvstorepb.ConsistencyLevel(42), 0, 2, 3, 3); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Unknown level = %v, want InvalidArgument", err)
+	}
+	if _, err := requiredReplicas(kvstorepb.ConsistencyLevel_ONE, 1, 2, 3, 3); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Level with a raw size = %v, want InvalidArgument", err)
+	}
+}
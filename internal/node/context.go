@@ -0,0 +1,43 @@
+package node
+
+import (
+	"errors"
+
+	"kvstore/internal/clock"
+	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/logging"
+	"kvstore/internal/repair"
+)
+
+// errRawVersion is returned for writes that carry a raw vector clock instead
+// of a causal context. Raw clocks are rejected because a client could
+// fabricate one that dominates versions it has never seen.
+var errRawVersion = errors.New("raw version is not accepted; pass the context returned by Get")
+
+// writeContext returns the vector clock a write should descend from: the
+// clock carried by the client's causal context, or an empty clock for a
+// blind write.
+func (s *Server) writeContext(key, token string, rawVersion *kvstorepb.VectorClock) (clock.VectorClock, error) {
+	if token == "" {
+		if rawVersion != nil && len(rawVersion.Entries) > 0 {
+			return nil, errRawVersion
+		}
+		return clock.New(), nil
+	}
+	return s.signer.Verify(key, token)
+}
+
+// issueContext returns a causal context covering every given version of key.
+// Returns an empty context (and logs) if the versions cannot be encoded.
+func (s *Server) issueContext(key string, values []repair.VersionedValue) string {
+	merged := clock.New()
+	for _, v := range values {
+		merged.Merge(v.Version)
+	}
+	token, err := s.signer.Sign(key, merged)
+	if err != nil {
+		s.log.Warn("Failed to issue causal context", logging.Key(key), logging.Err(err))
+		return ""
+	}
+	return token
+}
@@ -1,8 +1,14 @@
 package node
 
 import (
+	"sort"
+	"time"
+
 	"kvstore/internal/clock"
+	"kvstore/internal/conflict"
 	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/namespace"
+	"kvstore/internal/quorum"
 )
 
 // protoToVectorClock converts a protobuf VectorClock to internal clock.VectorClock.
@@ -18,18 +24,162 @@ func protoToVectorClock(pb *kvstorepb.VectorClock) clock.VectorClock {
 }
 
 // vectorClockToProto converts an internal clock.VectorClock to protobuf VectorClock.
+// Entries are sorted by node ID so the output is deterministic.
 func vectorClockToProto(vc clock.VectorClock) *kvstorepb.VectorClock {
 	if vc == nil || len(vc) == 0 {
 		return &kvstorepb.VectorClock{Entries: []*kvstorepb.VectorClockEntry{}}
 	}
+	nodeIDs := make([]string, 0, len(vc))
+	for nodeID := range vc {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Strings(nodeIDs)
+
 	pb := &kvstorepb.VectorClock{
 		Entries: make([]*kvstorepb.VectorClockEntry, 0, len(vc)),
 	}
-	for nodeID, counter := range vc {
+	for _, nodeID := range nodeIDs {
 		pb.Entries = append(pb.Entries, &kvstorepb.VectorClockEntry{
 			NodeId:  nodeID,
-			Counter: counter,
+			Counter: vc[nodeID],
 		})
 	}
 	return pb
 }
+
+// encodeVersion returns the compact binary encoding of vc used by internal RPCs.
+func encodeVersion(vc clock.VectorClock) ([]byte, error) {
+	if vc == nil {
+		return nil, nil
+	}
+	return clock.Encode(vc, nil)
+}
+
+// decodeVersion returns the version carried by an internal RPC, preferring
+// the compact binary encoding over the protobuf VectorClock (sent by older
+// nodes). Returns nil if neither is set.
+func decodeVersion(encoded []byte, pb *kvstorepb.VectorClock) (clock.VectorClock, error) {
+	if len(encoded) == 0 {
+		return protoToVectorClock(pb), nil
+	}
+	vc, _, err := clock.Decode(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return vc, nil
+}
+
+// protoToTimestamp converts a protobuf HLCTimestamp to internal clock.Timestamp.
+func protoToTimestamp(pb *kvstorepb.HLCTimestamp) clock.Timestamp {
+	if pb == nil {
+		return clock.Timestamp{}
+	}
+	return clock.Timestamp{
+		WallTime: pb.WallTimeMs,
+		Logical:  pb.Logical,
+		NodeID:   pb.NodeId,
+	}
+}
+
+// timestampToProto converts an internal clock.Timestamp to protobuf HLCTimestamp.
+func timestampToProto(ts clock.Timestamp) *kvstorepb.HLCTimestamp {
+	return &kvstorepb.HLCTimestamp{
+		WallTimeMs: ts.WallTime,
+		Logical:    ts.Logical,
+		NodeId:     ts.NodeID,
+	}
+}
+
+// protoToExpiry converts a protobuf expiration time in Unix milliseconds to
+// an expiration time (nil if 0, i.e. never).
+func protoToExpiry(ms int64) *time.Time {
+	if ms == 0 {
+		return nil
+	}
+	t := time.UnixMilli(ms)
+	return &t
+}
+
+// expiryToProto converts an expiration time to Unix milliseconds (0 if nil).
+func expiryToProto(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+// protoToLevel converts a protobuf ConsistencyLevel to quorum.Level.
+func protoToLevel(pb kvstorepb.ConsistencyLevel) quorum.Level {
+	switch pb {
+	case kvstorepb.ConsistencyLevel_ONE:
+		return quorum.One
+	case kvstorepb.ConsistencyLevel_QUORUM:
+		return quorum.Quorum
+	case kvstorepb.ConsistencyLevel_ALL:
+		return quorum.All
+	case kvstorepb.ConsistencyLevel_LOCAL_QUORUM:
+		return quorum.LocalQuorum
+	default:
+		return quorum.LevelUnspecified
+	}
+}
+
+// namespaceToProto converts a namespace to protobuf.
+func namespaceToProto(ns namespace.Namespace) *kvstorepb.Namespace {
+	return &kvstorepb.Namespace{
+		Name:              ns.Name,
+		ReplicationFactor: int32(ns.ReplicationFactor),
+		DefaultR:          int32(ns.R),
+		DefaultW:          int32(ns.W),
+		DefaultTtlMs:      ns.DefaultTTL.Milliseconds(),
+		ConflictPolicy:    ns.ConflictPolicy.String(),
+		MaxKeys:           ns.MaxKeys,
+		MaxValueBytes:     ns.MaxValueBytes,
+		MaxBytes:          ns.MaxBytes,
+		Deleted:           ns.Deleted,
+		UpdatedAt:         timestampToProto(ns.UpdatedAt),
+	}
+}
+
+// protoToNamespace converts a protobuf namespace, rejecting an unknown
+// conflict policy.
+func protoToNamespace(pb *kvstorepb.Namespace) (namespace.Namespace, error) {
+	policy, err := conflict.ParsePolicy(pb.ConflictPolicy)
+	if err != nil {
+		return namespace.Namespace{}, err
+	}
+	return namespace.Namespace{
+		Name:              pb.Name,
+		ReplicationFactor: int(pb.ReplicationFactor),
+		R:                 int(pb.DefaultR),
+		W:                 int(pb.DefaultW),
+		DefaultTTL:        time.Duration(pb.DefaultTtlMs) * time.Millisecond,
+		ConflictPolicy:    policy,
+		MaxKeys:           pb.MaxKeys,
+		MaxValueBytes:     pb.MaxValueBytes,
+		MaxBytes:          pb.MaxBytes,
+		Deleted:           pb.Deleted,
+		UpdatedAt:         protoToTimestamp(pb.UpdatedAt),
+	}, nil
+}
+
+// namespacesToProto converts namespaces to protobuf.
+func namespacesToProto(list []namespace.Namespace) []*kvstorepb.Namespace {
+	pbs := make([]*kvstorepb.Namespace, 0, len(list))
+	for _, ns := range list {
+		pbs = append(pbs, namespaceToProto(ns))
+	}
+	return pbs
+}
+
+// protoToNamespaces converts namespaces received from another node, skipping
+// definitions this node cannot represent.
+func protoToNamespaces(pbs []*kvstorepb.Namespace) []namespace.Namespace {
+	list := make([]namespace.Namespace, 0, len(pbs))
+	for _, pb := range pbs {
+		if ns, err := protoToNamespace(pb); err == nil {
+			list = append(list, ns)
+		}
+	}
+	return list
+}
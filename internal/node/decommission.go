@@ -0,0 +1,118 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"kvstore/internal/gossip"
+	"kvstore/internal/logging"
+	"kvstore/internal/repair"
+	"kvstore/internal/replication"
+	"kvstore/internal/ring"
+	"kvstore/internal/storage"
+)
+
+// Decommission removes the node from the cluster. It leaves gossip
+// membership, so that the other members drop it from their rings, and hands
+// every value and tombstone it stores to the replicas that own the key once
+// it is gone. It returns the number of keys handed off and a "key: error"
+// entry for each key that could not be. The node keeps serving until it is
+// stopped. Errors are gRPC status errors.
+func (n *Node) Decommission(ctx context.Context) (handedOff int, failed []string, err error) {
+	if n.membership == nil {
+		return 0, nil, status.Error(codes.FailedPrecondition, "decommission requires gossip membership: a static ring is changed by editing it")
+	}
+	n.runtimeMu.Lock()
+	server := n.server
+	n.runtimeMu.Unlock()
+	if server == nil {
+		return 0, nil, status.Error(codes.FailedPrecondition, "node is not serving")
+	}
+
+	n.membership.Leave()
+	n.announce(ctx)
+
+	// The ring without this node, as the remaining members will build it
+	n.ringMu.RLock()
+	rng := ring.NewRing(n.ring.GetVNodes())
+	n.ringMu.RUnlock()
+	rng.SetNodes(n.membership.AliveNodes())
+
+	names := []string{""}
+	for _, ns := range n.namespaces.List() {
+		names = append(names, ns.Name)
+	}
+	for _, name := range names {
+		ns, err := server.resolveNamespace(name)
+		if err != nil {
+			return handedOff, failed, err
+		}
+		start, startAfter := storage.NamespacedKey(name, ""), ""
+		for {
+			keys, more := n.store.KeysWithTombstones(start, startAfter, repairPageSize)
+			for _, stored := range keys {
+				if err := ctx.Err(); err != nil {
+					return handedOff, failed, err
+				}
+				if err := n.handOff(ctx, server.readRepairer, rng, stored, ns.ReplicationFactor); err != nil {
+					_, key := storage.SplitKey(stored)
+					failed = append(failed, fmt.Sprintf("%s: %v", key, err))
+					continue
+				}
+				handedOff++
+			}
+			if !more {
+				break
+			}
+			startAfter = keys[len(keys)-1]
+		}
+	}
+	n.log.Info("Decommission finished", slog.Int("handed_off", handedOff), slog.Int("failed", len(failed)))
+	return handedOff, failed, nil
+}
+
+// announce pushes the membership, in which this node has left, to every
+// other member instead of waiting for gossip to spread it.
+func (n *Node) announce(ctx context.Context) {
+	members := n.membership.Snapshot()
+	for _, m := range members {
+		if m.ID == n.nodeID || m.Status == gossip.Dead || m.Status == gossip.Left {
+			continue
+		}
+		gctx, cancel := context.WithTimeout(ctx, n.membership.ProbeInterval())
+		if err := n.gossipFn(gctx, m.Addr, members); err != nil {
+			n.log.Warn("Failed to announce decommission", slog.String("member", m.ID), logging.Err(err))
+		}
+		cancel()
+	}
+}
+
+// handOff writes the local version of a stored key to its replicas in rng.
+func (n *Node) handOff(ctx context.Context, w *repair.ReadRepairer, rng *ring.Ring, stored string, rf int) error {
+	vv := n.store.Get(stored)
+	if vv == nil {
+		return nil // Expired or compacted since it was listed
+	}
+	replicas := replication.GetReplicasForKey(rng, stored, rf)
+	if len(replicas) == 0 {
+		return errors.New("no replicas available")
+	}
+	version := repair.VersionedValue{
+		Value:     vv.Value,
+		Version:   vv.Version,
+		Timestamp: vv.Timestamp,
+		Deleted:   vv.Deleted,
+		ExpiresAt: vv.ExpiresAt,
+	}
+	var errs []error
+	for _, r := range replicas {
+		if err := w.Write(ctx, r.Addr, stored, version); err != nil {
+			errs = append(errs, fmt.Errorf("replica %s: %w", r.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,84 @@
+package node
+
+import (
+	"fmt"
+
+	"kvstore/internal/clock"
+	"kvstore/internal/dedup"
+	kvstorepb "kvstore/internal/gen/api"
+)
+
+// writeRecord is a coordinator's record of a client write, keyed by
+// client_id+request_id. A retry reuses the recorded version, so replicas
+// that already applied the write recognize it, and a retry of a completed
+// write returns the recorded response.
+type writeRecord struct {
+	key     string
+	deleted bool
+	version clock.VectorClock
+	ts      clock.Timestamp
+	put     *kvstorepb.PutResponse    // Set once a Put succeeded
+	del     *kvstorepb.DeleteResponse // Set once a Delete succeeded
+}
+
+// prepareWrite returns the record for a write: the recorded one if the
+// request is a retry, otherwise a new record with a fresh version that
+// descends from the client's causal context. The record is reserved
+// atomically, so concurrent retries of one request share a version.
+func (s *Server) prepareWrite(dedupKey, key, token string, rawVersion *kvstorepb.VectorClock, deleted bool) (*writeRecord, error) {
+	if prior, ok := s.writes.Get(dedupKey); ok {
+		return prior.check(key, deleted)
+	}
+
+	// Start from the client's causal context (versions seen by a previous
+	// Get) so the new write dominates every version it resolves
+	version, err := s.writeContext(key, token, rawVersion)
+	if err != nil {
+		return nil, err
+	}
+	// Continue from this node's counter in the stored version, so that a
+	// write without a context never reuses a version already stored, then
+	// increment coordinator's counter to create new version
+	if local := s.store.Get(key); local != nil && local.Version.Get(s.nodeID) > version.Get(s.nodeID) {
+		version.Set(s.nodeID, local.Version.Get(s.nodeID))
+	}
+	version.Increment(s.nodeID)
+
+	rec := &writeRecord{
+		key:     key,
+		deleted: deleted,
+		version: version,
+		ts:      s.hlc.Now(),
+	}
+	// A concurrent retry may have reserved the request since the lookup
+	if prior, loaded := s.writes.GetOrPut(dedupKey, rec); loaded {
+		return prior.check(key, deleted)
+	}
+	return rec, nil
+}
+
+// check returns the record if a retry with the given key and operation
+// matches it, or an error if its request_id was reused for another write.
+func (rec *writeRecord) check(key string, deleted bool) (*writeRecord, error) {
+	if rec.key != key || rec.deleted != deleted {
+		return nil, fmt.Errorf("request_id was already used for a different request")
+	}
+	return rec, nil
+}
+
+// applyOnce runs apply unless a write with the same replica dedup key was
+// already applied on this node. Keys are shared between the coordinator's
+// local writes and ReplicaPut, so each client write is applied at most once
+// per replica. The key is reserved before apply runs, with a channel closed
+// once it returns; a duplicate that arrives meanwhile waits for it, so it is
+// only acknowledged once the write is in the store.
+func applyOnce(applied *dedup.Table[chan struct{}], dedupKey string, apply func()) (duplicate bool) {
+	done := make(chan struct{})
+	if inFlight, loaded := applied.GetOrPut(dedupKey, done); loaded {
+		<-inFlight
+		return true
+	}
+	defer close(done)
+	apply()
+	return false
+}
@@ -0,0 +1,68 @@
+package node
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kvstore/internal/dedup"
+	"kvstore/internal/ring"
+	"kvstore/internal/storage"
+)
+
+func TestPrepareWrite_ConcurrentRetriesShareRecord(t *testing.T) {
+	self := ring.Node{ID: "n1", Addr: "localhost:50051"}
+	s := NewServer(storage.NewInMemoryStore("n1"), "n1", ring.NewRing(16), nil, self, NewClientManager(), nil, nil, nil, 3, 2, 2)
+	dedupKey := dedup.Key("", "c1", "r1")
+
+	records := make([]*writeRecord, 20)
+	var wg sync.WaitGroup
+	for i := range records {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec, err := s.prepareWrite(dedupKey, "k", "", nil, false)
+			if err != nil {
+				t.Error(err)
+			}
+			records[i] = rec
+		}()
+	}
+	wg.Wait()
+	for _, rec := range records[1:] {
+		if rec != records[0] {
+			t.Fatal("Expected concurrent retries to share one write record")
+		}
+	}
+
+	if _, err := s.prepareWrite(dedupKey, "k", "", nil, true); err == nil {
+		t.Error("Expected error for a request_id reused by a delete")
+	}
+}
+
+func TestApplyOnce_DuplicateWaitsForApply(t *testing.T) {
+	applied := dedup.NewTable[chan struct{}](10, time.Minute)
+	started, release := make(chan struct{}), make(chan struct{})
+	var stored atomic.Bool
+	go applyOnce(applied, "k", func() {
+		close(started)
+		<-release
+		stored.Store(true)
+	})
+	<-started
+
+	result := make(chan bool)
+	go func() {
+		result <- applyOnce(applied, "k", func() { t.Error("Expected the duplicate not to be applied") })
+	}()
+	select {
+	case <-result:
+		t.Fatal("Expected the duplicate to wait for the write in flight")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	if duplicate := <-result; !duplicate || !stored.Load() {
+		t.Errorf("applyOnce() = %v after apply stored %v; want a duplicate after the write", duplicate, stored.Load())
+	}
+}
@@ -0,0 +1,178 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+
+	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/logging"
+	"kvstore/internal/quorum"
+	"kvstore/internal/repair"
+	"kvstore/internal/ring"
+)
+
+// valueDigest returns the digest of a value returned by digest reads.
+func valueDigest(value []byte) []byte {
+	sum := sha256.Sum256(value)
+	return sum[:]
+}
+
+// localFirst ranks the local replica ahead of the others, so that a read
+// always contacts it.
+type localFirst struct {
+	quorum.LatencyTracker
+	self string
+}
+
+// Rank implements quorum.LatencyTracker.
+func (l localFirst) Rank(replicas []string) []string {
+	ranked := make([]string, 0, len(replicas))
+	for _, addr := range l.LatencyTracker.Rank(replicas) {
+		if addr == l.self {
+			ranked = append([]string{addr}, ranked...)
+		} else {
+			ranked = append(ranked, addr)
+		}
+	}
+	return ranked
+}
+
+// readReplica reads key from a single replica, locally if it is this node.
+// If digest is true, a remote replica returns only version metadata and a
+// value digest. The version is returned as a replicaVersion.
+func (s *Server) readReplica(ctx context.Context, key, requestID string, replica ring.Node, digest bool) ([]byte, interface{}, bool, error) {
+	// If replica is self, read locally
+	if replica.ID == s.selfNode.ID {
+		span := startStoreSpan(ctx, s.tracer, "Get", key)
+		vv := s.store.Get(key)
+		span.End()
+		if vv == nil {
+			return nil, nil, false, quorum.ErrNotFound
+		}
+		version := replicaVersion{clock: vv.Version, timestamp: vv.Timestamp, expiresAt: vv.ExpiresAt, addr: replica.Addr, full: true}
+		return vv.Value, version, vv.Deleted, nil
+	}
+
+	// Otherwise, call internal RPC
+	client, err := s.clientMgr.GetInternalClient(replica.Addr)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to get internal client: %w", err)
+	}
+
+	resp, err := client.ReplicaGet(ctx, &kvstorepb.ReplicaGetRequest{
+		Key:           key,
+		CoordinatorId: s.nodeID,
+		RequestId:     requestID,
+		Digest:        digest,
+	})
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	if resp.Status == kvstorepb.ReplicaGetResponse_NOT_FOUND {
+		return nil, nil, false, quorum.ErrNotFound
+	}
+
+	if resp.Status != kvstorepb.ReplicaGetResponse_SUCCESS {
+		return nil, nil, false, fmt.Errorf("replica error: %s", resp.ErrorMessage)
+	}
+
+	// Advance local clock past the replica's timestamp, rejecting clock skew
+	ts := protoToTimestamp(resp.Value.Timestamp)
+	if _, err := s.hlc.Update(ts); err != nil {
+		return nil, nil, false, fmt.Errorf("rejected replica timestamp: %w", err)
+	}
+
+	vc, err := decodeVersion(resp.EncodedVersion, resp.Value.Version)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("invalid replica version: %w", err)
+	}
+
+	version := replicaVersion{
+		clock:     vc,
+		timestamp: ts,
+		expiresAt: protoToExpiry(resp.Value.ExpiresAtMs),
+		addr:      replica.Addr,
+		full:      !digest,
+	}
+	if digest {
+		version.digest = resp.ValueDigest
+	}
+	return resp.Value.Value, version, resp.Value.Deleted, nil
+}
+
+// fillWinnerValues sets the value of every live winner from a full read of
+// the same version. Winners only seen through digest reads (their version
+// did not match the full read) are read again in full from a replica that
+// returned that version. addrToID maps replica addresses to node IDs.
+func (s *Server) fillWinnerValues(ctx context.Context, key, requestID string, winners []repair.VersionedValue, reads []quorum.ReadValue, addrToID map[string]string) error {
+	for i := range winners {
+		winner := &winners[i]
+		if winner.Deleted {
+			continue
+		}
+
+		// Replicas holding the winning version
+		var (
+			digestAddrs []string
+			digests     [][]byte
+			filled      bool
+		)
+		for _, rv := range reads {
+			rver, ok := rv.Version.(replicaVersion)
+			if !ok || !rver.clock.Equal(winner.Version) {
+				continue
+			}
+			if rver.full {
+				if !filled {
+					winner.Value = rv.Value
+					filled = true
+				}
+				continue
+			}
+			digestAddrs = append(digestAddrs, rver.addr)
+			digests = append(digests, rver.digest)
+		}
+		if filled {
+			// Equal versions must carry equal values
+			if !digestsMatch(winner.Value, digests) {
+				s.log.Warn("Replicas disagree on the value of a version", logging.Key(key), slog.String("version", winner.Version.String()))
+			}
+			continue
+		}
+
+		// Digest mismatch: fall back to a full read
+		for _, addr := range digestAddrs {
+			value, version, _, err := s.readReplica(ctx, key, requestID, ring.Node{ID: addrToID[addr], Addr: addr}, false)
+			if err != nil {
+				s.log.Warn("Full read after digest mismatch failed", logging.Key(key), slog.String("replica", addr), logging.Err(err))
+				continue
+			}
+			// The replica may have been written to since the digest read
+			if rver := version.(replicaVersion); !rver.clock.Equal(winner.Version) {
+				continue
+			}
+			winner.Value = value
+			filled = true
+			break
+		}
+		if !filled {
+			return fmt.Errorf("failed to read value for version %s of key %s", winner.Version, key)
+		}
+	}
+	return nil
+}
+
+// digestsMatch returns true if every digest read agrees with the value.
+func digestsMatch(value []byte, digests [][]byte) bool {
+	want := valueDigest(value)
+	for _, d := range digests {
+		if !bytes.Equal(d, want) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,21 @@
+package node
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLocalFirst_Rank(t *testing.T) {
+	cm := NewClientManager()
+	for i := 0; i < minLatencySamples; i++ {
+		cm.Observe("fast:1", time.Millisecond, nil)
+		cm.Observe("self:1", 10*time.Millisecond, nil)
+		cm.Observe("slow:1", 100*time.Millisecond, nil)
+	}
+
+	ranked := localFirst{LatencyTracker: cm, self: "self:1"}.Rank([]string{"slow:1", "self:1", "fast:1"})
+	if want := []string{"self:1", "fast:1", "slow:1"}; !reflect.DeepEqual(ranked, want) {
+		t.Errorf("Rank() = %v, want %v", ranked, want)
+	}
+}
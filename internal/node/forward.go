@@ -0,0 +1,111 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"kvstore/internal/auth"
+	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/logging"
+	"kvstore/internal/ring"
+)
+
+// CoordinatorPolicy selects which node coordinates a client request.
+type CoordinatorPolicy int
+
+const (
+	// CoordinateAny lets any node that receives a request coordinate it.
+	CoordinateAny CoordinatorPolicy = iota
+	// CoordinateReplica forwards requests received by a node outside the
+	// key's preference list to a replica, saving a hop on every replica RPC.
+	CoordinateReplica
+)
+
+// String returns the name of the policy.
+func (p CoordinatorPolicy) String() string {
+	switch p {
+	case CoordinateAny:
+		return "any"
+	case CoordinateReplica:
+		return "replica"
+	default:
+		return fmt.Sprintf("CoordinatorPolicy(%d)", int(p))
+	}
+}
+
+// ParseCoordinatorPolicy parses "any" or "replica" (case-insensitive).
+func ParseCoordinatorPolicy(s string) (CoordinatorPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "any":
+		return CoordinateAny, nil
+	case "replica":
+		return CoordinateReplica, nil
+	default:
+		return CoordinateAny, fmt.Errorf("unknown coordinator policy %q (expected any or replica)", s)
+	}
+}
+
+// isForwarded returns true if the request was forwarded by another node.
+func isForwarded(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+	for _, v := range md.Get(forwardedMetadataKey) {
+		if v == forwardedValue {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardTargets returns the alive replica addresses a request should be
+// forwarded to, fastest first, or nil if this node should coordinate it: the
+// policy allows any coordinator, this node is a replica, or the request was
+// already forwarded (loop protection). Replicas that gossip reports as
+// suspect or dead are skipped; if none is left, this node coordinates.
+func (s *Server) forwardTargets(ctx context.Context, replicas []ring.Node) []string {
+	if s.runtimeConfig().Coordinator != CoordinateReplica || isForwarded(ctx) {
+		return nil
+	}
+	addrs := make([]string, 0, len(replicas))
+	for _, r := range replicas {
+		if r.ID == s.selfNode.ID {
+			return nil
+		}
+		if s.isAlive != nil && !s.isAlive(r.ID) {
+			continue
+		}
+		addrs = append(addrs, r.Addr)
+	}
+	return s.clientMgr.Rank(addrs)
+}
+
+// forward sends a client request to the first target a client can be
+// created for, marked as forwarded so the target coordinates it itself.
+// Returns forwarded=false if there is none, in which case the caller
+// coordinates locally. Clients connect lazily, so the request is bounded by
+// its own deadline rather than by a blocking dial. Errors returned by the
+// target, including UNAVAILABLE if it cannot be reached, are passed through
+// and not retried elsewhere, since the request may already have been applied.
+func forward[Resp any](ctx context.Context, s *Server, key string, targets []string, call func(context.Context, kvstorepb.KVStoreClient) (Resp, error)) (resp Resp, forwarded bool, err error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, forwardedMetadataKey, forwardedValue)
+	if p, ok := auth.FromContext(ctx); ok {
+		ctx = metadata.AppendToOutgoingContext(ctx, principalMetadataKey, p.Name)
+	}
+	for _, addr := range targets {
+		client, dialErr := s.clientMgr.GetClient(addr)
+		if dialErr != nil {
+			s.log.Warn("Forwarding failed", logging.Key(key), slog.String("replica", addr), logging.Err(dialErr))
+			continue
+		}
+		s.log.Debug("Forwarding to replica", logging.Key(key), slog.String("replica", addr))
+		resp, err = call(ctx, client)
+		return resp, true, err
+	}
+	s.log.Warn("No replica reachable, coordinating locally", logging.Key(key))
+	return resp, false, nil
+}
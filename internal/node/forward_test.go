@@ -0,0 +1,67 @@
+package node
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+	"kvstore/internal/ring"
+)
+
+func TestForwardTargets(t *testing.T) {
+	s := &Server{
+		clientMgr: NewClientManager(),
+		selfNode:  ring.Node{ID: "n4", Addr: "localhost:50054"},
+	}
+	s.SetCoordinatorPolicy(CoordinateReplica)
+	replicas := []ring.Node{
+		{ID: "n1", Addr: "localhost:50051"},
+		{ID: "n2", Addr: "localhost:50052"},
+		{ID: "n3", Addr: "localhost:50053"},
+	}
+	ctx := context.Background()
+
+	if targets := s.forwardTargets(ctx, replicas); len(targets) != 3 {
+		t.Errorf("Expected non-replica to forward to all 3 replicas, got %v", targets)
+	}
+
+	// A replica coordinates its own keys
+	if targets := s.forwardTargets(ctx, append(replicas[:2:2], s.selfNode)); targets != nil {
+		t.Errorf("Expected replica to coordinate locally, got %v", targets)
+	}
+
+	// Replicas that are not alive are skipped; with none left, coordinate locally
+	s.SetLiveness(func(nodeID string) bool { return nodeID != "n2" })
+	if targets := s.forwardTargets(ctx, replicas); len(targets) != 2 || targets[0] == "localhost:50052" || targets[1] == "localhost:50052" {
+		t.Errorf("Expected forwarding to the 2 alive replicas, got %v", targets)
+	}
+	s.SetLiveness(func(string) bool { return false })
+	if targets := s.forwardTargets(ctx, replicas); len(targets) != 0 {
+		t.Errorf("Expected no targets without alive replicas, got %v", targets)
+	}
+	s.SetLiveness(nil)
+
+	// Loop protection: a forwarded request is never forwarded again
+	forwardedCtx := metadata.NewIncomingContext(ctx, metadata.Pairs(forwardedMetadataKey, forwardedValue))
+	if targets := s.forwardTargets(forwardedCtx, replicas); targets != nil {
+		t.Errorf("Expected forwarded request to be coordinated locally, got %v", targets)
+	}
+
+	// Default policy: any node coordinates
+	s.SetCoordinatorPolicy(CoordinateAny)
+	if targets := s.forwardTargets(ctx, replicas); targets != nil {
+		t.Errorf("Expected no forwarding under the any policy, got %v", targets)
+	}
+}
+
+func TestParseCoordinatorPolicy(t *testing.T) {
+	for _, p := range []CoordinatorPolicy{CoordinateAny, CoordinateReplica} {
+		got, err := ParseCoordinatorPolicy(p.String())
+		if err != nil || got != p {
+			t.Errorf("ParseCoordinatorPolicy(%q) = %v, %v", p.String(), got, err)
+		}
+	}
+	if _, err := ParseCoordinatorPolicy("nearest"); err == nil {
+		t.Error("Expected error for unknown policy")
+	}
+}
@@ -2,31 +2,81 @@ package node
 
 import (
 	"context"
-	"log"
-
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"kvstore/internal/clock"
+	"kvstore/internal/dedup"
 	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/logging"
+	"kvstore/internal/namespace"
 	"kvstore/internal/storage"
+	"kvstore/internal/tracing"
 )
 
 // InternalServer implements the KVInternal gRPC service for replica operations.
 type InternalServer struct {
 	kvstorepb.UnimplementedKVInternalServer
-	store  storage.Store
-	nodeID string
+	store   storage.Store
+	nodeID  string
+	hlc     *clock.HLC                  // Hybrid logical clock shared with the coordinator
+	applied *dedup.Table[chan struct{}] // Writes applied to the local store
+
+	namespaces *namespace.Registry // Namespace quotas (nil = none)
+	tracer     trace.Tracer
+	log        *slog.Logger
+	status     func() *kvstorepb.NodeStatus // Answers GetNodeStatus (nil = unavailable)
 }
 
 // NewInternalServer creates a new internal server instance.
-func NewInternalServer(store storage.Store, nodeID string) *InternalServer {
+// If hlc is nil, a clock with the default max offset is created.
+func NewInternalServer(store storage.Store, nodeID string, hlc *clock.HLC) *InternalServer {
+	if hlc == nil {
+		hlc = clock.NewHLC(nodeID, clock.DefaultMaxOffset)
+	}
 	return &InternalServer{
-		store:  store,
-		nodeID: nodeID,
+		store:   store,
+		nodeID:  nodeID,
+		hlc:     hlc,
+		applied: dedup.NewTable[chan struct{}](dedup.DefaultCapacity, dedup.DefaultTTL),
+		tracer:  tracing.Tracer(nil),
+		log:     logging.For(logging.Replica).With(logging.NodeID(nodeID)),
 	}
 }
 
+// SetTracer traces the replica's store operations. Must be called before the
+// server starts serving.
+func (s *InternalServer) SetTracer(tp trace.TracerProvider) {
+	s.tracer = tracing.Tracer(tp)
+}
+
+// SetStatus sets the function reporting the node's status to GetNodeStatus.
+// Must be called before the server starts serving.
+func (s *InternalServer) SetStatus(fn func() *kvstorepb.NodeStatus) {
+	s.status = fn
+}
+
+// GetNodeStatus returns the status of the node, for the cluster status of
+// another node.
+func (s *InternalServer) GetNodeStatus(ctx context.Context, req *kvstorepb.GetNodeStatusRequest) (*kvstorepb.GetNodeStatusResponse, error) {
+	if s.status == nil {
+		return nil, status.Error(codes.Unimplemented, "node status is not available")
+	}
+	return &kvstorepb.GetNodeStatusResponse{Status: s.status()}, nil
+}
+
+// SetAppliedTable sets the table of writes applied to the local store
+// (see Server.SetAppliedTable).
+func (s *InternalServer) SetAppliedTable(applied *dedup.Table[chan struct{}]) {
+	s.applied = applied
+}
+
 // ReplicaPut handles internal Put requests from coordinator to replica.
 func (s *InternalServer) ReplicaPut(ctx context.Context, req *kvstorepb.ReplicaPutRequest) (*kvstorepb.ReplicaPutResponse, error) {
-	log.Printf("[%s] ReplicaPut: key=%s, coordinator=%s, request_id=%s",
-		s.nodeID, req.Key, req.CoordinatorId, req.RequestId)
+	s.logReplica(ctx, "ReplicaPut", req.Key, req.CoordinatorId, req.ClientId, req.RequestId)
 
 	if req.Key == "" {
 		return &kvstorepb.ReplicaPutResponse{
@@ -35,15 +85,33 @@ func (s *InternalServer) ReplicaPut(ctx context.Context, req *kvstorepb.ReplicaP
 		}, nil
 	}
 
-	// Convert protobuf version to internal version
-	version := protoToVectorClock(req.Version)
+	// Convert wire version to internal version
+	version, err := decodeVersion(req.EncodedVersion, req.Version)
+	if err != nil {
+		return &kvstorepb.ReplicaPutResponse{
+			Status:       kvstorepb.ReplicaPutResponse_ERROR,
+			ErrorMessage: "invalid version: " + err.Error(),
+		}, nil
+	}
+
+	// Advance local clock past the coordinator's timestamp, rejecting clock skew
+	ts := protoToTimestamp(req.Timestamp)
+	if _, err := s.hlc.Update(ts); err != nil {
+		return &kvstorepb.ReplicaPutResponse{
+			Status:       kvstorepb.ReplicaPutResponse_ERROR,
+			ErrorMessage: err.Error(),
+		}, nil
+	}
 
 	// If this is a repair operation, do NOT increment clock
 	// Just overwrite with the provided version
 	if req.IsRepair {
 		// For repair: overwrite with exact version (no increment)
 		// Storage should accept if incoming version dominates or is equal
-		err := s.store.PutRepair(req.Key, req.Value, version, req.Deleted)
+		span := startStoreSpan(ctx, s.tracer, "PutRepair", req.Key)
+		err := s.store.PutRepair(req.Key, req.Value, version, ts, req.Deleted, protoToExpiry(req.ExpiresAtMs))
+		tracing.SetError(span, err)
+		span.End()
 		if err != nil {
 			return &kvstorepb.ReplicaPutResponse{
 				Status:       kvstorepb.ReplicaPutResponse_ERROR,
@@ -55,11 +123,26 @@ func (s *InternalServer) ReplicaPut(ctx context.Context, req *kvstorepb.ReplicaP
 		}, nil
 	}
 
-	// Normal operation: store and increment
-	newVersion := s.store.Put(req.Key, req.Value, version, req.Deleted)
+	// Enforce the storage quotas of the namespace on this node's store
+	if err := s.admitReplicaWrite(req.Key, req.Value, req.Deleted); err != nil {
+		return &kvstorepb.ReplicaPutResponse{
+			Status:       kvstorepb.ReplicaPutResponse_ERROR,
+			ErrorMessage: status.Convert(err).Message(),
+		}, nil
+	}
 
-	// Verify version was updated
-	_ = newVersion
+	// Normal operation: store the coordinator's version, at most once per
+	// client request
+	span := startStoreSpan(ctx, s.tracer, "Put", req.Key)
+	duplicate := applyOnce(s.applied, dedup.Key(req.Principal, req.ClientId, req.RequestId, req.Key), func() {
+		s.store.PutVersion(req.Key, req.Value, version, ts, req.Deleted, protoToExpiry(req.ExpiresAtMs))
+	})
+	span.SetAttributes(attribute.Bool("kvstore.duplicate", duplicate))
+	span.End()
+	if duplicate {
+		s.log.Debug("ReplicaPut duplicate, already applied",
+			logging.RequestID(req.RequestId), logging.ClientID(req.ClientId), logging.Key(req.Key))
+	}
 
 	return &kvstorepb.ReplicaPutResponse{
 		Status: kvstorepb.ReplicaPutResponse_SUCCESS,
@@ -68,8 +151,7 @@ func (s *InternalServer) ReplicaPut(ctx context.Context, req *kvstorepb.ReplicaP
 
 // ReplicaGet handles internal Get requests from coordinator to replica.
 func (s *InternalServer) ReplicaGet(ctx context.Context, req *kvstorepb.ReplicaGetRequest) (*kvstorepb.ReplicaGetResponse, error) {
-	log.Printf("[%s] ReplicaGet: key=%s, coordinator=%s, request_id=%s",
-		s.nodeID, req.Key, req.CoordinatorId, req.RequestId)
+	s.logReplica(ctx, "ReplicaGet", req.Key, req.CoordinatorId, "", req.RequestId)
 
 	if req.Key == "" {
 		return &kvstorepb.ReplicaGetResponse{
@@ -78,27 +160,46 @@ func (s *InternalServer) ReplicaGet(ctx context.Context, req *kvstorepb.ReplicaG
 		}, nil
 	}
 
+	span := startStoreSpan(ctx, s.tracer, "Get", req.Key)
 	vv := s.store.Get(req.Key)
+	span.End()
 	if vv == nil {
 		return &kvstorepb.ReplicaGetResponse{
 			Status: kvstorepb.ReplicaGetResponse_NOT_FOUND,
 		}, nil
 	}
 
-	return &kvstorepb.ReplicaGetResponse{
+	encoded, err := encodeVersion(vv.Version)
+	if err != nil {
+		return &kvstorepb.ReplicaGetResponse{
+			Status:       kvstorepb.ReplicaGetResponse_ERROR,
+			ErrorMessage: err.Error(),
+		}, nil
+	}
+
+	resp := &kvstorepb.ReplicaGetResponse{
 		Status: kvstorepb.ReplicaGetResponse_SUCCESS,
 		Value: &kvstorepb.VersionedValue{
-			Value:   vv.Value,
-			Version: vectorClockToProto(vv.Version),
-			Deleted: vv.Deleted,
+			Value: vv.Value,
+			// Older nodes only read the VectorClock message
+			Version:     vectorClockToProto(vv.Version),
+			Deleted:     vv.Deleted,
+			Timestamp:   timestampToProto(vv.Timestamp),
+			ExpiresAtMs: expiryToProto(vv.ExpiresAt),
 		},
-	}, nil
+		EncodedVersion: encoded,
+	}
+	if req.Digest {
+		// Version metadata only: the coordinator fetches the value from one replica
+		resp.Value.Value = nil
+		resp.ValueDigest = valueDigest(vv.Value)
+	}
+	return resp, nil
 }
 
 // ReplicaDelete handles internal Delete requests from coordinator to replica.
 func (s *InternalServer) ReplicaDelete(ctx context.Context, req *kvstorepb.ReplicaDeleteRequest) (*kvstorepb.ReplicaDeleteResponse, error) {
-	log.Printf("[%s] ReplicaDelete: key=%s, coordinator=%s, request_id=%s",
-		s.nodeID, req.Key, req.CoordinatorId, req.RequestId)
+	s.logReplica(ctx, "ReplicaDelete", req.Key, req.CoordinatorId, "", req.RequestId)
 
 	if req.Key == "" {
 		return &kvstorepb.ReplicaDeleteResponse{
@@ -107,14 +208,27 @@ func (s *InternalServer) ReplicaDelete(ctx context.Context, req *kvstorepb.Repli
 		}, nil
 	}
 
-	// Convert protobuf version to internal version
-	version := protoToVectorClock(req.Version)
+	// Convert wire version to internal version
+	version, err := decodeVersion(req.EncodedVersion, req.Version)
+	if err != nil {
+		return &kvstorepb.ReplicaDeleteResponse{
+			Status:       kvstorepb.ReplicaDeleteResponse_ERROR,
+			ErrorMessage: "invalid version: " + err.Error(),
+		}, nil
+	}
 
-	// Delete the key (stores tombstone)
-	newVersion := s.store.Delete(req.Key, version)
+	ts := protoToTimestamp(req.Timestamp)
+	if _, err := s.hlc.Update(ts); err != nil {
+		return &kvstorepb.ReplicaDeleteResponse{
+			Status:       kvstorepb.ReplicaDeleteResponse_ERROR,
+			ErrorMessage: err.Error(),
+		}, nil
+	}
 
-	// Verify version was updated
-	_ = newVersion
+	// Delete the key (stores tombstone)
+	span := startStoreSpan(ctx, s.tracer, "Delete", req.Key)
+	s.store.PutVersion(req.Key, nil, version, ts, true, nil)
+	span.End()
 
 	return &kvstorepb.ReplicaDeleteResponse{
 		Status: kvstorepb.ReplicaDeleteResponse_SUCCESS,
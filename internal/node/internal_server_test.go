@@ -0,0 +1,182 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kvstore/internal/clock"
+	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/storage"
+)
+
+func TestReplicaGet_DigestMode(t *testing.T) {
+	store := storage.NewInMemoryStore("n1")
+	value := bytes.Repeat([]byte("x"), 1<<20)
+	store.Put("k", value, clock.VectorClock{"n1": 1}, clock.Timestamp{WallTime: 1}, false)
+	s := NewInternalServer(store, "n1", nil)
+
+	full, err := s.ReplicaGet(context.Background(), &kvstorepb.ReplicaGetRequest{Key: "k"})
+	if err != nil || full.Status != kvstorepb.ReplicaGetResponse_SUCCESS {
+		t.Fatalf("Full read failed: %v %v", err, full.GetErrorMessage())
+	}
+	if !bytes.Equal(full.Value.Value, value) || len(full.ValueDigest) != 0 {
+		t.Error("Expected full read to return the value without a digest")
+	}
+	// Older coordinators only read the VectorClock message
+	encoded, _ := decodeVersion(full.EncodedVersion, nil)
+	if vc := protoToVectorClock(full.Value.Version); len(vc) == 0 || !vc.Equal(encoded) {
+		t.Errorf("Expected the VectorClock message %v alongside the encoded version, got %v", encoded, vc)
+	}
+
+	digest, err := s.ReplicaGet(context.Background(), &kvstorepb.ReplicaGetRequest{Key: "k", Digest: true})
+	if err != nil || digest.Status != kvstorepb.ReplicaGetResponse_SUCCESS {
+		t.Fatalf("Digest read failed: %v %v", err, digest.GetErrorMessage())
+	}
+	if len(digest.Value.Value) != 0 {
+		t.Errorf("Expected digest read to omit the value, got %d bytes", len(digest.Value.Value))
+	}
+	if !bytes.Equal(digest.ValueDigest, valueDigest(value)) {
+		t.Error("Expected digest of the stored value")
+	}
+	if !bytes.Equal(digest.EncodedVersion, full.EncodedVersion) {
+		t.Error("Expected digest read to carry the same version as a full read")
+	}
+}
+
+func TestReplicaPut_StoresExpiry(t *testing.T) {
+	store := storage.NewInMemoryStore("n1")
+	s := NewInternalServer(store, "n1", nil)
+	encoded, err := encodeVersion(clock.VectorClock{"n2": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiresAt := time.Now().Add(time.Hour).UnixMilli()
+	req := &kvstorepb.ReplicaPutRequest{Key: "k", Value: []byte("v"), EncodedVersion: encoded, RequestId: "r1", ExpiresAtMs: expiresAt}
+	if resp, err := s.ReplicaPut(context.Background(), req); err != nil || resp.Status != kvstorepb.ReplicaPutResponse_SUCCESS {
+		t.Fatalf("ReplicaPut failed: %v %v", err, resp.GetErrorMessage())
+	}
+
+	resp, err := s.ReplicaGet(context.Background(), &kvstorepb.ReplicaGetRequest{Key: "k"})
+	if err != nil || resp.Status != kvstorepb.ReplicaGetResponse_SUCCESS {
+		t.Fatalf("ReplicaGet failed: %v %v", err, resp.GetErrorMessage())
+	}
+	if resp.Value.ExpiresAtMs != expiresAt {
+		t.Errorf("Expected expiry %d, got %d", expiresAt, resp.Value.ExpiresAtMs)
+	}
+
+	// A repair carrying a past expiry leaves the key expired
+	req = &kvstorepb.ReplicaPutRequest{Key: "k", Value: []byte("v"), EncodedVersion: encoded, RequestId: "r2", IsRepair: true, ExpiresAtMs: 1}
+	if resp, err := s.ReplicaPut(context.Background(), req); err != nil || resp.Status != kvstorepb.ReplicaPutResponse_SUCCESS {
+		t.Fatalf("Repair failed: %v %v", err, resp.GetErrorMessage())
+	}
+	if resp, _ := s.ReplicaGet(context.Background(), &kvstorepb.ReplicaGetRequest{Key: "k"}); resp.Status != kvstorepb.ReplicaGetResponse_NOT_FOUND {
+		t.Errorf("Expected expired key to read as not found, got %v", resp.Status)
+	}
+}
+
+func TestDigestsMatch(t *testing.T) {
+	v := []byte("value")
+	if !digestsMatch(v, [][]byte{valueDigest(v), valueDigest(v)}) {
+		t.Error("Expected matching digests")
+	}
+	if digestsMatch(v, [][]byte{valueDigest([]byte("other"))}) {
+		t.Error("Expected mismatch for a different value")
+	}
+	if !digestsMatch(v, nil) {
+		t.Error("Expected no digests to match trivially")
+	}
+}
+
+// countingStore counts the writes applied to a store.
+type countingStore struct {
+	*storage.InMemoryStore
+	puts atomic.Int32
+}
+
+func (s *countingStore) PutVersion(key string, value []byte, version clock.VectorClock, ts clock.Timestamp, deleted bool, expiresAt *time.Time) clock.VectorClock {
+	s.puts.Add(1)
+	return s.InMemoryStore.PutVersion(key, value, version, ts, deleted, expiresAt)
+}
+
+func TestReplicaPut_DuplicateRequestAppliedOnce(t *testing.T) {
+	store := &countingStore{InMemoryStore: storage.NewInMemoryStore("n1")}
+	s := NewInternalServer(store, "n1", nil)
+	encoded, err := encodeVersion(clock.VectorClock{"n2": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &kvstorepb.ReplicaPutRequest{
+		Key:            "k",
+		Value:          []byte("v"),
+		EncodedVersion: encoded,
+		CoordinatorId:  "n2",
+		ClientId:       "c1",
+		RequestId:      "r1",
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := s.ReplicaPut(context.Background(), req)
+		if err != nil || resp.Status != kvstorepb.ReplicaPutResponse_SUCCESS {
+			t.Fatalf("ReplicaPut %d failed: %v %v", i, err, resp.GetErrorMessage())
+		}
+	}
+
+	stored := store.Get("k")
+	if stored == nil {
+		t.Fatal("Expected key to be stored")
+	}
+	if got := store.puts.Load(); got != 1 || !stored.Version.Equal(clock.VectorClock{"n2": 1}) {
+		t.Errorf("Expected retried write to be applied once with the coordinator's version, got %d writes at %v", got, stored.Version)
+	}
+}
+
+func TestReplicaPut_ConcurrentDuplicatesAppliedOnce(t *testing.T) {
+	store := &countingStore{InMemoryStore: storage.NewInMemoryStore("n1")}
+	s := NewInternalServer(store, "n1", nil)
+	encoded, err := encodeVersion(clock.VectorClock{"n2": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &kvstorepb.ReplicaPutRequest{
+		Key:            "k",
+		Value:          []byte("v"),
+		EncodedVersion: encoded,
+		CoordinatorId:  "n2",
+		ClientId:       "c1",
+		RequestId:      "r1",
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.ReplicaPut(context.Background(), req)
+		}()
+	}
+	wg.Wait()
+
+	if got := store.puts.Load(); got != 1 {
+		t.Errorf("Expected concurrent duplicates to be applied once, got %d writes", got)
+	}
+}
+
+func TestReplicaPut_DedupScopedByPrincipal(t *testing.T) {
+	store := &countingStore{InMemoryStore: storage.NewInMemoryStore("n1")}
+	s := NewInternalServer(store, "n1", nil)
+
+	// Two principals that chose the same client and request IDs
+	for i, principal := range []string{"alice", "bob"} {
+		req := &kvstorepb.ReplicaPutRequest{Key: "k", Value: []byte(principal), CoordinatorId: "n2", ClientId: "c1", RequestId: "r1", Principal: principal}
+		if resp, err := s.ReplicaPut(context.Background(), req); err != nil || resp.Status != kvstorepb.ReplicaPutResponse_SUCCESS {
+			t.Fatalf("ReplicaPut %d failed: %v %v", i, err, resp.GetErrorMessage())
+		}
+	}
+	if got := store.Get("k"); string(got.Value) != "bob" || store.puts.Load() != 2 {
+		t.Errorf("Expected both principals' writes to be applied, got %q after %d writes", got.Value, store.puts.Load())
+	}
+}
@@ -0,0 +1,193 @@
+package node
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"kvstore/internal/quorum"
+)
+
+const (
+	// DefaultHedgePercentile is the latency percentile after which a read is
+	// speculatively sent to an extra replica.
+	DefaultHedgePercentile = 0.95
+	// defaultHedgeDelay is used until a replica has enough latency samples.
+	defaultHedgeDelay = 50 * time.Millisecond
+	// minHedgeDelay bounds the hedge delay from below so that very fast
+	// replicas do not trigger a speculative read on every request.
+	minHedgeDelay = 2 * time.Millisecond
+	// minLatencySamples is the number of samples needed before a replica's
+	// histogram is trusted.
+	minLatencySamples = 20
+	// latencyDecayAt halves every bucket once a histogram holds this many
+	// samples, so old observations fade out.
+	latencyDecayAt = 1000
+)
+
+// latencyBucketBounds are the upper bounds of the latency histogram buckets:
+// 250µs doubling up to ~4s. The last bucket counts everything slower.
+var latencyBucketBounds = func() []time.Duration {
+	bounds := make([]time.Duration, 15)
+	for i := range bounds {
+		bounds[i] = 250 * time.Microsecond << i
+	}
+	return bounds
+}()
+
+// latencyHistogram is a decaying histogram of RPC latencies for one replica.
+// Not safe for concurrent use; guarded by ClientManager.latencyMu.
+type latencyHistogram struct {
+	buckets []uint64
+	count   uint64
+}
+
+func newLatencyHistogram() *latencyHistogram {
+	return &latencyHistogram{buckets: make([]uint64, len(latencyBucketBounds)+1)}
+}
+
+// observe records a sample.
+func (h *latencyHistogram) observe(d time.Duration) {
+	i := sort.Search(len(latencyBucketBounds), func(i int) bool { return d <= latencyBucketBounds[i] })
+	h.buckets[i]++
+	h.count++
+	if h.count >= latencyDecayAt {
+		h.count = 0
+		for i := range h.buckets {
+			h.buckets[i] /= 2
+			h.count += h.buckets[i]
+		}
+	}
+}
+
+// percentile returns the upper bound of the bucket containing the p-th
+// percentile (0 < p <= 1).
+func (h *latencyHistogram) percentile(p float64) time.Duration {
+	target := uint64(p * float64(h.count))
+	if target == 0 {
+		target = 1
+	}
+	var cumulative uint64
+	for i, n := range h.buckets {
+		cumulative += n
+		if cumulative >= target {
+			if i < len(latencyBucketBounds) {
+				return latencyBucketBounds[i]
+			}
+			break
+		}
+	}
+	return quorum.DefaultPerReplicaTimeout
+}
+
+// ReplicaLatency summarizes the RPC latency observed for one replica.
+type ReplicaLatency struct {
+	Samples uint64
+	Errors  uint64
+	P50     time.Duration
+	P95     time.Duration
+	P99     time.Duration
+}
+
+// Rank implements quorum.LatencyTracker: replicas are ordered by median
+// latency. Replicas without enough samples come first so that they are
+// measured; ties keep the preference-list order.
+func (cm *ClientManager) Rank(replicas []string) []string {
+	cm.latencyMu.Lock()
+	defer cm.latencyMu.Unlock()
+
+	medians := make(map[string]time.Duration, len(replicas))
+	for _, addr := range replicas {
+		if h, ok := cm.latencies[addr]; ok && h.count >= minLatencySamples {
+			medians[addr] = h.percentile(0.5)
+		}
+	}
+
+	ranked := append([]string(nil), replicas...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return medians[ranked[i]] < medians[ranked[j]]
+	})
+	return ranked
+}
+
+// HedgeDelay implements quorum.LatencyTracker: the delay is the slowest
+// hedge-percentile latency among the contacted replicas.
+func (cm *ClientManager) HedgeDelay(contacted []string) time.Duration {
+	cm.latencyMu.Lock()
+	defer cm.latencyMu.Unlock()
+
+	var delay time.Duration
+	for _, addr := range contacted {
+		h, ok := cm.latencies[addr]
+		if !ok || h.count < minLatencySamples {
+			return defaultHedgeDelay
+		}
+		if p := h.percentile(cm.hedgePercentile); p > delay {
+			delay = p
+		}
+	}
+	if delay < minHedgeDelay {
+		delay = minHedgeDelay
+	}
+	return delay
+}
+
+// Observe implements quorum.LatencyTracker. Failed reads arrive with the
+// timeout applied to them as their latency, so failing replicas rank last.
+// A not-found read is an answer, not an error.
+func (cm *ClientManager) Observe(addr string, latency time.Duration, err error) {
+	cm.latencyMu.Lock()
+	defer cm.latencyMu.Unlock()
+
+	h, ok := cm.latencies[addr]
+	if !ok {
+		h = newLatencyHistogram()
+		cm.latencies[addr] = h
+	}
+	if err != nil && !errors.Is(err, quorum.ErrNotFound) {
+		cm.latencyErrors[addr]++
+	}
+	h.observe(latency)
+}
+
+// LatencyPercentile returns the p-th percentile RPC latency observed for a
+// replica. Returns false if the replica does not have enough samples yet.
+func (cm *ClientManager) LatencyPercentile(addr string, p float64) (time.Duration, bool) {
+	cm.latencyMu.Lock()
+	defer cm.latencyMu.Unlock()
+
+	h, ok := cm.latencies[addr]
+	if !ok || h.count < minLatencySamples {
+		return 0, false
+	}
+	return h.percentile(p), true
+}
+
+// SetHedgePercentile sets the latency percentile (0 < p < 1) after which reads
+// are hedged.
+func (cm *ClientManager) SetHedgePercentile(p float64) {
+	if p <= 0 || p >= 1 {
+		p = DefaultHedgePercentile
+	}
+	cm.latencyMu.Lock()
+	defer cm.latencyMu.Unlock()
+	cm.hedgePercentile = p
+}
+
+// Latencies returns a snapshot of the observed RPC latency per replica address.
+func (cm *ClientManager) Latencies() map[string]ReplicaLatency {
+	cm.latencyMu.Lock()
+	defer cm.latencyMu.Unlock()
+
+	out := make(map[string]ReplicaLatency, len(cm.latencies))
+	for addr, h := range cm.latencies {
+		out[addr] = ReplicaLatency{
+			Samples: h.count,
+			Errors:  cm.latencyErrors[addr],
+			P50:     h.percentile(0.5),
+			P95:     h.percentile(0.95),
+			P99:     h.percentile(0.99),
+		}
+	}
+	return out
+}
@@ -0,0 +1,25 @@
+package node
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"kvstore/internal/quorum"
+)
+
+func TestClientManager_ObserveNotFoundIsNotAnError(t *testing.T) {
+	cm := NewClientManager()
+	for i := 0; i < minLatencySamples; i++ {
+		cm.Observe("a:1", time.Millisecond, quorum.ErrNotFound)
+		cm.Observe("b:1", 500*time.Millisecond, errors.New("timeout"))
+	}
+
+	latencies := cm.Latencies()
+	if a := latencies["a:1"]; a.Errors != 0 || a.P50 > 2*time.Millisecond {
+		t.Errorf("Expected not-found reads recorded at their latency without errors, got %+v", a)
+	}
+	if b := latencies["b:1"]; b.Errors != minLatencySamples || b.P50 < 500*time.Millisecond || b.P50 >= quorum.DefaultPerReplicaTimeout {
+		t.Errorf("Expected failed reads recorded at their 500ms timeout, got %+v", b)
+	}
+}
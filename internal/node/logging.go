@@ -0,0 +1,42 @@
+package node
+
+import (
+	"context"
+	"log/slog"
+
+	"kvstore/internal/logging"
+	"kvstore/internal/quorum"
+)
+
+// Every request passes through the helpers below, so they build their fields
+// only when debug logs are enabled.
+
+// logRequest logs a client request received by the coordinator.
+func (s *Server) logRequest(ctx context.Context, method, namespace, key, clientID, requestID string) {
+	if !s.log.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	s.log.LogAttrs(ctx, slog.LevelDebug, method+" request",
+		logging.RequestID(requestID), logging.ClientID(clientID), logging.Key(key),
+		slog.String("namespace", namespace), slog.String("principal", principalOf(ctx)))
+}
+
+// logQuorum logs the outcome of a quorum operation.
+func (s *Server) logQuorum(ctx context.Context, method, key, requestID string, success bool, outcomes []quorum.ReplicaOutcome) {
+	if !s.log.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	s.log.LogAttrs(ctx, slog.LevelDebug, method+" quorum",
+		logging.RequestID(requestID), logging.Key(key),
+		slog.Bool("success", success), slog.String("outcomes", quorum.FormatOutcomes(outcomes)))
+}
+
+// logReplica logs a replica RPC received from a coordinator.
+func (s *InternalServer) logReplica(ctx context.Context, method, key, coordinator, clientID, requestID string) {
+	if !s.log.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	s.log.LogAttrs(ctx, slog.LevelDebug, method,
+		logging.RequestID(requestID), logging.ClientID(clientID), logging.Key(key),
+		slog.String("coordinator", coordinator))
+}
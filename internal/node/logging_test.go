@@ -0,0 +1,57 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"kvstore/internal/logging"
+	"kvstore/internal/quorum"
+)
+
+func TestServer_LogRequest(t *testing.T) {
+	var buf bytes.Buffer
+	logs, err := logging.New(&buf, logging.FormatJSON, logging.DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{log: logs.Logger(logging.Server).With(logging.NodeID("n1"))}
+	ctx := context.Background()
+
+	// Per-request logs are at debug level
+	s.logRequest(ctx, "Put", "default", "user:1", "c1", "r1")
+	s.logQuorum(ctx, "Put", "user:1", "r1", true, []quorum.ReplicaOutcome{{ReplicaID: "localhost:50051", Success: true}})
+	if buf.Len() != 0 {
+		t.Fatalf("Expected no request logs at info level, got %q", buf.String())
+	}
+
+	if err := logs.SetConfig(logging.Config{Level: slog.LevelInfo, Levels: map[string]slog.Level{logging.Server: slog.LevelDebug}}); err != nil {
+		t.Fatal(err)
+	}
+	s.logRequest(ctx, "Put", "default", "user:1", "c1", "r1")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected one JSON record, got %q: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"msg":        "Put request",
+		"subsystem":  logging.Server,
+		"node_id":    "n1",
+		"request_id": "r1",
+		"client_id":  "c1",
+		"key_hash":   logging.KeyHash("user:1"),
+		"namespace":  "default",
+	}
+	for field, value := range want {
+		if record[field] != value {
+			t.Errorf("Expected %s=%v, got %v", field, value, record[field])
+		}
+	}
+	if strings.Contains(buf.String(), "user:1") {
+		t.Errorf("Expected the key to be logged as a hash, got %q", buf.String())
+	}
+}
@@ -0,0 +1,208 @@
+package node
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"kvstore/internal/admission"
+	"kvstore/internal/clock"
+	"kvstore/internal/gossip"
+	"kvstore/internal/metrics"
+	"kvstore/internal/quorum"
+)
+
+// Metrics returns the registry of the node's metrics, for serving on a
+// metrics endpoint.
+func (n *Node) Metrics() *prometheus.Registry {
+	return n.metrics
+}
+
+// registerMetrics registers the request metrics recorded by the observe
+// interceptor and the metrics collected from the node's state on scrape.
+func (n *Node) registerMetrics() {
+	reg := n.metrics
+	n.rpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kvstore_rpc_requests_total",
+		Help: "gRPC requests handled, by method and status code.",
+	}, []string{"method", "code"})
+	n.rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kvstore_rpc_duration_seconds",
+		Help:    "Time to handle gRPC requests, by method and status code.",
+		Buckets: metrics.DefaultBuckets,
+	}, []string{"method", "code"})
+	reg.MustRegister(n.rpcRequests, n.rpcDuration)
+
+	reg.MustRegister(metrics.NewGaugeFunc("kvstore_members", "Cluster members known to this node, by gossip status.", []string{"status"}, func(emit metrics.Emit) {
+		if n.membership == nil {
+			emit(float64(n.ringSize()), strings.ToLower(gossip.Alive.String()))
+			return
+		}
+		counts := make(map[gossip.MemberStatus]int)
+		for _, m := range n.membership.Snapshot() {
+			counts[m.Status]++
+		}
+		for _, s := range []gossip.MemberStatus{gossip.Alive, gossip.Suspect, gossip.Dead} {
+			emit(float64(counts[s]), strings.ToLower(s.String()))
+		}
+	}))
+	reg.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "kvstore_ring_nodes",
+			Help: "Nodes in this node's view of the hash ring.",
+		}, func() float64 { return float64(n.ringSize()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "kvstore_store_keys",
+			Help: "Keys holding a value in the local store.",
+		}, func() float64 { return float64(n.store.Stats().Keys) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "kvstore_store_tombstones",
+			Help: "Deleted keys held in the local store.",
+		}, func() float64 { return float64(n.store.Stats().Tombstones) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "kvstore_store_bytes",
+			Help: "Size of the keys and values held in the local store.",
+		}, func() float64 { return float64(n.store.Stats().Bytes) }),
+	)
+
+	reg.MustRegister(metrics.NewCounterFunc("kvstore_read_repairs_total",
+		"Read repairs by result: triggered reads, skipped repairs, and repaired or failed replicas.",
+		[]string{"result"}, func(emit metrics.Emit) {
+			n.runtimeMu.Lock()
+			server := n.server
+			n.runtimeMu.Unlock()
+			if server == nil {
+				return
+			}
+			s := server.RepairStats()
+			emit(float64(s.Triggered), "triggered")
+			emit(float64(s.Skipped), "skipped")
+			emit(float64(s.Succeeded), "succeeded")
+			emit(float64(s.Failed), "failed")
+		}))
+
+	reg.MustRegister(metrics.NewCounterFunc("kvstore_rate_limit_requests_total", "Client requests checked against rate limits, by result.",
+		[]string{"result"}, func(emit metrics.Emit) {
+			s := n.limiter.Stats()
+			emit(float64(s.Allowed), "allowed")
+			emit(float64(s.ThrottledRequests), "throttled_requests")
+			emit(float64(s.ThrottledBytes), "throttled_bytes")
+		}))
+
+	reg.MustRegister(metrics.NewHistogramFunc("kvstore_clock_entries", "Entries in the vector clocks written by this node, after pruning.",
+		func() (uint64, float64, map[float64]uint64) {
+			s := n.ClockStats()
+			buckets := make(map[float64]uint64, len(clock.SizeBucketBounds))
+			var cumulative uint64
+			for i, bound := range clock.SizeBucketBounds {
+				cumulative += s.SizeBuckets[i]
+				buckets[float64(bound)] = cumulative
+			}
+			return s.Observed, float64(s.SizeSum), buckets
+		}))
+	reg.MustRegister(metrics.NewCounterFunc("kvstore_clock_pruned_total", "Vector clocks pruned on write and the entries removed from them.",
+		[]string{"unit"}, func(emit metrics.Emit) {
+			s := n.ClockStats()
+			emit(float64(s.PrunedClocks), "clocks")
+			emit(float64(s.PrunedEntries), "entries")
+		}))
+
+	admissionStats := func() map[string]admission.Stats {
+		coordinator, replica := n.AdmissionStats()
+		return map[string]admission.Stats{"coordinator": coordinator, "replica": replica}
+	}
+	reg.MustRegister(metrics.NewGaugeFunc("kvstore_admission_in_flight", "Operations holding an admission slot.", []string{"pool"}, func(emit metrics.Emit) {
+		for pool, s := range admissionStats() {
+			emit(float64(s.InFlight), pool)
+		}
+	}))
+	reg.MustRegister(metrics.NewGaugeFunc("kvstore_admission_queued", "Operations waiting for an admission slot.", []string{"pool"}, func(emit metrics.Emit) {
+		for pool, s := range admissionStats() {
+			emit(float64(s.Queued), pool)
+		}
+	}))
+	reg.MustRegister(metrics.NewCounterFunc("kvstore_admission_operations_total", "Operations admitted or shed, by priority.",
+		[]string{"pool", "priority", "result"}, func(emit metrics.Emit) {
+			for pool, s := range admissionStats() {
+				for p := range s.Admitted {
+					priority := admission.Priority(p).String()
+					emit(float64(s.Admitted[p]), pool, priority, "admitted")
+					emit(float64(s.Shed[p]), pool, priority, "shed")
+				}
+			}
+		}))
+}
+
+// ringSize returns the number of nodes in the ring.
+func (n *Node) ringSize() int {
+	n.ringMu.RLock()
+	defer n.ringMu.RUnlock()
+	return len(n.ring.GetNodes())
+}
+
+// observe counts each request and its latency by method and status code. It
+// runs first, so requests rejected by authentication, rate limits or
+// admission control are counted too.
+func (n *Node) observe(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	method := strings.TrimPrefix(info.FullMethod, "/")
+	code := status.Code(err).String()
+	n.rpcRequests.WithLabelValues(method, code).Inc()
+	n.rpcDuration.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+	return resp, err
+}
+
+// quorumMetrics records the results of quorum operations and of the replica
+// RPCs they make. A nil *quorumMetrics records nothing.
+type quorumMetrics struct {
+	operations      *prometheus.CounterVec
+	replicaOps      *prometheus.CounterVec
+	replicaDuration *prometheus.HistogramVec
+}
+
+func newQuorumMetrics(reg prometheus.Registerer) *quorumMetrics {
+	m := &quorumMetrics{
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "kvstore_quorum_operations_total",
+			Help: "Quorum operations coordinated by this node, by operation and result.",
+		}, []string{"op", "result"}),
+		replicaOps: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "kvstore_replica_operations_total",
+			Help: "Replica RPCs made by quorum operations, by operation, replica and outcome.",
+		}, []string{"op", "replica", "outcome"}),
+		replicaDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "kvstore_replica_rpc_duration_seconds",
+			Help:    "Latency of replica RPCs that completed, by operation and replica.",
+			Buckets: metrics.DefaultBuckets,
+		}, []string{"op", "replica"}),
+	}
+	reg.MustRegister(m.operations, m.replicaOps, m.replicaDuration)
+	return m
+}
+
+// observe records a quorum operation given its outcomes keyed by replica
+// address.
+func (m *quorumMetrics) observe(op string, success bool, outcomes []quorum.ReplicaOutcome, addrToID map[string]string) {
+	if m == nil {
+		return
+	}
+	result := "success"
+	if !success {
+		result = "failure"
+	}
+	m.operations.WithLabelValues(op, result).Inc()
+	for _, o := range outcomes {
+		replica := addrToID[o.ReplicaID]
+		if replica == "" {
+			replica = o.ReplicaID
+		}
+		m.replicaOps.WithLabelValues(op, replica, o.Class.String()).Inc()
+		if o.Latency > 0 {
+			m.replicaDuration.WithLabelValues(op, replica).Observe(o.Latency.Seconds())
+		}
+	}
+}
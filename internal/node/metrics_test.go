@@ -0,0 +1,66 @@
+package node
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"kvstore/internal/clock"
+	"kvstore/internal/metrics"
+	"kvstore/internal/quorum"
+	"kvstore/internal/ring"
+)
+
+func TestNode_Metrics(t *testing.T) {
+	self := ring.Node{ID: "n1", Addr: "127.0.0.1:50051"}
+	n := NewNode("n1", self.Addr, []ring.Node{self, {ID: "n2", Addr: "127.0.0.1:50052"}}, nil,
+		DefaultGossipConfig(), 16, 2, 1, 1, nil, []byte("secret"))
+	n.store.Put("a", []byte("value"), nil, clock.Timestamp{}, false)
+	n.store.Put("b", []byte("value"), nil, clock.Timestamp{}, false)
+	n.store.Delete("b", nil, clock.Timestamp{})
+
+	notFound := func(ctx context.Context, req any) (any, error) {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	n.observe(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/kvstore.KVStore/Get"}, notFound)
+
+	qm := newQuorumMetrics(n.Metrics())
+	qm.observe("put", true, []quorum.ReplicaOutcome{
+		{ReplicaID: self.Addr, Success: true, Latency: 3 * time.Millisecond},
+		{ReplicaID: "127.0.0.1:50052", Class: quorum.ErrorTimeout, Latency: 2 * time.Second},
+	}, map[string]string{self.Addr: "n1", "127.0.0.1:50052": "n2"})
+
+	rec := httptest.NewRecorder()
+	metrics.Handler(n.Metrics()).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	out := rec.Body
+	for _, want := range []string{
+		`kvstore_rpc_requests_total{code="NotFound",method="kvstore.KVStore/Get"} 1`,
+		`kvstore_rpc_duration_seconds_count{code="NotFound",method="kvstore.KVStore/Get"} 1`,
+		`kvstore_quorum_operations_total{op="put",result="success"} 1`,
+		`kvstore_replica_operations_total{op="put",outcome="timeout",replica="n2"} 1`,
+		`kvstore_replica_rpc_duration_seconds_bucket{op="put",replica="n1",le="0.005"} 1`,
+		`kvstore_members{status="alive"} 2`,
+		`kvstore_ring_nodes 2`,
+		`kvstore_store_keys 1`,
+		`kvstore_store_tombstones 1`,
+		`kvstore_store_bytes 6`,
+		`kvstore_clock_entries_bucket{le="1"} 3`,
+		`kvstore_clock_entries_count 3`,
+		`kvstore_clock_pruned_total{unit="entries"} 0`,
+		`kvstore_admission_operations_total{pool="replica",priority="read",result="admitted"} 0`,
+	} {
+		if !strings.Contains(out.String(), want+"\n") {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+}
+
+func TestQuorumMetrics_NilRecordsNothing(t *testing.T) {
+	var qm *quorumMetrics
+	qm.observe("get", false, []quorum.ReplicaOutcome{{ReplicaID: "a"}}, nil)
+}
@@ -0,0 +1,215 @@
+package node
+
+import (
+	"context"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"kvstore/internal/admission"
+	"kvstore/internal/conflict"
+	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/logging"
+	"kvstore/internal/namespace"
+	"kvstore/internal/ring"
+	"kvstore/internal/storage"
+)
+
+// namespaceSyncInterval is how often a node exchanges namespace definitions
+// with a random peer, so that nodes that missed a change catch up.
+const namespaceSyncInterval = 5 * time.Second
+
+// resolveNamespace returns the settings of a request's namespace. The default
+// namespace ("") uses the node's replication factor and default quorums.
+func (s *Server) resolveNamespace(name string) (namespace.Namespace, error) {
+	if name == "" {
+		rc := s.runtimeConfig()
+		return namespace.Namespace{ReplicationFactor: s.replicationFactor, R: rc.R, W: rc.W}, nil
+	}
+	if s.namespaces != nil {
+		if ns, ok := s.namespaces.Get(name); ok {
+			return ns, nil
+		}
+	}
+	return namespace.Namespace{}, status.Errorf(codes.NotFound, "namespace %q does not exist", name)
+}
+
+// checkQuota rejects a write of value to key that exceeds the quotas of its
+// namespace. The storage quotas are only checked against this node's usage
+// if it stores the key; every replica enforces them again on its own store.
+func (s *Server) checkQuota(ns namespace.Namespace, key string, value []byte, replicas []ring.Node) error {
+	for _, r := range replicas {
+		if r.ID == s.selfNode.ID {
+			return admitWrite(s.store, ns, key, value)
+		}
+	}
+	return quotaError(ns.Admit(storage.Usage{}, key, nil, value))
+}
+
+// admitWrite checks a write of value to key against the quotas of its
+// namespace and this node's usage.
+func admitWrite(store storage.Store, ns namespace.Namespace, key string, value []byte) error {
+	return quotaError(ns.Admit(store.Usage(ns.Name), key, store.Get(key), value))
+}
+
+// quotaError converts a quota error to a ResourceExhausted status.
+func quotaError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return status.Error(codes.ResourceExhausted, err.Error())
+}
+
+// Namespaces returns the node's namespace registry.
+func (n *Node) Namespaces() *namespace.Registry {
+	return n.namespaces
+}
+
+// PutNamespace creates or updates a namespace and distributes it to the
+// other nodes.
+func (n *Node) PutNamespace(ctx context.Context, ns namespace.Namespace) (namespace.Namespace, error) {
+	ns.Deleted = false
+	if err := ns.Validate(); err != nil {
+		return namespace.Namespace{}, err
+	}
+	ns.UpdatedAt = n.hlc.Now()
+	n.namespaces.Merge(ns)
+	n.broadcastNamespaces(ctx)
+	return ns, nil
+}
+
+// DeleteNamespace deletes a namespace and distributes the deletion to the
+// other nodes. Its keys become inaccessible but stay in storage.
+func (n *Node) DeleteNamespace(ctx context.Context, name string) {
+	n.namespaces.Merge(namespace.Namespace{Name: name, Deleted: true, UpdatedAt: n.hlc.Now()})
+	n.broadcastNamespaces(ctx)
+}
+
+// onNamespacesChanged applies the conflict policies of the namespaces on top
+// of the configured per-prefix policies.
+func (n *Node) onNamespacesChanged() {
+	n.runtimeMu.Lock()
+	defer n.runtimeMu.Unlock()
+
+	list := n.namespaces.List()
+	prefixes := make(map[string]conflict.Policy, len(list))
+	for _, ns := range list {
+		prefixes[storage.NamespacedKey(ns.Name, "")] = ns.ConflictPolicy
+	}
+	policies := n.policies.With(prefixes)
+	n.store.SetPolicies(policies)
+	if n.server != nil {
+		n.server.SetPolicies(policies)
+	}
+	n.log.Info("Namespaces changed", slog.Int("defined", len(list)))
+}
+
+// syncNamespacesLoop exchanges namespace definitions with a random peer every
+// namespaceSyncInterval until ctx is done.
+func (n *Node) syncNamespacesLoop(ctx context.Context) {
+	ticker := time.NewTicker(namespaceSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			peers := n.peers()
+			if len(peers) == 0 {
+				continue
+			}
+			release, err := n.coordinatorAdmission.Acquire(ctx, admission.AntiEntropy)
+			if err != nil {
+				continue // Overloaded; catch up on a later tick
+			}
+			syncCtx, cancel := context.WithTimeout(ctx, namespaceSyncInterval)
+			_ = n.syncNamespaces(syncCtx, peers[rand.Intn(len(peers))].Addr) // Best effort
+			cancel()
+			release()
+		}
+	}
+}
+
+// broadcastNamespaces sends the node's namespace definitions to every peer,
+// so that a change applies cluster-wide without waiting for the sync loop.
+func (n *Node) broadcastNamespaces(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, peer := range n.peers() {
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			if err := n.syncNamespaces(ctx, addr); err != nil {
+				n.log.Warn("Namespace sync failed, relying on background sync", slog.String("peer", addr), logging.Err(err))
+			}
+		}(peer.Addr)
+	}
+	wg.Wait()
+}
+
+// syncNamespaces sends the node's namespace definitions to the node at addr
+// and merges the definitions it returns.
+func (n *Node) syncNamespaces(ctx context.Context, addr string) error {
+	client, err := n.clientMgr.GetInternalClient(addr)
+	if err != nil {
+		return err
+	}
+	resp, err := client.SyncNamespaces(ctx, &kvstorepb.SyncNamespacesRequest{
+		FromId:     n.nodeID,
+		Namespaces: namespacesToProto(n.namespaces.All()),
+	})
+	if err != nil {
+		return err
+	}
+	n.namespaces.Merge(protoToNamespaces(resp.Namespaces)...)
+	return nil
+}
+
+// peers returns the ring members other than this node.
+func (n *Node) peers() []ring.Node {
+	n.ringMu.RLock()
+	defer n.ringMu.RUnlock()
+	var peers []ring.Node
+	for _, node := range n.ring.GetNodes() {
+		if node.ID != n.nodeID {
+			peers = append(peers, node)
+		}
+	}
+	return peers
+}
+
+// SetNamespaces sets the registry used to enforce namespace quotas and to
+// answer SyncNamespaces. Must be called before the server starts serving.
+func (s *InternalServer) SetNamespaces(namespaces *namespace.Registry) {
+	s.namespaces = namespaces
+}
+
+// SyncNamespaces merges the namespace definitions of another node and
+// returns this node's definitions.
+func (s *InternalServer) SyncNamespaces(ctx context.Context, req *kvstorepb.SyncNamespacesRequest) (*kvstorepb.SyncNamespacesResponse, error) {
+	if s.namespaces == nil {
+		return nil, status.Error(codes.Unimplemented, "node has no namespace registry")
+	}
+	s.namespaces.Merge(protoToNamespaces(req.Namespaces)...)
+	return &kvstorepb.SyncNamespacesResponse{
+		ResponderId: s.nodeID,
+		Namespaces:  namespacesToProto(s.namespaces.All()),
+	}, nil
+}
+
+// admitReplicaWrite enforces the storage quotas of a replicated write's
+// namespace.
+func (s *InternalServer) admitReplicaWrite(key string, value []byte, deleted bool) error {
+	if deleted || s.namespaces == nil {
+		return nil
+	}
+	name, _ := storage.SplitKey(key)
+	ns, ok := s.namespaces.Get(name)
+	if !ok {
+		return nil
+	}
+	return admitWrite(s.store, ns, key, value)
+}
@@ -3,58 +3,159 @@ package node
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
+	"kvstore/internal/admission"
+	"kvstore/internal/auth"
+	"kvstore/internal/causal"
+	"kvstore/internal/clock"
+	"kvstore/internal/conflict"
+	"kvstore/internal/dedup"
 	kvstorepb "kvstore/internal/gen/api"
 	"kvstore/internal/gossip"
+	"kvstore/internal/logging"
+	"kvstore/internal/metrics"
+	"kvstore/internal/namespace"
+	"kvstore/internal/ratelimit"
 	"kvstore/internal/ring"
 	"kvstore/internal/storage"
+	"kvstore/internal/tlsutil"
+	"kvstore/internal/tracing"
 )
 
 // Node represents a single node in the distributed system.
 type Node struct {
-	nodeID     string
-	listenAddr string
-	grpcServer *grpc.Server
-	store      storage.Store
-	ring       *ring.Ring
-	ringMu     sync.RWMutex // Protects ring updates
-	clientMgr  *ClientManager
-	selfNode   ring.Node
-	rf         int // replication factor
-	r          int // read quorum
-	w          int // write quorum
-	membership *gossip.Membership
+	nodeID      string
+	listenAddr  string
+	grpcServer  *grpc.Server
+	store       *storage.InMemoryStore
+	hlc         *clock.HLC
+	policies    *conflict.Policies
+	signer      *causal.Signer
+	pruner      *clock.Pruner
+	ring        *ring.Ring
+	ringMu      sync.RWMutex // Protects ring updates
+	clientMgr   *ClientManager
+	selfNode    ring.Node
+	rf          int // replication factor
+	membership  *gossip.Membership
+	server      *Server
+	namespaces  *namespace.Registry
+	limiter     *ratelimit.Limiter
+	certs       *tlsutil.Certs              // nil without TLS
+	authPolicy  atomic.Pointer[auth.Policy] // nil without authentication
+	snapshotDir string                      // Directory Snapshot writes to ("" = disabled)
+	syncCtx     context.Context             // Ends the namespace sync loop when done
+	stopSync    context.CancelFunc
+
+	// Admission control of client requests and of replica RPCs
+	coordinatorAdmission *admission.Controller
+	replicaAdmission     *admission.Controller
+
+	metrics     *prometheus.Registry
+	rpcRequests *prometheus.CounterVec
+	rpcDuration *prometheus.HistogramVec
+	tracer      trace.TracerProvider // nil = not traced
+	logs        *logging.Logs
+	log         *slog.Logger
+
+	startTime time.Time
+	ringEpoch atomic.Uint64 // Incremented on every ring rebuild
+
+	runtimeMu     sync.Mutex    // Protects runtime, configVersion and server, and serializes policy updates
+	runtime       RuntimeConfig // Default quorums, timeouts, coordinator policy, gossip timing, rate and admission limits
+	configVersion uint64
+	reloadFn      func() (RuntimeConfig, error)
+}
+
+// GossipConfig configures failure detection in gossip membership.
+type GossipConfig struct {
+	ProbeInterval  time.Duration // Interval between probes of a random member
+	SuspectTimeout time.Duration // Time without contact before a member is suspect
+	DeadTimeout    time.Duration // Time without contact before a member is dead
+}
+
+// DefaultGossipConfig returns a 1s probe interval, 3s suspect timeout and
+// 10s dead timeout.
+func DefaultGossipConfig() GossipConfig {
+	return GossipConfig{
+		ProbeInterval:  1 * time.Second,
+		SuspectTimeout: 3 * time.Second,
+		DeadTimeout:    10 * time.Second,
+	}
 }
 
 // NewNode creates a new node instance.
-// If seeds is non-empty, uses gossip membership. Otherwise, uses static ringNodes.
-func NewNode(nodeID, listenAddr string, ringNodes []ring.Node, seeds []ring.Node, vnodes, rf, r, w int) *Node {
+// If seeds is non-empty, or there are no static ringNodes, uses gossip
+// membership (a node without seeds starts a new cluster that others join).
+// Otherwise, uses static ringNodes.
+// policies selects the conflict resolution policy per key space (nil = siblings).
+// contextSecret signs client causal contexts and must be shared by all nodes;
+// if empty, an insecure development default is used.
+// The node logs to logging.Default(), whose levels Reload changes.
+func NewNode(nodeID, listenAddr string, ringNodes []ring.Node, seeds []ring.Node, gossipCfg GossipConfig, vnodes, rf, r, w int, policies *conflict.Policies, contextSecret []byte) *Node {
+	if len(contextSecret) == 0 {
+		logging.For(logging.Node).Warn("No causal context secret configured, using insecure default", logging.NodeID(nodeID))
+		contextSecret = []byte(causal.InsecureDefaultSecret)
+	}
 	store := storage.NewInMemoryStore(nodeID)
+	store.SetPolicies(policies)
+	pruneCfg := clock.DefaultPruneConfig()
+	pruner := clock.NewPruner(pruneCfg)
+	store.SetPruner(pruner)
 	rng := ring.NewRing(vnodes)
 	selfNode := ring.Node{ID: nodeID, Addr: listenAddr}
+	logs := logging.Default()
 
 	n := &Node{
 		nodeID:     nodeID,
 		listenAddr: listenAddr,
 		store:      store,
+		hlc:        clock.NewHLC(nodeID, clock.DefaultMaxOffset),
+		policies:   policies,
+		signer:     causal.NewSigner(contextSecret),
+		pruner:     pruner,
 		ring:       rng,
 		clientMgr:  NewClientManager(),
 		selfNode:   selfNode,
 		rf:         rf,
-		r:          r,
-		w:          w,
+		namespaces: namespace.NewRegistry(),
+		limiter:    ratelimit.NewLimiter(ratelimit.Config{}),
+
+		coordinatorAdmission: admission.NewController(admission.Config{}),
+		replicaAdmission:     admission.NewController(admission.Config{}),
+		metrics:              metrics.NewRegistry(),
+		logs:                 logs,
+		log:                  logs.Logger(logging.Node).With(logging.NodeID(nodeID)),
+		runtime: RuntimeConfig{
+			R:          r,
+			W:          w,
+			Timeouts:   DefaultTimeoutConfig(),
+			Gossip:     gossipCfg,
+			ClockPrune: pruneCfg,
+			Logging:    logs.Config(),
+		},
+		configVersion: 1,
+		startTime:     time.Now(),
 	}
+	n.ringEpoch.Store(1)
+	n.namespaces.SetOnChange(n.onNamespacesChanged)
+	n.registerMetrics()
+	n.syncCtx, n.stopSync = context.WithCancel(context.Background())
 
-	// Initialize membership if seeds provided (dynamic), otherwise use static
-	if len(seeds) > 0 {
+	// Use gossip membership unless only a static ring is given
+	if len(seeds) > 0 || len(ringNodes) == 0 {
 		// Dynamic membership with gossip
-		membership := gossip.NewMembership(nodeID, listenAddr, 1*time.Second, 3*time.Second, 10*time.Second)
+		membership := gossip.NewMembership(nodeID, listenAddr, gossipCfg.ProbeInterval, gossipCfg.SuspectTimeout, gossipCfg.DeadTimeout)
 		membership.AddSeedMembers(seeds)
 		membership.SetOnMembershipChanged(n.onMembershipChanged)
 		n.membership = membership
@@ -76,7 +177,18 @@ func (n *Node) Start() error {
 		return fmt.Errorf("failed to listen on %s: %w", n.listenAddr, err)
 	}
 
-	n.grpcServer = grpc.NewServer()
+	var opts []grpc.ServerOption
+	interceptors := []grpc.UnaryServerInterceptor{n.trace, n.observe, n.rateLimit, n.admit}
+	if n.certs != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(n.certs.ServerConfig())))
+		// Authenticate before rate limiting, so that limits apply per principal
+		interceptors = []grpc.UnaryServerInterceptor{n.trace, n.observe, n.requireNodeCert, n.authorize, n.rateLimit, n.admit}
+	}
+	opts = append(opts, grpc.ChainUnaryInterceptor(interceptors...))
+	if n.tracer != nil {
+		opts = append(opts, grpc.StatsHandler(tracing.ServerHandler(n.tracer, traced)))
+	}
+	n.grpcServer = grpc.NewServer(opts...)
 
 	// Create thread-safe ring getter
 	ringGetter := func() *ring.Ring {
@@ -85,13 +197,34 @@ func (n *Node) Start() error {
 		return n.ring
 	}
 
-	server := NewServer(n.store, n.nodeID, n.ring, ringGetter, n.selfNode, n.clientMgr, n.rf, n.r, n.w)
+	n.runtimeMu.Lock()
+	server := NewServer(n.store, n.nodeID, n.ring, ringGetter, n.selfNode, n.clientMgr, n.hlc, n.policies, n.signer, n.rf, n.runtime.R, n.runtime.W)
+	server.SetRuntimeConfig(n.runtime)
+	n.server = server
+	n.runtimeMu.Unlock()
+	// Local and replica writes share one table so each write is applied once
+	applied := dedup.NewTable[chan struct{}](dedup.DefaultCapacity, dedup.DefaultTTL)
+	server.SetAppliedTable(applied)
+	server.SetNamespaces(n.namespaces)
+	server.SetRepairAdmission(n.coordinatorAdmission)
+	server.SetMetrics(n.metrics)
+	server.SetTracer(n.tracer)
+	if n.membership != nil {
+		server.SetLiveness(n.membership.IsAlive)
+	}
 	kvstorepb.RegisterKVStoreServer(n.grpcServer, server)
 
 	// Register internal service
-	internalServer := NewInternalServer(n.store, n.nodeID)
+	internalServer := NewInternalServer(n.store, n.nodeID, n.hlc)
+	internalServer.SetAppliedTable(applied)
+	internalServer.SetNamespaces(n.namespaces)
+	internalServer.SetTracer(n.tracer)
+	internalServer.SetStatus(n.Status)
 	kvstorepb.RegisterKVInternalServer(n.grpcServer, internalServer)
 
+	// Exchange namespace definitions with peers in the background
+	go n.syncNamespacesLoop(n.syncCtx)
+
 	// Register membership service if using gossip
 	if n.membership != nil {
 		ringGetter := func() *ring.Ring {
@@ -104,13 +237,16 @@ func (n *Node) Start() error {
 
 		// Start membership protocol
 		n.membership.Start(n.probeFn, n.gossipFn)
-		log.Printf("[%s] Started gossip membership", n.nodeID)
+		n.log.Info("Started gossip membership")
 	}
 
+	// Register admin service
+	kvstorepb.RegisterAdminServer(n.grpcServer, NewAdminServer(n))
+
 	// Enable gRPC reflection for grpcurl
 	reflection.Register(n.grpcServer)
 
-	log.Printf("[%s] Starting node on %s", n.nodeID, n.listenAddr)
+	n.log.Info("Starting node", slog.String("addr", n.listenAddr))
 
 	if err := n.grpcServer.Serve(lis); err != nil {
 		return fmt.Errorf("failed to serve: %w", err)
@@ -121,18 +257,51 @@ func (n *Node) Start() error {
 
 // Stop gracefully stops the node.
 func (n *Node) Stop() {
+	n.stopSync()
 	if n.membership != nil {
 		n.membership.Stop()
 	}
 	if n.grpcServer != nil {
-		log.Printf("[%s] Stopping node", n.nodeID)
+		n.log.Info("Stopping node")
 		n.grpcServer.GracefulStop()
 	}
 }
 
+// SetTimeoutConfig sets the per-replica RPC timeout configuration.
+// Must be called before Start.
+func (n *Node) SetTimeoutConfig(cfg TimeoutConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	n.runtime.Timeouts = cfg
+	return nil
+}
+
+// SetCoordinatorPolicy sets which node coordinates client requests.
+// Must be called before Start.
+func (n *Node) SetCoordinatorPolicy(policy CoordinatorPolicy) {
+	n.runtime.Coordinator = policy
+}
+
+// SetClockPruning sets the size and age limits of vector clocks written by
+// this node. Must be called before Start; use Reload afterwards.
+func (n *Node) SetClockPruning(cfg clock.PruneConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	n.runtime.ClockPrune = cfg
+	n.pruner.SetConfig(cfg)
+	return nil
+}
+
+// ClockStats returns vector clock size and pruning statistics for local writes.
+func (n *Node) ClockStats() clock.PruneStats {
+	return n.pruner.Stats()
+}
+
 // onMembershipChanged is called when membership changes (callback from gossip).
 func (n *Node) onMembershipChanged(aliveNodes []ring.Node) {
-	log.Printf("[%s] Membership changed: %d alive nodes", n.nodeID, len(aliveNodes))
+	n.log.Info("Membership changed", slog.Int("alive", len(aliveNodes)))
 
 	// Rebuild ring with alive nodes only
 	n.ringMu.Lock()
@@ -140,8 +309,9 @@ func (n *Node) onMembershipChanged(aliveNodes []ring.Node) {
 	newRing.SetNodes(aliveNodes)
 	n.ring = newRing
 	n.ringMu.Unlock()
+	n.ringEpoch.Add(1)
 
-	log.Printf("[%s] Ring updated with %d nodes", n.nodeID, len(aliveNodes))
+	n.log.Info("Ring updated", slog.Int("nodes", len(aliveNodes)))
 }
 
 // probeFn performs a ping probe for failure detection.
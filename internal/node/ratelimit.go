@@ -0,0 +1,107 @@
+package node
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"kvstore/internal/auth"
+	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/ratelimit"
+)
+
+// SetRateLimits sets the per-client rate limits. Must be called before
+// Start; use Reload afterwards.
+func (n *Node) SetRateLimits(cfg ratelimit.Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	n.runtime.RateLimits = cfg
+	n.limiter.SetConfig(cfg)
+	return nil
+}
+
+// RateLimitStats returns the number of requests admitted and throttled by the
+// per-client rate limits.
+func (n *Node) RateLimitStats() ratelimit.Stats {
+	return n.limiter.Stats()
+}
+
+// rateLimit enforces the per-client rate limits on data requests. Requests
+// are limited by the node that received them from the client, so forwarded
+// requests are not counted twice.
+func (n *Node) rateLimit(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if !strings.HasPrefix(info.FullMethod, "/kvstore.KVStore/") || n.forwardedByNode(ctx) {
+		return handler(ctx, req)
+	}
+	client := clientName(ctx, req)
+	if err := n.limiter.Allow(client, requestBytes(req)); err != nil {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	resp, err := handler(ctx, req)
+	if size := responseBytes(resp); size > 0 {
+		n.limiter.Charge(client, size)
+	}
+	return resp, err
+}
+
+// forwardedByNode reports whether a request was forwarded by another node.
+// With TLS, only a caller with a node certificate can forward.
+func (n *Node) forwardedByNode(ctx context.Context) bool {
+	if !isForwarded(ctx) {
+		return false
+	}
+	if n.certs == nil {
+		return true
+	}
+	_, err := n.peerNodeCert(ctx)
+	return err == nil
+}
+
+// clientName returns the name a request is rate limited under: its
+// authenticated principal, or else its client ID.
+func clientName(ctx context.Context, req any) string {
+	if p, ok := auth.FromContext(ctx); ok && p.Name != "" {
+		return p.Name
+	}
+	if r, ok := req.(interface{ GetClientId() string }); ok {
+		return r.GetClientId()
+	}
+	return ""
+}
+
+// requestBytes returns the size of the keys and values a request sends.
+func requestBytes(req any) int {
+	switch r := req.(type) {
+	case *kvstorepb.PutRequest:
+		return len(r.Key) + len(r.Value)
+	case *kvstorepb.GetRequest:
+		return len(r.Key)
+	case *kvstorepb.DeleteRequest:
+		return len(r.Key)
+	case *kvstorepb.ScanRequest:
+		return len(r.Prefix)
+	}
+	return 0
+}
+
+// responseBytes returns the size of the values or keys a response returns.
+func responseBytes(resp any) int {
+	size := 0
+	switch r := resp.(type) {
+	case *kvstorepb.GetResponse:
+		if r.Value != nil {
+			size += len(r.Value.Value)
+		}
+		for _, v := range r.Conflicts {
+			size += len(v.Value)
+		}
+	case *kvstorepb.ScanResponse:
+		for _, key := range r.Keys {
+			size += len(key)
+		}
+	}
+	return size
+}
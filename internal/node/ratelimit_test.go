@@ -0,0 +1,68 @@
+package node
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"kvstore/internal/auth"
+	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/ratelimit"
+)
+
+func TestNode_RateLimit(t *testing.T) {
+	n := &Node{limiter: ratelimit.NewLimiter(ratelimit.Config{
+		Default: ratelimit.Limits{Requests: 1, Bytes: 100},
+		Clients: map[string]ratelimit.Limits{"alice": {Requests: 100}, "reader": {Bytes: 100}},
+	})}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return &kvstorepb.GetResponse{Value: &kvstorepb.VersionedValue{Value: make([]byte, 150)}}, nil
+	}
+	call := func(ctx context.Context, method string, req any) codes.Code {
+		_, err := n.rateLimit(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return status.Code(err)
+	}
+	ctx := context.Background()
+
+	if code := call(ctx, "/kvstore.KVStore/Put", &kvstorepb.PutRequest{Key: "k", ClientId: "batch"}); code != codes.OK {
+		t.Fatalf("Expected first request to pass, got %v", code)
+	}
+	if code := call(ctx, "/kvstore.KVStore/Put", &kvstorepb.PutRequest{Key: "k", ClientId: "batch"}); code != codes.ResourceExhausted {
+		t.Errorf("Expected second request in the same second to be throttled, got %v", code)
+	}
+	if code := call(ctx, "/kvstore.KVStore/Put", &kvstorepb.PutRequest{Key: "k", ClientId: "web"}); code != codes.OK {
+		t.Errorf("Expected other clients to be unaffected, got %v", code)
+	}
+
+	// Limits apply to the authenticated principal, whatever client ID it sends
+	ctx = auth.NewContext(context.Background(), auth.Principal{Name: "alice"})
+	for i := 0; i < 5; i++ {
+		if code := call(ctx, "/kvstore.KVStore/Get", &kvstorepb.GetRequest{Key: "k", ClientId: "batch"}); code != codes.OK {
+			t.Fatalf("Expected alice's override to apply, got %v", code)
+		}
+	}
+
+	// Bytes read are charged after the request
+	read := &kvstorepb.GetRequest{Key: "k", ClientId: "reader"}
+	if code := call(context.Background(), "/kvstore.KVStore/Get", read); code != codes.OK {
+		t.Fatalf("Expected first read to pass, got %v", code)
+	}
+	if code := call(context.Background(), "/kvstore.KVStore/Get", read); code != codes.ResourceExhausted {
+		t.Errorf("Expected read after exceeding the byte rate to be throttled, got %v", code)
+	}
+	if stats := n.RateLimitStats(); stats.ThrottledRequests != 1 || stats.ThrottledBytes != 1 {
+		t.Errorf("Expected 1 request and 1 byte throttle, got %+v", stats)
+	}
+
+	// Other services and forwarded requests are not limited
+	if code := call(context.Background(), "/kvstore.Admin/GetConfig", &kvstorepb.GetConfigRequest{}); code != codes.OK {
+		t.Errorf("Expected admin request to pass, got %v", code)
+	}
+	fwd := metadata.NewIncomingContext(context.Background(), metadata.Pairs(forwardedMetadataKey, forwardedValue))
+	if code := call(fwd, "/kvstore.KVStore/Put", &kvstorepb.PutRequest{Key: "k", ClientId: "batch"}); code != codes.OK {
+		t.Errorf("Expected forwarded request to pass, got %v", code)
+	}
+}
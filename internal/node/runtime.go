@@ -0,0 +1,152 @@
+package node
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	"kvstore/internal/clock"
+	"kvstore/internal/logging"
+	"kvstore/internal/ratelimit"
+)
+
+// RuntimeConfig holds the node settings that can be changed while the node
+// runs. The replication factor, ring and storage settings require a restart.
+type RuntimeConfig struct {
+	R           int               // Default read quorum
+	W           int               // Default write quorum
+	Timeouts    TimeoutConfig     // Per-replica RPC timeouts
+	Coordinator CoordinatorPolicy // Whether non-replicas forward requests
+	Gossip      GossipConfig      // Failure detection timing
+	RateLimits  ratelimit.Config  // Per-client request and byte rates
+	Admission   AdmissionConfig   // Concurrency limits and load shedding
+	ClockPrune  clock.PruneConfig // Vector clock size and age limits
+	Logging     logging.Config    // Log levels and sampling
+}
+
+// Validate returns an error if the configuration is inconsistent or does not
+// fit replication factor rf.
+func (c RuntimeConfig) Validate(rf int) error {
+	if c.R < 1 || c.R > rf {
+		return fmt.Errorf("r must be between 1 and rf (%d), got %d", rf, c.R)
+	}
+	if c.W < 1 || c.W > rf {
+		return fmt.Errorf("w must be between 1 and rf (%d), got %d", rf, c.W)
+	}
+	if err := c.Timeouts.Validate(); err != nil {
+		return err
+	}
+	if err := c.RateLimits.Validate(); err != nil {
+		return err
+	}
+	if err := c.Admission.Validate(); err != nil {
+		return err
+	}
+	if err := c.ClockPrune.Validate(); err != nil {
+		return err
+	}
+	if err := c.Logging.Validate(); err != nil {
+		return err
+	}
+	return c.Gossip.Validate()
+}
+
+// Validate returns an error unless 0 < ProbeInterval < SuspectTimeout < DeadTimeout.
+func (c GossipConfig) Validate() error {
+	if c.ProbeInterval <= 0 {
+		return fmt.Errorf("gossip probe interval must be positive, got %v", c.ProbeInterval)
+	}
+	if c.SuspectTimeout <= c.ProbeInterval {
+		return fmt.Errorf("gossip suspect timeout (%v) must exceed the probe interval (%v)", c.SuspectTimeout, c.ProbeInterval)
+	}
+	if c.DeadTimeout <= c.SuspectTimeout {
+		return fmt.Errorf("gossip dead timeout (%v) must exceed the suspect timeout (%v)", c.DeadTimeout, c.SuspectTimeout)
+	}
+	return nil
+}
+
+// RuntimeConfig returns the active runtime configuration and its version.
+// The version is 1 for the startup configuration and increments on every
+// reload that changes it.
+func (n *Node) RuntimeConfig() (RuntimeConfig, uint64) {
+	n.runtimeMu.Lock()
+	defer n.runtimeMu.Unlock()
+	return n.runtime, n.configVersion
+}
+
+// Reload validates cfg and applies it to the request path and the gossip
+// loop. Requests in flight finish with the configuration they started with.
+// It returns the active version and whether cfg differed from the active
+// configuration; an invalid cfg leaves the active configuration unchanged.
+func (n *Node) Reload(cfg RuntimeConfig) (version uint64, changed bool, err error) {
+	if err := cfg.Validate(n.rf); err != nil {
+		return 0, false, fmt.Errorf("invalid runtime config: %w", err)
+	}
+
+	n.runtimeMu.Lock()
+	defer n.runtimeMu.Unlock()
+	if reflect.DeepEqual(cfg, n.runtime) {
+		return n.configVersion, false, nil
+	}
+
+	if n.server != nil {
+		n.server.SetRuntimeConfig(cfg)
+	}
+	if n.membership != nil {
+		n.membership.SetTimeouts(cfg.Gossip.ProbeInterval, cfg.Gossip.SuspectTimeout, cfg.Gossip.DeadTimeout)
+	}
+	n.coordinatorAdmission.SetConfig(cfg.Admission.Coordinator)
+	n.replicaAdmission.SetConfig(cfg.Admission.Replica)
+	n.pruner.SetConfig(cfg.ClockPrune)
+	// Replacing the limits resets every client's buckets, so keep them otherwise
+	if !reflect.DeepEqual(cfg.RateLimits, n.runtime.RateLimits) {
+		n.limiter.SetConfig(cfg.RateLimits)
+	}
+	_ = n.logs.SetConfig(cfg.Logging) // Validated above
+	n.runtime = cfg
+	n.configVersion++
+
+	n.log.Info("Applied runtime config", slog.Uint64("version", n.configVersion),
+		slog.Int("r", cfg.R), slog.Int("w", cfg.W), slog.String("coordinator", cfg.Coordinator.String()),
+		slog.String("replica_timeout", fmt.Sprintf("%s/%v", cfg.Timeouts.Mode, cfg.Timeouts.Fixed)),
+		slog.String("gossip", fmt.Sprintf("%v/%v/%v", cfg.Gossip.ProbeInterval, cfg.Gossip.SuspectTimeout, cfg.Gossip.DeadTimeout)),
+		slog.Float64("rate_limit_requests", cfg.RateLimits.Default.Requests), slog.Float64("rate_limit_bytes", cfg.RateLimits.Default.Bytes),
+		slog.Int("coordinator_max_in_flight", cfg.Admission.Coordinator.MaxInFlight), slog.Int("replica_max_in_flight", cfg.Admission.Replica.MaxInFlight),
+		slog.Int("clock_prune_max_entries", cfg.ClockPrune.MaxEntries), slog.Duration("clock_prune_max_age", cfg.ClockPrune.MaxAge),
+		slog.String("log_levels", cfg.Logging.String()))
+	return n.configVersion, true, nil
+}
+
+// SetReloadFunc sets the function that rereads the runtime configuration
+// from its sources for the Admin ReloadConfig RPC. Must be called before Start.
+func (n *Node) SetReloadFunc(fn func() (RuntimeConfig, error)) {
+	n.reloadFn = fn
+}
+
+// ReloadFromSource rereads the runtime configuration with the reload function
+// and applies it.
+func (n *Node) ReloadFromSource() (version uint64, changed bool, err error) {
+	if n.reloadFn == nil {
+		return 0, false, fmt.Errorf("no configuration source to reload from")
+	}
+	cfg, err := n.reloadFn()
+	if err != nil {
+		return 0, false, err
+	}
+	return n.Reload(cfg)
+}
+
+// runtimeConfig returns the active configuration. Snapshots are never
+// modified, so a reader sees either the old or the new configuration.
+func (s *Server) runtimeConfig() *RuntimeConfig {
+	if cfg := s.runtime.Load(); cfg != nil {
+		return cfg
+	}
+	return &RuntimeConfig{R: 2, W: 2, Timeouts: DefaultTimeoutConfig(), Gossip: DefaultGossipConfig()}
+}
+
+// SetRuntimeConfig atomically replaces the server's runtime configuration.
+// It is safe to call while serving requests.
+func (s *Server) SetRuntimeConfig(cfg RuntimeConfig) {
+	s.runtime.Store(&cfg)
+}
@@ -0,0 +1,87 @@
+package node
+
+import (
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"kvstore/internal/logging"
+	"kvstore/internal/ratelimit"
+	"kvstore/internal/ring"
+)
+
+func TestNode_Reload(t *testing.T) {
+	nodes := []ring.Node{
+		{ID: "n1", Addr: "localhost:50051"},
+		{ID: "n2", Addr: "localhost:50052"},
+		{ID: "n3", Addr: "localhost:50053"},
+	}
+	n := NewNode("n1", "localhost:50051", nodes, nil, DefaultGossipConfig(), 16, 3, 2, 2, nil, []byte("secret"))
+	n.server = NewServer(n.store, "n1", n.ring, nil, n.selfNode, n.clientMgr, n.hlc, nil, n.signer, 3, 2, 2)
+	n.logs, _ = logging.New(io.Discard, logging.FormatText, logging.DefaultConfig())
+
+	cfg, version := n.RuntimeConfig()
+	if version != 1 || cfg.R != 2 || cfg.W != 2 {
+		t.Fatalf("Expected startup config v1 with R=2 W=2, got v%d %+v", version, cfg)
+	}
+
+	// Invalid changes are rejected and leave the active config in place
+	bad := cfg
+	bad.W = 4
+	if _, _, err := n.Reload(bad); err == nil {
+		t.Error("Expected error for W > N")
+	}
+	bad = cfg
+	bad.Gossip.DeadTimeout = bad.Gossip.SuspectTimeout
+	if _, _, err := n.Reload(bad); err == nil {
+		t.Error("Expected error for dead timeout <= suspect timeout")
+	}
+	bad = cfg
+	bad.ClockPrune.MaxEntries = 1
+	if _, _, err := n.Reload(bad); err == nil {
+		t.Error("Expected error for clock prune max entries below min entries")
+	}
+	bad = cfg
+	bad.Logging.Levels = map[string]slog.Level{"disk": slog.LevelDebug}
+	if _, _, err := n.Reload(bad); err == nil {
+		t.Error("Expected error for unknown log subsystem")
+	}
+	if _, version := n.RuntimeConfig(); version != 1 {
+		t.Errorf("Expected version to stay 1 after rejected reloads, got %d", version)
+	}
+
+	// A valid change is applied to the server and bumps the version
+	next := cfg
+	next.R, next.W = 1, 3
+	next.Coordinator = CoordinateReplica
+	next.Timeouts.Fixed = 500 * time.Millisecond
+	next.RateLimits = ratelimit.Config{Default: ratelimit.Limits{Requests: 1}}
+	next.Logging = logging.Config{Level: slog.LevelWarn, Levels: map[string]slog.Level{logging.Gossip: slog.LevelDebug}}
+	next.ClockPrune.MaxEntries = 20
+	version, changed, err := n.Reload(next)
+	if err != nil || !changed || version != 2 {
+		t.Fatalf("Reload() = v%d, changed=%v, err=%v; want v2, changed", version, changed, err)
+	}
+	if got := *n.server.runtimeConfig(); !reflect.DeepEqual(got, next) {
+		t.Errorf("Expected server to use the reloaded config, got %+v", got)
+	}
+	if got := n.server.quorumOptions(0).Timeout("localhost:50052"); got != 500*time.Millisecond {
+		t.Errorf("Expected reloaded 500ms replica timeout, got %v", got)
+	}
+	if n.limiter.Allow("c", 0) != nil || n.limiter.Allow("c", 0) == nil {
+		t.Error("Expected reloaded rate limit of 1 request per second")
+	}
+	if got := n.logs.Config(); got.LevelOf(logging.Gossip) != slog.LevelDebug || got.LevelOf(logging.Server) != slog.LevelWarn {
+		t.Errorf("Expected reloaded log levels warn,gossip=debug, got %s", got)
+	}
+	if got := n.pruner.Config(); got != next.ClockPrune {
+		t.Errorf("Expected reloaded clock pruning %+v, got %+v", next.ClockPrune, got)
+	}
+
+	// Reloading the same config is a no-op
+	if version, changed, err := n.Reload(next); err != nil || changed || version != 2 {
+		t.Errorf("Reload(same) = v%d, changed=%v, err=%v; want v2, unchanged", version, changed, err)
+	}
+}
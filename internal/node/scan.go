@@ -0,0 +1,60 @@
+package node
+
+import (
+	"context"
+	"time"
+
+	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/storage"
+)
+
+const (
+	// Default and maximum number of keys per Scan page
+	defaultScanLimit = 1000
+	maxScanLimit     = 10000
+)
+
+// Scan lists the live keys of a namespace stored on this node. Each key is
+// stored on N nodes, and no node stores every key, so a client scanning the
+// cluster queries every member and merges the results.
+func (s *Server) Scan(ctx context.Context, req *kvstorepb.ScanRequest) (*kvstorepb.ScanResponse, error) {
+	ns, err := s.resolveNamespace(req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultScanLimit
+	}
+	if limit > maxScanLimit {
+		limit = maxScanLimit
+	}
+
+	// Page through the store until limit keys that have not outlived the
+	// namespace TTL are found
+	prefix := storage.NamespacedKey(ns.Name, req.Prefix)
+	after := storage.NamespacedKey(ns.Name, req.StartAfter)
+	now := time.Now()
+	keys := make([]string, 0)
+	more := true
+	for more && len(keys) < limit {
+		var page []string
+		page, more = s.store.Keys(prefix, after, limit-len(keys))
+		for _, stored := range page {
+			after = stored
+			if ns.DefaultTTL > 0 {
+				if vv := s.store.Get(stored); vv == nil || ns.Expired(vv.Timestamp, now) {
+					continue
+				}
+			}
+			_, key := storage.SplitKey(stored)
+			keys = append(keys, key)
+		}
+	}
+	return &kvstorepb.ScanResponse{
+		Keys:   keys,
+		More:   more,
+		NodeId: s.nodeID,
+	}, nil
+}
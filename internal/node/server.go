@@ -1,12 +1,24 @@
 package node
 
 import (
+	"log/slog"
+	"sync/atomic"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+	"kvstore/internal/admission"
+	"kvstore/internal/causal"
+	"kvstore/internal/clock"
+	"kvstore/internal/conflict"
+	"kvstore/internal/dedup"
 	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/logging"
+	"kvstore/internal/namespace"
 	"kvstore/internal/repair"
 	"kvstore/internal/ring"
 	"kvstore/internal/storage"
+	"kvstore/internal/tracing"
 )
 
 // Server implements the KVStore gRPC service.
@@ -18,16 +30,27 @@ type Server struct {
 	ringGetter        func() *ring.Ring // Thread-safe ring getter (for dynamic membership)
 	selfNode          ring.Node
 	clientMgr         *ClientManager
+	hlc               *clock.HLC                        // Hybrid logical clock for version timestamps
+	policies          atomic.Pointer[conflict.Policies] // Conflict resolution policy per key space
+	signer            *causal.Signer                    // Signs and verifies client causal contexts
+	namespaces        *namespace.Registry               // nil = default namespace only
 	replicationFactor int
-	defaultR          int
-	defaultW          int
-	readRepairer      *repair.ReadRepairer // Read repair coordinator
+	runtime           atomic.Pointer[RuntimeConfig] // Default quorums, timeouts and coordinator policy
+	readRepairer      *repair.ReadRepairer          // Read repair coordinator
+	writes            *dedup.Table[*writeRecord]    // Client writes by client_id+request_id
+	applied           *dedup.Table[chan struct{}]   // Writes applied to the local store
+	metrics           *quorumMetrics                // nil = not recorded
+	isAlive           func(nodeID string) bool      // nil = every ring node is alive
+	tracer            trace.Tracer
+	log               *slog.Logger
 }
 
 // NewServer creates a new gRPC server instance.
 // If ringGetter is provided, it's used for thread-safe ring access (dynamic membership).
-// Otherwise, the static ring is used.
-func NewServer(store storage.Store, nodeID string, r *ring.Ring, ringGetter func() *ring.Ring, self ring.Node, clientMgr *ClientManager, rf, defaultR, defaultW int) *Server {
+// Otherwise, the static ring is used. If hlc is nil, a clock with the default
+// max offset is created. A nil policies returns siblings for every key.
+// If signer is nil, contexts are signed with causal.InsecureDefaultSecret.
+func NewServer(store storage.Store, nodeID string, r *ring.Ring, ringGetter func() *ring.Ring, self ring.Node, clientMgr *ClientManager, hlc *clock.HLC, policies *conflict.Policies, signer *causal.Signer, rf, defaultR, defaultW int) *Server {
 	if rf <= 0 {
 		rf = 3
 	}
@@ -37,21 +60,38 @@ func NewServer(store storage.Store, nodeID string, r *ring.Ring, ringGetter func
 	if defaultW <= 0 {
 		defaultW = 2
 	}
+	if hlc == nil {
+		hlc = clock.NewHLC(nodeID, clock.DefaultMaxOffset)
+	}
+	if signer == nil {
+		signer = causal.NewSigner([]byte(causal.InsecureDefaultSecret))
+	}
 	s := &Server{
 		store:             store,
 		nodeID:            nodeID,
 		ring:              r,
 		selfNode:          self,
 		clientMgr:         clientMgr,
+		hlc:               hlc,
+		signer:            signer,
 		replicationFactor: rf,
-		defaultR:          defaultR,
-		defaultW:          defaultW,
+		writes:            dedup.NewTable[*writeRecord](dedup.DefaultCapacity, dedup.DefaultTTL),
+		applied:           dedup.NewTable[chan struct{}](dedup.DefaultCapacity, dedup.DefaultTTL),
+		tracer:            tracing.Tracer(nil),
+		log:               logging.For(logging.Server).With(logging.NodeID(nodeID)),
 	}
+	s.policies.Store(policies)
 	if ringGetter != nil {
 		s.ringGetter = ringGetter
 	} else {
 		s.ringGetter = func() *ring.Ring { return r }
 	}
+	s.SetRuntimeConfig(RuntimeConfig{
+		R:        defaultR,
+		W:        defaultW,
+		Timeouts: DefaultTimeoutConfig(),
+		Gossip:   DefaultGossipConfig(),
+	})
 
 	// Initialize read repairer
 	s.readRepairer = repair.NewReadRepairer(
@@ -60,8 +100,82 @@ func NewServer(store storage.Store, nodeID string, r *ring.Ring, ringGetter func
 		},
 		2*time.Second, // repair timeout
 	)
+	s.readRepairer.SetLogger(logging.For(logging.Repair).With(logging.NodeID(nodeID)))
 
 	return s
 }
 
+// SetTimeoutConfig sets the per-replica RPC timeout configuration.
+// Must be called before the server starts serving requests.
+func (s *Server) SetTimeoutConfig(cfg TimeoutConfig) {
+	rc := *s.runtimeConfig()
+	rc.Timeouts = cfg
+	s.SetRuntimeConfig(rc)
+}
+
+// SetCoordinatorPolicy sets which node coordinates client requests.
+// Must be called before the server starts serving requests.
+func (s *Server) SetCoordinatorPolicy(policy CoordinatorPolicy) {
+	rc := *s.runtimeConfig()
+	rc.Coordinator = policy
+	s.SetRuntimeConfig(rc)
+}
+
+// SetAppliedTable sets the table of writes applied to the local store. It
+// must be shared with the node's InternalServer so that a write is applied at
+// most once whether it arrives locally or through ReplicaPut.
+func (s *Server) SetAppliedTable(applied *dedup.Table[chan struct{}]) {
+	s.applied = applied
+}
+
+// SetPolicies replaces the conflict resolution policies. It is safe to call
+// while serving requests.
+func (s *Server) SetPolicies(policies *conflict.Policies) {
+	s.policies.Store(policies)
+}
+
+// SetNamespaces sets the registry that resolves request namespaces.
+// Must be called before the server starts serving requests.
+func (s *Server) SetNamespaces(namespaces *namespace.Registry) {
+	s.namespaces = namespaces
+}
+
+// SetLiveness sets the function reporting whether a node is alive in the
+// gossip membership; requests are only forwarded to alive replicas. Must be
+// called before the server starts serving.
+func (s *Server) SetLiveness(isAlive func(nodeID string) bool) {
+	s.isAlive = isAlive
+}
+
+// SetRepairAdmission bounds the read repairs the server runs at once; repairs
+// beyond the limit are skipped. Must be called before the server starts
+// serving.
+func (s *Server) SetRepairAdmission(c *admission.Controller) {
+	s.readRepairer.SetAdmission(c)
+}
+
+// SetMetrics registers the quorum and replica RPC metrics on reg and records
+// them. Must be called before the server starts serving, at most once per
+// registry.
+func (s *Server) SetMetrics(reg prometheus.Registerer) {
+	s.metrics = newQuorumMetrics(reg)
+}
+
+// SetTracer traces quorum operations, local store operations and read
+// repair. Must be called before the server starts serving.
+func (s *Server) SetTracer(tp trace.TracerProvider) {
+	s.tracer = tracing.Tracer(tp)
+	s.readRepairer.SetTracer(tp)
+}
+
+// RepairStats returns the read repairs run by the server.
+func (s *Server) RepairStats() repair.Stats {
+	return s.readRepairer.Stats()
+}
+
+// RepairPending returns the number of read repairs in progress.
+func (s *Server) RepairPending() int64 {
+	return s.readRepairer.Pending()
+}
+
 // Put, Get, Delete are implemented in server_quorum.go for Phase 3 quorum coordination
@@ -3,21 +3,35 @@ package node
 import (
 	"context"
 	"fmt"
-	"log"
+	"sync/atomic"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"kvstore/internal/clock"
+	"kvstore/internal/dedup"
 	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/logging"
 	"kvstore/internal/quorum"
 	"kvstore/internal/repair"
 	"kvstore/internal/replication"
+	"kvstore/internal/ring"
+	"kvstore/internal/storage"
 )
 
+// replicaVersion is the version metadata returned by a single replica read.
+type replicaVersion struct {
+	clock     clock.VectorClock
+	timestamp clock.Timestamp
+	expiresAt *time.Time // nil if the value does not expire
+	addr      string     // Replica that returned the version
+	digest    []byte     // Value digest; set for digest reads only
+	full      bool       // The read returned the full value
+}
+
 // Put handles Put requests with quorum coordination.
 func (s *Server) Put(ctx context.Context, req *kvstorepb.PutRequest) (*kvstorepb.PutResponse, error) {
-	log.Printf("[%s] Put request: key=%s, client_id=%s, request_id=%s",
-		s.nodeID, req.Key, req.ClientId, req.RequestId)
+	s.logRequest(ctx, "Put", req.Namespace, req.Key, req.ClientId, req.RequestId)
 
 	if req.Key == "" {
 		return &kvstorepb.PutResponse{
@@ -25,22 +39,36 @@ func (s *Server) Put(ctx context.Context, req *kvstorepb.PutRequest) (*kvstorepb
 			ErrorMessage: "key cannot be empty",
 		}, nil
 	}
-
-	// Get replication factor and quorum sizes
-	rf := s.replicationFactor
-	if rf <= 0 {
-		rf = 3
+	if !storage.ValidKey(req.Key) {
+		return &kvstorepb.PutResponse{
+			Status:       kvstorepb.PutResponse_ERROR,
+			ErrorMessage: "key cannot contain NUL bytes",
+		}, status.Error(codes.InvalidArgument, "key cannot contain NUL bytes")
 	}
-	requiredW := int(req.ConsistencyW)
-	if requiredW <= 0 {
-		requiredW = s.defaultW
+	if req.TtlMs < 0 {
+		return &kvstorepb.PutResponse{
+			Status:       kvstorepb.PutResponse_ERROR,
+			ErrorMessage: "ttl_ms cannot be negative",
+		}, status.Error(codes.InvalidArgument, "ttl_ms cannot be negative")
+	}
+
+	// Resolve the namespace's replication settings and stored key
+	ns, err := s.resolveNamespace(req.Namespace)
+	if err != nil {
+		return &kvstorepb.PutResponse{
+			Status:       kvstorepb.PutResponse_ERROR,
+			ErrorMessage: status.Convert(err).Message(),
+		}, err
 	}
+	rf := ns.ReplicationFactor
+	_, defaultW := ns.Quorums()
+	key := storage.NamespacedKey(ns.Name, req.Key)
 
 	// Get ring (thread-safe if using dynamic membership)
 	rng := s.ringGetter()
 
 	// Get preference list (replicas)
-	replicas := replication.GetReplicasForKey(rng, req.Key, rf)
+	replicas := replication.GetReplicasForKey(rng, key, rf)
 	if len(replicas) == 0 {
 		return &kvstorepb.PutResponse{
 			Status:       kvstorepb.PutResponse_ERROR,
@@ -48,21 +76,70 @@ func (s *Server) Put(ctx context.Context, req *kvstorepb.PutRequest) (*kvstorepb
 		}, nil
 	}
 
-	// Prepare version: merge client-provided context (known versions from previous Get)
-	// This enables proper causality when client resolves conflicts
-	newVersion := clock.New()
-	if req.Version != nil {
-		// Client provided a version context (e.g., from resolving conflicts)
-		// Merge it to ensure the new write dominates the known versions
-		newVersion = protoToVectorClock(req.Version)
+	// Forward to a replica if this node should not coordinate the key
+	if targets := s.forwardTargets(ctx, replicas); len(targets) > 0 {
+		call := func(ctx context.Context, client kvstorepb.KVStoreClient) (*kvstorepb.PutResponse, error) {
+			return client.Put(ctx, req)
+		}
+		if resp, forwarded, err := forward(ctx, s, req.Key, targets, call); forwarded {
+			return resp, err
+		}
+	}
+
+	// Enforce the namespace quotas
+	if err := s.checkQuota(ns, key, req.Value, replicas); err != nil {
+		return &kvstorepb.PutResponse{
+			Status:       kvstorepb.PutResponse_ERROR,
+			ErrorMessage: status.Convert(err).Message(),
+		}, err
+	}
+
+	// Resolve quorum size from the consistency level or raw W
+	requiredW, err := requiredReplicas(req.ConsistencyLevel, req.ConsistencyW, defaultW, rf, len(replicas))
+	if err != nil {
+		return &kvstorepb.PutResponse{
+			Status:       kvstorepb.PutResponse_ERROR,
+			ErrorMessage: status.Convert(err).Message(),
+		}, err
+	}
+
+	// Prepare version, reusing the version of an earlier attempt on retries
+	principal := principalName(ctx)
+	dedupKey := dedup.Key(principal, req.ClientId, req.RequestId)
+	rec, err := s.prepareWrite(dedupKey, key, req.Context, req.Version, false)
+	if err != nil {
+		return &kvstorepb.PutResponse{
+			Status:       kvstorepb.PutResponse_ERROR,
+			ErrorMessage: err.Error(),
+		}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if rec.put != nil {
+		s.log.Debug("Duplicate Put, returning recorded result",
+			logging.RequestID(req.RequestId), logging.ClientID(req.ClientId), logging.Key(req.Key))
+		return rec.put, nil
+	}
+	newVersion, ts := rec.version, rec.ts
+
+	// The value expires ttl_ms after its HLC timestamp, on every replica
+	var expiresAt *time.Time
+	if req.TtlMs > 0 {
+		expiresAt = protoToExpiry(ts.WallTime + req.TtlMs)
+	}
+
+	encodedVersion, err := encodeVersion(newVersion)
+	if err != nil {
+		return &kvstorepb.PutResponse{
+			Status:       kvstorepb.PutResponse_ERROR,
+			ErrorMessage: "invalid version: " + err.Error(),
+		}, nil
 	}
-	// Increment coordinator's counter to create new version
-	newVersion.Increment(s.nodeID)
 
 	// Convert replicas to string IDs for quorum coordinator
 	replicaIDs := make([]string, len(replicas))
+	replicaIDMap := make(map[string]string) // addr -> nodeID
 	for i, r := range replicas {
 		replicaIDs[i] = r.Addr
+		replicaIDMap[r.Addr] = r.ID
 	}
 
 	// Perform quorum write
@@ -86,7 +163,11 @@ func (s *Server) Put(ctx context.Context, req *kvstorepb.PutRequest) (*kvstorepb
 
 		// If replica is self, write locally
 		if replicaNode.ID == s.selfNode.ID {
-			s.store.Put(req.Key, req.Value, newVersion, false)
+			span := startStoreSpan(ctx, s.tracer, "Put", key)
+			applyOnce(s.applied, dedup.Key(principal, req.ClientId, req.RequestId, key), func() {
+				s.store.PutVersion(key, req.Value, newVersion, ts, false, expiresAt)
+			})
+			span.End()
 			return true, nil
 		}
 
@@ -97,12 +178,17 @@ func (s *Server) Put(ctx context.Context, req *kvstorepb.PutRequest) (*kvstorepb
 		}
 
 		replicaReq := &kvstorepb.ReplicaPutRequest{
-			Key:           req.Key,
-			Value:         req.Value,
-			Version:       vectorClockToProto(newVersion),
-			CoordinatorId: s.nodeID,
-			RequestId:     req.RequestId,
-			Deleted:       false,
+			Key:            key,
+			Value:          req.Value,
+			Version:        vectorClockToProto(newVersion), // For older replicas
+			EncodedVersion: encodedVersion,
+			CoordinatorId:  s.nodeID,
+			RequestId:      req.RequestId,
+			ClientId:       req.ClientId,
+			Principal:      principal,
+			Deleted:        false,
+			Timestamp:      timestampToProto(ts),
+			ExpiresAtMs:    expiryToProto(expiresAt),
 		}
 
 		resp, err := client.ReplicaPut(ctx, replicaReq)
@@ -113,25 +199,37 @@ func (s *Server) Put(ctx context.Context, req *kvstorepb.PutRequest) (*kvstorepb
 		return resp.Status == kvstorepb.ReplicaPutResponse_SUCCESS, nil
 	}
 
-	result := quorum.DoWrite(ctx, replicaIDs, requiredW, writeFn)
+	qctx, span := startQuorumSpan(ctx, s.tracer, "quorum.Write", key, requiredW, len(replicaIDs))
+	result := quorum.DoWriteWithOptions(qctx, replicaIDs, requiredW, writeFn, s.quorumOptions(req.ReplicaTimeoutMs))
+	endQuorumSpan(span, result.Success, result.ErrorMessage, result.Outcomes)
+	s.logQuorum(ctx, "Put", req.Key, req.RequestId, result.Success, result.Outcomes)
+	s.metrics.observe("put", result.Success, result.Outcomes, replicaIDMap)
+	acked := ackedNodeIDs(result.Outcomes, replicaIDMap)
 
 	if !result.Success {
 		return &kvstorepb.PutResponse{
-			Status:       kvstorepb.PutResponse_ERROR,
-			ErrorMessage: result.ErrorMessage,
+			Status:        kvstorepb.PutResponse_ERROR,
+			ErrorMessage:  result.ErrorMessage,
+			AckedReplicas: acked,
 		}, status.Error(codes.Unavailable, result.ErrorMessage)
 	}
 
-	return &kvstorepb.PutResponse{
-		Status:  kvstorepb.PutResponse_SUCCESS,
-		Version: vectorClockToProto(newVersion),
-	}, nil
+	resp := &kvstorepb.PutResponse{
+		Status:        kvstorepb.PutResponse_SUCCESS,
+		Version:       vectorClockToProto(newVersion),
+		Context:       s.issueContext(key, []repair.VersionedValue{{Version: newVersion}}),
+		AckedReplicas: acked,
+	}
+	// Record the result so that retries return it
+	done := *rec
+	done.put = resp
+	s.writes.Put(dedupKey, &done)
+	return resp, nil
 }
 
 // Get handles Get requests with quorum coordination.
 func (s *Server) Get(ctx context.Context, req *kvstorepb.GetRequest) (*kvstorepb.GetResponse, error) {
-	log.Printf("[%s] Get request: key=%s, client_id=%s, request_id=%s",
-		s.nodeID, req.Key, req.ClientId, req.RequestId)
+	s.logRequest(ctx, "Get", req.Namespace, req.Key, req.ClientId, req.RequestId)
 
 	if req.Key == "" {
 		return &kvstorepb.GetResponse{
@@ -139,22 +237,30 @@ func (s *Server) Get(ctx context.Context, req *kvstorepb.GetRequest) (*kvstorepb
 			ErrorMessage: "key cannot be empty",
 		}, nil
 	}
-
-	// Get replication factor and quorum sizes
-	rf := s.replicationFactor
-	if rf <= 0 {
-		rf = 3
+	if !storage.ValidKey(req.Key) {
+		return &kvstorepb.GetResponse{
+			Status:       kvstorepb.GetResponse_ERROR,
+			ErrorMessage: "key cannot contain NUL bytes",
+		}, status.Error(codes.InvalidArgument, "key cannot contain NUL bytes")
 	}
-	requiredR := int(req.ConsistencyR)
-	if requiredR <= 0 {
-		requiredR = s.defaultR
+
+	// Resolve the namespace's replication settings and stored key
+	ns, err := s.resolveNamespace(req.Namespace)
+	if err != nil {
+		return &kvstorepb.GetResponse{
+			Status:       kvstorepb.GetResponse_ERROR,
+			ErrorMessage: status.Convert(err).Message(),
+		}, err
 	}
+	rf := ns.ReplicationFactor
+	defaultR, _ := ns.Quorums()
+	key := storage.NamespacedKey(ns.Name, req.Key)
 
 	// Get ring (thread-safe if using dynamic membership)
 	rng := s.ringGetter()
 
 	// Get preference list (replicas)
-	replicas := replication.GetReplicasForKey(rng, req.Key, rf)
+	replicas := replication.GetReplicasForKey(rng, key, rf)
 	if len(replicas) == 0 {
 		return &kvstorepb.GetResponse{
 			Status:       kvstorepb.GetResponse_ERROR,
@@ -162,6 +268,25 @@ func (s *Server) Get(ctx context.Context, req *kvstorepb.GetRequest) (*kvstorepb
 		}, nil
 	}
 
+	// Forward to a replica if this node should not coordinate the key
+	if targets := s.forwardTargets(ctx, replicas); len(targets) > 0 {
+		call := func(ctx context.Context, client kvstorepb.KVStoreClient) (*kvstorepb.GetResponse, error) {
+			return client.Get(ctx, req)
+		}
+		if resp, forwarded, err := forward(ctx, s, req.Key, targets, call); forwarded {
+			return resp, err
+		}
+	}
+
+	// Resolve quorum size from the consistency level or raw R
+	requiredR, err := requiredReplicas(req.ConsistencyLevel, req.ConsistencyR, defaultR, rf, len(replicas))
+	if err != nil {
+		return &kvstorepb.GetResponse{
+			Status:       kvstorepb.GetResponse_ERROR,
+			ErrorMessage: status.Convert(err).Message(),
+		}, err
+	}
+
 	// Convert replicas to addresses for quorum coordinator
 	replicaAddrs := make([]string, len(replicas))
 	replicaIDMap := make(map[string]string) // addr -> nodeID
@@ -170,111 +295,95 @@ func (s *Server) Get(ctx context.Context, req *kvstorepb.GetRequest) (*kvstorepb
 		replicaIDMap[r.Addr] = r.ID
 	}
 
-	// Perform quorum read
-	readFn := func(ctx context.Context, replicaAddr string) ([]byte, interface{}, bool, error) {
-		// Find replica node
-		var replicaNode struct {
-			ID   string
-			Addr string
-		}
-		var found bool
-		for _, r := range replicas {
-			if r.Addr == replicaAddr {
-				replicaNode.ID = r.ID
-				replicaNode.Addr = r.Addr
-				found = true
-				break
-			}
+	// Perform quorum read: one replica returns the full value, the rest only
+	// version digests. Local reads are free and always full: a coordinating
+	// replica reads itself first and claims the full read up front, so every
+	// remote replica is asked for a digest.
+	opts := s.quorumOptions(req.ReplicaTimeoutMs)
+	var dataClaimed atomic.Bool
+	for _, r := range replicas {
+		if r.ID == s.selfNode.ID {
+			opts.Tracker = localFirst{LatencyTracker: opts.Tracker, self: r.Addr}
+			dataClaimed.Store(true)
 		}
+	}
+	readFn := func(ctx context.Context, replicaAddr string) ([]byte, interface{}, bool, error) {
+		id, found := replicaIDMap[replicaAddr]
 		if !found {
 			return nil, nil, false, fmt.Errorf("replica not found: %s", replicaAddr)
 		}
+		node := ring.Node{ID: id, Addr: replicaAddr}
 
-		// If replica is self, read locally
-		if replicaNode.ID == s.selfNode.ID {
-			vv := s.store.Get(req.Key)
-			if vv == nil {
-				return nil, nil, false, fmt.Errorf("not found")
-			}
-			return vv.Value, vv.Version, vv.Deleted, nil
-		}
-
-		// Otherwise, call internal RPC
-		client, err := s.clientMgr.GetInternalClient(replicaAddr)
-		if err != nil {
-			return nil, nil, false, fmt.Errorf("failed to get internal client: %w", err)
-		}
-
-		replicaReq := &kvstorepb.ReplicaGetRequest{
-			Key:           req.Key,
-			CoordinatorId: s.nodeID,
-			RequestId:     req.RequestId,
-		}
-
-		resp, err := client.ReplicaGet(ctx, replicaReq)
-		if err != nil {
-			return nil, nil, false, err
-		}
-
-		if resp.Status == kvstorepb.ReplicaGetResponse_NOT_FOUND {
-			return nil, nil, false, fmt.Errorf("not found")
-		}
-
-		if resp.Status != kvstorepb.ReplicaGetResponse_SUCCESS {
-			return nil, nil, false, fmt.Errorf("replica error: %s", resp.ErrorMessage)
+		full := id == s.selfNode.ID || dataClaimed.CompareAndSwap(false, true)
+		value, version, deleted, err := s.readReplica(ctx, key, req.RequestId, node, !full)
+		if err != nil && full && id != s.selfNode.ID {
+			// Let the next replica serve the value instead
+			dataClaimed.Store(false)
 		}
-
-		version := protoToVectorClock(resp.Value.Version)
-		deleted := resp.Value.Deleted
-		return resp.Value.Value, version, deleted, nil
+		return value, version, deleted, err
 	}
 
-	result := quorum.DoRead(ctx, replicaAddrs, requiredR, readFn)
+	// Contact R replicas ranked by observed latency, hedging slow reads
+	qctx, span := startQuorumSpan(ctx, s.tracer, "quorum.Read", key, requiredR, len(replicaAddrs))
+	result := quorum.DoReadWithOptions(qctx, replicaAddrs, requiredR, readFn, opts)
+	endQuorumSpan(span, result.Success, result.ErrorMessage, result.Outcomes)
+	s.logQuorum(ctx, "Get", req.Key, req.RequestId, result.Success, result.Outcomes)
+	s.metrics.observe("get", result.Success, result.Outcomes, replicaIDMap)
+	acked := ackedNodeIDs(result.Outcomes, replicaIDMap)
 
 	if !result.Success {
 		return &kvstorepb.GetResponse{
-			Status:       kvstorepb.GetResponse_ERROR,
-			ErrorMessage: result.ErrorMessage,
+			Status:        kvstorepb.GetResponse_ERROR,
+			ErrorMessage:  result.ErrorMessage,
+			AckedReplicas: acked,
 		}, status.Error(codes.Unavailable, result.ErrorMessage)
 	}
 
 	// Reconcile versions using proper algorithm
 	if len(result.Values) == 0 {
 		return &kvstorepb.GetResponse{
-			Status: kvstorepb.GetResponse_NOT_FOUND,
+			Status:        kvstorepb.GetResponse_NOT_FOUND,
+			AckedReplicas: acked,
 		}, nil
 	}
 
-	// Convert ReadValue to repair.VersionedValue for reconciliation
-	// Note: quorum.DoRead doesn't preserve replica addresses, so we'll use indices
+	// Convert ReadValue to repair.VersionedValue for reconciliation,
+	// tracking which replica returned each version
 	repairValues := make([]repair.VersionedValue, 0, len(result.Values))
 	replicaIDs := make([]string, 0, len(result.Values))
 
-	for i, rv := range result.Values {
-		vc, ok := rv.Version.(clock.VectorClock)
+	for _, rv := range result.Values {
+		rver, ok := rv.Version.(replicaVersion)
 		if !ok {
 			continue
 		}
 		repairValues = append(repairValues, repair.VersionedValue{
-			Value:   rv.Value,
-			Version: vc,
-			Deleted: rv.Deleted,
+			Value:     rv.Value,
+			Version:   rver.clock,
+			Timestamp: rver.timestamp,
+			Deleted:   rv.Deleted,
+			ExpiresAt: rver.expiresAt,
 		})
-		// Use index to map back to replica (approximate, but sufficient for reconciliation)
-		if i < len(replicas) {
-			replicaIDs = append(replicaIDs, replicas[i].ID)
-		} else {
-			replicaIDs = append(replicaIDs, fmt.Sprintf("replica-%d", i))
-		}
+		replicaIDs = append(replicaIDs, replicaIDMap[rv.ReplicaID])
 	}
 
-	// Use reconcile algorithm to compute maximal set
-	reconcileResult := repair.Reconcile(repairValues, replicaIDs)
+	// Use reconcile algorithm to compute maximal set (collapsed to one winner under LWW)
+	reconcileResult := repair.ReconcileWithPolicy(repairValues, replicaIDs, s.policies.Load().For(key))
+
+	// Digest reads return no value: fetch the winners' values in full
+	if err := s.fillWinnerValues(ctx, key, req.RequestId, reconcileResult.Winners, result.Values, replicaIDMap); err != nil {
+		return &kvstorepb.GetResponse{
+			Status:        kvstorepb.GetResponse_ERROR,
+			ErrorMessage:  err.Error(),
+			AckedReplicas: acked,
+		}, status.Error(codes.Unavailable, err.Error())
+	}
 
 	// Handle results
 	if reconcileResult.IsNotFound() {
 		return &kvstorepb.GetResponse{
-			Status: kvstorepb.GetResponse_NOT_FOUND,
+			Status:        kvstorepb.GetResponse_NOT_FOUND,
+			AckedReplicas: acked,
 		}, nil
 	}
 
@@ -283,69 +392,63 @@ func (s *Server) Get(ctx context.Context, req *kvstorepb.GetRequest) (*kvstorepb
 		winner := reconcileResult.Winners[0]
 
 		// Trigger read repair if there are stale replicas (fire-and-forget)
-		if len(reconcileResult.Stale) > 0 {
-			// Build replica ID to address mapping
-			replicaIDToAddr := make(map[string]string)
-			for i, replica := range replicas {
-				if i < len(replicaIDs) {
-					replicaIDToAddr[replicaIDs[i]] = replica.Addr
-				}
-			}
-
-			// Trigger async read repair
-			s.readRepairer.Repair(context.Background(), req.Key, reconcileResult.Winners, reconcileResult.Stale, replicaIDToAddr)
-		}
+		s.triggerReadRepair(ctx, key, reconcileResult, result.Outcomes, replicas)
 
-		if winner.Deleted {
-			// Tombstone - return as NOT_FOUND
+		if winner.Deleted || ns.Expired(winner.Timestamp, time.Now()) {
+			// Tombstone or expired value - return as NOT_FOUND, with a
+			// context so a later write supersedes it
 			return &kvstorepb.GetResponse{
-				Status: kvstorepb.GetResponse_NOT_FOUND,
+				Status:        kvstorepb.GetResponse_NOT_FOUND,
+				Context:       s.issueContext(key, reconcileResult.Winners),
+				AckedReplicas: acked,
 			}, nil
 		}
 		return &kvstorepb.GetResponse{
 			Status: kvstorepb.GetResponse_SUCCESS,
 			Value: &kvstorepb.VersionedValue{
-				Value:   winner.Value,
-				Version: vectorClockToProto(winner.Version),
-				Deleted: winner.Deleted,
+				Value:       winner.Value,
+				Version:     vectorClockToProto(winner.Version),
+				Deleted:     winner.Deleted,
+				Timestamp:   timestampToProto(winner.Timestamp),
+				ExpiresAtMs: expiryToProto(winner.ExpiresAt),
 			},
+			Context:       s.issueContext(key, reconcileResult.Winners),
+			AckedReplicas: acked,
 		}, nil
 	}
 
-	// Multiple winners (conflicts) - return siblings
+	// Multiple winners (conflicts) - return siblings, with expired values
+	// as tombstones
+	now := time.Now()
 	conflicts := make([]*kvstorepb.VersionedValue, 0, len(reconcileResult.Winners))
 	for _, winner := range reconcileResult.Winners {
-		conflicts = append(conflicts, &kvstorepb.VersionedValue{
-			Value:   winner.Value,
-			Version: vectorClockToProto(winner.Version),
-			Deleted: winner.Deleted,
-		})
+		sibling := &kvstorepb.VersionedValue{
+			Value:       winner.Value,
+			Version:     vectorClockToProto(winner.Version),
+			Deleted:     winner.Deleted,
+			Timestamp:   timestampToProto(winner.Timestamp),
+			ExpiresAtMs: expiryToProto(winner.ExpiresAt),
+		}
+		if ns.Expired(winner.Timestamp, now) {
+			sibling.Value, sibling.Deleted = nil, true
+		}
+		conflicts = append(conflicts, sibling)
 	}
 
 	// Trigger read repair if there are stale replicas (fire-and-forget)
-	if len(reconcileResult.Stale) > 0 {
-		// Build replica ID to address mapping
-		replicaIDToAddr := make(map[string]string)
-		for i, replica := range replicas {
-			if i < len(replicaIDs) {
-				replicaIDToAddr[replicaIDs[i]] = replica.Addr
-			}
-		}
-
-		// Trigger async read repair
-		s.readRepairer.Repair(context.Background(), req.Key, reconcileResult.Winners, reconcileResult.Stale, replicaIDToAddr)
-	}
+	s.triggerReadRepair(ctx, key, reconcileResult, result.Outcomes, replicas)
 
 	return &kvstorepb.GetResponse{
-		Status:    kvstorepb.GetResponse_SUCCESS,
-		Conflicts: conflicts,
+		Status:        kvstorepb.GetResponse_SUCCESS,
+		Conflicts:     conflicts,
+		Context:       s.issueContext(key, reconcileResult.Winners),
+		AckedReplicas: acked,
 	}, nil
 }
 
 // Delete handles Delete requests with quorum coordination.
 func (s *Server) Delete(ctx context.Context, req *kvstorepb.DeleteRequest) (*kvstorepb.DeleteResponse, error) {
-	log.Printf("[%s] Delete request: key=%s, client_id=%s, request_id=%s",
-		s.nodeID, req.Key, req.ClientId, req.RequestId)
+	s.logRequest(ctx, "Delete", req.Namespace, req.Key, req.ClientId, req.RequestId)
 
 	if req.Key == "" {
 		return &kvstorepb.DeleteResponse{
@@ -353,22 +456,30 @@ func (s *Server) Delete(ctx context.Context, req *kvstorepb.DeleteRequest) (*kvs
 			ErrorMessage: "key cannot be empty",
 		}, nil
 	}
-
-	// Get replication factor and quorum sizes
-	rf := s.replicationFactor
-	if rf <= 0 {
-		rf = 3
+	if !storage.ValidKey(req.Key) {
+		return &kvstorepb.DeleteResponse{
+			Status:       kvstorepb.DeleteResponse_ERROR,
+			ErrorMessage: "key cannot contain NUL bytes",
+		}, status.Error(codes.InvalidArgument, "key cannot contain NUL bytes")
 	}
-	requiredW := int(req.ConsistencyW)
-	if requiredW <= 0 {
-		requiredW = s.defaultW
+
+	// Resolve the namespace's replication settings and stored key
+	ns, err := s.resolveNamespace(req.Namespace)
+	if err != nil {
+		return &kvstorepb.DeleteResponse{
+			Status:       kvstorepb.DeleteResponse_ERROR,
+			ErrorMessage: status.Convert(err).Message(),
+		}, err
 	}
+	rf := ns.ReplicationFactor
+	_, defaultW := ns.Quorums()
+	key := storage.NamespacedKey(ns.Name, req.Key)
 
 	// Get ring (thread-safe if using dynamic membership)
 	rng := s.ringGetter()
 
 	// Get preference list (replicas)
-	replicas := replication.GetReplicasForKey(rng, req.Key, rf)
+	replicas := replication.GetReplicasForKey(rng, key, rf)
 	if len(replicas) == 0 {
 		return &kvstorepb.DeleteResponse{
 			Status:       kvstorepb.DeleteResponse_ERROR,
@@ -376,17 +487,56 @@ func (s *Server) Delete(ctx context.Context, req *kvstorepb.DeleteRequest) (*kvs
 		}, nil
 	}
 
-	// Prepare version
-	newVersion := clock.New()
-	if req.Version != nil {
-		newVersion = protoToVectorClock(req.Version)
+	// Forward to a replica if this node should not coordinate the key
+	if targets := s.forwardTargets(ctx, replicas); len(targets) > 0 {
+		call := func(ctx context.Context, client kvstorepb.KVStoreClient) (*kvstorepb.DeleteResponse, error) {
+			return client.Delete(ctx, req)
+		}
+		if resp, forwarded, err := forward(ctx, s, req.Key, targets, call); forwarded {
+			return resp, err
+		}
+	}
+
+	// Resolve quorum size from the consistency level or raw W
+	requiredW, err := requiredReplicas(req.ConsistencyLevel, req.ConsistencyW, defaultW, rf, len(replicas))
+	if err != nil {
+		return &kvstorepb.DeleteResponse{
+			Status:       kvstorepb.DeleteResponse_ERROR,
+			ErrorMessage: status.Convert(err).Message(),
+		}, err
+	}
+
+	// Prepare version, reusing the version of an earlier attempt on retries
+	principal := principalName(ctx)
+	dedupKey := dedup.Key(principal, req.ClientId, req.RequestId)
+	rec, err := s.prepareWrite(dedupKey, key, req.Context, req.Version, true)
+	if err != nil {
+		return &kvstorepb.DeleteResponse{
+			Status:       kvstorepb.DeleteResponse_ERROR,
+			ErrorMessage: err.Error(),
+		}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if rec.del != nil {
+		s.log.Debug("Duplicate Delete, returning recorded result",
+			logging.RequestID(req.RequestId), logging.ClientID(req.ClientId), logging.Key(req.Key))
+		return rec.del, nil
+	}
+	newVersion, ts := rec.version, rec.ts
+
+	encodedVersion, err := encodeVersion(newVersion)
+	if err != nil {
+		return &kvstorepb.DeleteResponse{
+			Status:       kvstorepb.DeleteResponse_ERROR,
+			ErrorMessage: "invalid version: " + err.Error(),
+		}, nil
 	}
-	newVersion.Increment(s.nodeID)
 
 	// Convert replicas to string IDs for quorum coordinator
 	replicaIDs := make([]string, len(replicas))
+	replicaIDMap := make(map[string]string) // addr -> nodeID
 	for i, r := range replicas {
 		replicaIDs[i] = r.Addr
+		replicaIDMap[r.Addr] = r.ID
 	}
 
 	// Perform quorum write (tombstone)
@@ -411,7 +561,11 @@ func (s *Server) Delete(ctx context.Context, req *kvstorepb.DeleteRequest) (*kvs
 
 		// If replica is self, write tombstone locally
 		if replicaNode.ID == s.selfNode.ID {
-			s.store.Put(req.Key, nil, newVersion, true) // deleted=true
+			span := startStoreSpan(ctx, s.tracer, "Delete", key)
+			applyOnce(s.applied, dedup.Key(principal, req.ClientId, req.RequestId, key), func() {
+				s.store.PutVersion(key, nil, newVersion, ts, true, nil) // deleted=true
+			})
+			span.End()
 			return true, nil
 		}
 
@@ -422,12 +576,16 @@ func (s *Server) Delete(ctx context.Context, req *kvstorepb.DeleteRequest) (*kvs
 		}
 
 		replicaReq := &kvstorepb.ReplicaPutRequest{
-			Key:           req.Key,
-			Value:         nil,
-			Version:       vectorClockToProto(newVersion),
-			CoordinatorId: s.nodeID,
-			RequestId:     req.RequestId,
-			Deleted:       true,
+			Key:            key,
+			Value:          nil,
+			Version:        vectorClockToProto(newVersion), // For older replicas
+			EncodedVersion: encodedVersion,
+			CoordinatorId:  s.nodeID,
+			RequestId:      req.RequestId,
+			ClientId:       req.ClientId,
+			Principal:      principal,
+			Deleted:        true,
+			Timestamp:      timestampToProto(ts),
 		}
 
 		resp, err := client.ReplicaPut(ctx, replicaReq)
@@ -438,17 +596,69 @@ func (s *Server) Delete(ctx context.Context, req *kvstorepb.DeleteRequest) (*kvs
 		return resp.Status == kvstorepb.ReplicaPutResponse_SUCCESS, nil
 	}
 
-	result := quorum.DoWrite(ctx, replicaIDs, requiredW, writeFn)
+	qctx, span := startQuorumSpan(ctx, s.tracer, "quorum.Write", key, requiredW, len(replicaIDs))
+	result := quorum.DoWriteWithOptions(qctx, replicaIDs, requiredW, writeFn, s.quorumOptions(req.ReplicaTimeoutMs))
+	endQuorumSpan(span, result.Success, result.ErrorMessage, result.Outcomes)
+	s.logQuorum(ctx, "Delete", req.Key, req.RequestId, result.Success, result.Outcomes)
+	s.metrics.observe("delete", result.Success, result.Outcomes, replicaIDMap)
+	acked := ackedNodeIDs(result.Outcomes, replicaIDMap)
 
 	if !result.Success {
 		return &kvstorepb.DeleteResponse{
-			Status:       kvstorepb.DeleteResponse_ERROR,
-			ErrorMessage: result.ErrorMessage,
+			Status:        kvstorepb.DeleteResponse_ERROR,
+			ErrorMessage:  result.ErrorMessage,
+			AckedReplicas: acked,
 		}, status.Error(codes.Unavailable, result.ErrorMessage)
 	}
 
-	return &kvstorepb.DeleteResponse{
-		Status:  kvstorepb.DeleteResponse_SUCCESS,
-		Version: vectorClockToProto(newVersion),
-	}, nil
+	resp := &kvstorepb.DeleteResponse{
+		Status:        kvstorepb.DeleteResponse_SUCCESS,
+		Version:       vectorClockToProto(newVersion),
+		Context:       s.issueContext(key, []repair.VersionedValue{{Version: newVersion}}),
+		AckedReplicas: acked,
+	}
+	// Record the result so that retries return it
+	done := *rec
+	done.del = resp
+	s.writes.Put(dedupKey, &done)
+	return resp, nil
+}
+
+// ackedNodeIDs returns the node IDs of the replicas that succeeded, given
+// outcomes keyed by replica address.
+func ackedNodeIDs(outcomes []quorum.ReplicaOutcome, addrToID map[string]string) []string {
+	acked := quorum.Acked(outcomes)
+	for i, addr := range acked {
+		acked[i] = addrToID[addr]
+	}
+	return acked
+}
+
+// triggerReadRepair repairs (fire-and-forget) the replicas that returned a
+// stale version, plus replicas that answered they hold no version at all
+// while a winner exists. The repair outlives the request but is linked to
+// its trace.
+func (s *Server) triggerReadRepair(ctx context.Context, key string, reconciled repair.ReconcileResult, outcomes []quorum.ReplicaOutcome, replicas []ring.Node) {
+	replicaIDToAddr := make(map[string]string, len(replicas))
+	addrToID := make(map[string]string, len(replicas))
+	for _, r := range replicas {
+		replicaIDToAddr[r.ID] = r.Addr
+		addrToID[r.Addr] = r.ID
+	}
+
+	stale := reconciled.Stale
+	for _, o := range outcomes {
+		if o.Class != quorum.ErrorNotFound {
+			continue
+		}
+		if stale == nil {
+			stale = make(map[string]repair.VersionedValue)
+		}
+		stale[addrToID[o.ReplicaID]] = repair.VersionedValue{}
+	}
+	if len(stale) == 0 {
+		return
+	}
+
+	s.readRepairer.Repair(context.WithoutCancel(ctx), key, reconciled.Winners, stale, replicaIDToAddr)
 }
@@ -0,0 +1,51 @@
+package node
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"kvstore/internal/clock"
+	"kvstore/internal/ring"
+)
+
+func TestNode_Snapshot(t *testing.T) {
+	nodes := []ring.Node{{ID: "n1", Addr: "localhost:50051"}}
+	n := NewNode("n1", "localhost:50051", nodes, nil, DefaultGossipConfig(), 16, 1, 1, 1, nil, []byte("secret"))
+	if _, _, _, err := n.Snapshot(); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Snapshot() without a directory = %v, want FailedPrecondition", err)
+	}
+
+	now := clock.Timestamp{WallTime: time.Now().UnixMilli(), NodeID: "n1"}
+	n.store.Put("a", []byte("1"), nil, now, false)
+	n.store.Delete("b", nil, now)
+	dir := filepath.Join(t.TempDir(), "snapshots")
+	n.SetSnapshotDir(dir)
+	path, records, size, err := n.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() failed: %v", err)
+	}
+	if filepath.Dir(path) != dir || records != 2 {
+		t.Errorf("Snapshot() = %s, %d records; want a file in %s with 2 records", path, records, dir)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != size {
+		t.Errorf("Snapshot file size = %v (%v), want %d", info, err, size)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected only the snapshot file in %s, got %d entries", dir, len(entries))
+	}
+
+	restored := NewNode("n1", "localhost:50051", nodes, nil, DefaultGossipConfig(), 16, 1, 1, 1, nil, []byte("secret"))
+	if got, err := restored.RestoreSnapshot(path); err != nil || got != 2 {
+		t.Fatalf("RestoreSnapshot() = %d, %v; want 2 records", got, err)
+	}
+	if st := restored.store.Stats(); st.Keys != 1 || st.Tombstones != 1 {
+		t.Errorf("Restored store has %d keys, %d tombstones; want 1, 1", st.Keys, st.Tombstones)
+	}
+	if _, err := restored.RestoreSnapshot(filepath.Join(dir, "missing.snap")); err == nil {
+		t.Error("Expected RestoreSnapshot of a missing file to fail")
+	}
+}
@@ -0,0 +1,213 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/gossip"
+	"kvstore/internal/storage"
+)
+
+const (
+	// DefaultTombstoneGrace is how long Compact keeps tombstones unless told
+	// otherwise. It must exceed the time a replica can miss a delete and be
+	// repaired, or compacted deletes can be undone by read repair.
+	DefaultTombstoneGrace = 24 * time.Hour
+
+	clusterStatusTimeout = 2 * time.Second // Per member
+	repairPageSize       = 1000            // Keys listed from the store at once
+)
+
+// Status returns the status of the node.
+func (n *Node) Status() *kvstorepb.NodeStatus {
+	st := n.store.Stats()
+	ringNodes := n.ringSize()
+	n.runtimeMu.Lock()
+	server, version := n.server, n.configVersion
+	n.runtimeMu.Unlock()
+
+	s := &kvstorepb.NodeStatus{
+		NodeId:        n.nodeID,
+		Addr:          n.listenAddr,
+		UptimeMs:      time.Since(n.startTime).Milliseconds(),
+		Membership:    "static",
+		Members:       int32(ringNodes),
+		AliveMembers:  int32(ringNodes),
+		RingEpoch:     n.ringEpoch.Load(),
+		RingNodes:     int32(ringNodes),
+		Keys:          int64(st.Keys),
+		Tombstones:    int64(st.Tombstones),
+		StoreBytes:    st.Bytes,
+		ConfigVersion: version,
+	}
+	if n.membership != nil {
+		s.Membership = "gossip"
+		s.Members = int32(len(n.membership.Snapshot()))
+		s.AliveMembers = int32(len(n.membership.AliveNodes()))
+	}
+	if server != nil {
+		s.RepairBacklog = server.RepairPending()
+	}
+	return s
+}
+
+// members returns the members of the cluster as this node sees them. With
+// static membership, every ring node is considered alive.
+func (n *Node) members() []*gossip.Member {
+	if n.membership != nil {
+		return n.membership.Snapshot()
+	}
+	n.ringMu.RLock()
+	defer n.ringMu.RUnlock()
+	var members []*gossip.Member
+	for _, node := range n.ring.GetNodes() {
+		members = append(members, &gossip.Member{ID: node.ID, Addr: node.Addr, Status: gossip.Alive})
+	}
+	return members
+}
+
+// ClusterStatus collects the status of every member that is not dead, and
+// sums the totals of those that answered.
+func (n *Node) ClusterStatus(ctx context.Context) *kvstorepb.GetClusterStatusResponse {
+	members := n.members()
+	sort.Slice(members, func(i, j int) bool { return members[i].ID < members[j].ID })
+
+	resp := &kvstorepb.GetClusterStatusResponse{
+		ResponderId: n.nodeID,
+		Members:     make([]*kvstorepb.ClusterMemberStatus, len(members)),
+		Healthy:     true,
+	}
+	var wg sync.WaitGroup
+	for i, m := range members {
+		ms := &kvstorepb.ClusterMemberStatus{NodeId: m.ID, Addr: m.Addr, MemberStatus: m.Status.ToProto()}
+		resp.Members[i] = ms
+		switch {
+		case m.ID == n.nodeID:
+			ms.Status = n.Status()
+		case m.Status == gossip.Dead:
+			ms.Error = "member is dead"
+		case m.Status == gossip.Left:
+			ms.Error = "member left the cluster"
+		default:
+			wg.Add(1)
+			go func(addr string) {
+				defer wg.Done()
+				s, err := n.peerStatus(ctx, addr)
+				if err != nil {
+					ms.Error = status.Convert(err).Message()
+					return
+				}
+				ms.Status = s
+			}(m.Addr)
+		}
+	}
+	wg.Wait()
+
+	for _, ms := range resp.Members {
+		if ms.MemberStatus == kvstorepb.MemberStatus_LEFT && ms.NodeId != n.nodeID {
+			continue // Decommissioned members do not count toward health
+		}
+		if ms.Status == nil || ms.MemberStatus != kvstorepb.MemberStatus_ALIVE {
+			resp.Healthy = false
+		}
+		if s := ms.Status; s != nil {
+			resp.Keys += s.Keys
+			resp.Tombstones += s.Tombstones
+			resp.StoreBytes += s.StoreBytes
+			resp.RepairBacklog += s.RepairBacklog
+		}
+	}
+	return resp
+}
+
+// peerStatus requests the status of the node at addr.
+func (n *Node) peerStatus(ctx context.Context, addr string) (*kvstorepb.NodeStatus, error) {
+	client, err := n.clientMgr.GetInternalClient(addr)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, clusterStatusTimeout)
+	defer cancel()
+	resp, err := client.GetNodeStatus(ctx, &kvstorepb.GetNodeStatusRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Status, nil
+}
+
+// Compact removes values past the TTL of their namespace and the tombstones
+// written more than grace ago (DefaultTombstoneGrace if grace is not
+// positive) from the local store.
+func (n *Node) Compact(grace time.Duration) storage.CompactStats {
+	if grace <= 0 {
+		grace = DefaultTombstoneGrace
+	}
+	st := n.store.Compact(time.Now().Add(-grace), n.namespaceTTL)
+	n.log.Info("Compacted store", slog.Int("expired", st.Expired), slog.Int("tombstones", st.Tombstones),
+		slog.Duration("tombstone_grace", grace))
+	return st
+}
+
+// namespaceTTL returns the TTL of a namespace, or 0 if it has none or does
+// not exist.
+func (n *Node) namespaceTTL(name string) time.Duration {
+	ns, _ := n.namespaces.Get(name)
+	return ns.DefaultTTL
+}
+
+// Repair reads every key the node stores in a namespace with a prefix,
+// including deleted keys, at consistency ALL, so that read repair brings all
+// of their replicas up to date and spreads deletes to replicas that missed
+// them. It returns the number of keys read and a "key: error" entry for each
+// key that could not be. Errors are gRPC status errors.
+func (n *Node) Repair(ctx context.Context, namespace, prefix string) (checked int, failed []string, err error) {
+	n.runtimeMu.Lock()
+	server := n.server
+	n.runtimeMu.Unlock()
+	if server == nil {
+		return 0, nil, status.Error(codes.FailedPrecondition, "node is not serving")
+	}
+	if _, err := server.resolveNamespace(namespace); err != nil {
+		return 0, nil, err
+	}
+
+	start, startAfter := storage.NamespacedKey(namespace, prefix), ""
+	for {
+		keys, more := n.store.KeysWithTombstones(start, startAfter, repairPageSize)
+		for _, stored := range keys {
+			if err := ctx.Err(); err != nil {
+				return checked, failed, err
+			}
+			_, key := storage.SplitKey(stored)
+			resp, err := server.Get(ctx, &kvstorepb.GetRequest{
+				Namespace:        namespace,
+				Key:              key,
+				ClientId:         "admin-repair",
+				ConsistencyLevel: kvstorepb.ConsistencyLevel_ALL,
+			})
+			if err == nil && resp.Status == kvstorepb.GetResponse_ERROR {
+				err = errors.New(resp.ErrorMessage)
+			}
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("%s: %s", key, status.Convert(err).Message()))
+				continue
+			}
+			checked++
+		}
+		if !more {
+			break
+		}
+		startAfter = keys[len(keys)-1]
+	}
+	n.log.Info("Repair finished", slog.String("namespace", namespace), slog.String("prefix", prefix),
+		slog.Int("checked", checked), slog.Int("failed", len(failed)))
+	return checked, failed, nil
+}
@@ -0,0 +1,64 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"kvstore/internal/clock"
+	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/namespace"
+	"kvstore/internal/ring"
+	"kvstore/internal/storage"
+)
+
+func TestNode_Status(t *testing.T) {
+	nodes := []ring.Node{{ID: "n1", Addr: "localhost:50051"}}
+	n := NewNode("n1", "localhost:50051", nodes, nil, DefaultGossipConfig(), 16, 1, 1, 1, nil, []byte("secret"))
+
+	old := clock.Timestamp{WallTime: time.Now().Add(-48 * time.Hour).UnixMilli(), NodeID: "n1"}
+	now := clock.Timestamp{WallTime: time.Now().UnixMilli(), NodeID: "n1"}
+	n.store.Put("a", []byte("1"), nil, now, false)
+	n.store.Put("b", []byte("2"), nil, now, false)
+	n.store.Delete("old", nil, old)
+	n.store.Delete("new", nil, now)
+
+	s := n.Status()
+	if s.NodeId != "n1" || s.Membership != "static" || s.Members != 1 || s.AliveMembers != 1 || s.RingNodes != 1 {
+		t.Errorf("Status() membership = %+v, want static single node", s)
+	}
+	if s.Keys != 2 || s.Tombstones != 2 || s.StoreBytes == 0 {
+		t.Errorf("Status() store = %d keys, %d tombstones, %d bytes; want 2, 2, >0", s.Keys, s.Tombstones, s.StoreBytes)
+	}
+	if s.RingEpoch != 1 || s.ConfigVersion != 1 {
+		t.Errorf("Status() ring epoch %d, config version %d; want 1, 1", s.RingEpoch, s.ConfigVersion)
+	}
+
+	cs := n.ClusterStatus(context.Background())
+	if !cs.Healthy || len(cs.Members) != 1 || cs.Members[0].Status == nil {
+		t.Fatalf("ClusterStatus() = %+v, want one healthy member with status", cs)
+	}
+	if cs.Members[0].MemberStatus != kvstorepb.MemberStatus_ALIVE || cs.Keys != 2 || cs.Tombstones != 2 {
+		t.Errorf("ClusterStatus() = %+v, want alive member and totals of 2 keys, 2 tombstones", cs)
+	}
+
+	// Only the tombstone older than the default grace and the value past its
+	// namespace TTL are removed
+	n.namespaces.Merge(namespace.Namespace{Name: "cache", DefaultTTL: time.Hour, UpdatedAt: now})
+	n.store.Put(storage.NamespacedKey("cache", "c"), []byte("3"), nil, old, false)
+	if st := n.Compact(0); st.Tombstones != 1 || st.Expired != 1 {
+		t.Errorf("Compact(0) = %+v, want 1 tombstone and 1 expired value", st)
+	}
+	if s := n.Status(); s.Keys != 2 || s.Tombstones != 1 {
+		t.Errorf("Status() after compaction = %d keys, %d tombstones; want 2, 1", s.Keys, s.Tombstones)
+	}
+
+	if _, _, err := n.Repair(context.Background(), "", ""); err == nil {
+		t.Error("Expected Repair to fail on a node that is not serving")
+	}
+	if _, _, err := n.Decommission(context.Background()); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Decommission() with a static ring = %v, want FailedPrecondition", err)
+	}
+}
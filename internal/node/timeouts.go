@@ -0,0 +1,113 @@
+package node
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"kvstore/internal/quorum"
+)
+
+// TimeoutMode selects how per-replica RPC timeouts are chosen.
+type TimeoutMode int
+
+const (
+	// TimeoutFixed uses the same timeout for every replica.
+	TimeoutFixed TimeoutMode = iota
+	// TimeoutAdaptive derives each replica's timeout from its recent latency.
+	TimeoutAdaptive
+)
+
+// String returns the name of the mode.
+func (m TimeoutMode) String() string {
+	switch m {
+	case TimeoutFixed:
+		return "fixed"
+	case TimeoutAdaptive:
+		return "adaptive"
+	default:
+		return fmt.Sprintf("TimeoutMode(%d)", int(m))
+	}
+}
+
+// ParseTimeoutMode parses "fixed" or "adaptive" (case-insensitive).
+func ParseTimeoutMode(s string) (TimeoutMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "fixed":
+		return TimeoutFixed, nil
+	case "adaptive":
+		return TimeoutAdaptive, nil
+	default:
+		return TimeoutFixed, fmt.Errorf("unknown timeout mode %q (expected fixed or adaptive)", s)
+	}
+}
+
+// TimeoutConfig configures per-replica RPC timeouts. In adaptive mode a
+// replica's timeout is its Percentile latency, clamped to [Min, Max]; replicas
+// without enough latency samples use Fixed. Incoming gRPC deadlines always
+// bound the timeout.
+type TimeoutConfig struct {
+	Mode       TimeoutMode
+	Fixed      time.Duration // Timeout in fixed mode, and for unmeasured replicas
+	Min        time.Duration // Lower bound in adaptive mode
+	Max        time.Duration // Upper bound in adaptive mode
+	Percentile float64       // Latency percentile used in adaptive mode (0 < p < 1)
+}
+
+// DefaultTimeoutConfig returns a fixed 2s timeout, with adaptive bounds of
+// 50ms..2s on the p99 latency.
+func DefaultTimeoutConfig() TimeoutConfig {
+	return TimeoutConfig{
+		Mode:       TimeoutFixed,
+		Fixed:      quorum.DefaultPerReplicaTimeout,
+		Min:        50 * time.Millisecond,
+		Max:        quorum.DefaultPerReplicaTimeout,
+		Percentile: 0.99,
+	}
+}
+
+// Validate returns an error if the configuration is inconsistent.
+func (c TimeoutConfig) Validate() error {
+	if c.Fixed <= 0 {
+		return fmt.Errorf("fixed replica timeout must be positive, got %v", c.Fixed)
+	}
+	if c.Mode == TimeoutAdaptive {
+		if c.Min <= 0 || c.Max < c.Min {
+			return fmt.Errorf("adaptive replica timeout bounds must satisfy 0 < min <= max, got %v..%v", c.Min, c.Max)
+		}
+		if c.Percentile <= 0 || c.Percentile >= 1 {
+			return fmt.Errorf("adaptive replica timeout percentile must be in (0, 1), got %v", c.Percentile)
+		}
+	}
+	return nil
+}
+
+// quorumOptions returns the quorum options for a request. A positive
+// requestTimeoutMs overrides the node's per-replica timeout for that request.
+func (s *Server) quorumOptions(requestTimeoutMs int32) quorum.Options {
+	opts := quorum.Options{Tracker: s.clientMgr}
+
+	cfg := s.runtimeConfig().Timeouts
+	switch {
+	case requestTimeoutMs > 0:
+		d := time.Duration(requestTimeoutMs) * time.Millisecond
+		opts.Timeout = func(string) time.Duration { return d }
+	case cfg.Mode == TimeoutAdaptive:
+		opts.Timeout = func(addr string) time.Duration {
+			d, ok := s.clientMgr.LatencyPercentile(addr, cfg.Percentile)
+			if !ok {
+				return cfg.Fixed
+			}
+			if d < cfg.Min {
+				return cfg.Min
+			}
+			if d > cfg.Max {
+				return cfg.Max
+			}
+			return d
+		}
+	default:
+		opts.Timeout = func(string) time.Duration { return cfg.Fixed }
+	}
+	return opts
+}
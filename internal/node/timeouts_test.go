@@ -0,0 +1,63 @@
+package node
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQuorumOptions_Adaptive(t *testing.T) {
+	cm := NewClientManager()
+	for i := 0; i < 100; i++ {
+		cm.Observe("fast:1", 100*time.Microsecond, nil)
+		cm.Observe("mid:1", 100*time.Millisecond, nil)
+		cm.Observe("slow:1", 5*time.Second, errors.New("timeout"))
+	}
+	s := &Server{clientMgr: cm}
+	s.SetTimeoutConfig(TimeoutConfig{
+		Mode:       TimeoutAdaptive,
+		Fixed:      time.Second,
+		Min:        10 * time.Millisecond,
+		Max:        500 * time.Millisecond,
+		Percentile: 0.99,
+	})
+
+	timeout := s.quorumOptions(0).Timeout
+	if got := timeout("fast:1"); got != 10*time.Millisecond {
+		t.Errorf("Expected fast replica clamped to min 10ms, got %v", got)
+	}
+	if got := timeout("mid:1"); got < 100*time.Millisecond || got > 500*time.Millisecond {
+		t.Errorf("Expected mid replica timeout from its p99 latency, got %v", got)
+	}
+	if got := timeout("slow:1"); got != 500*time.Millisecond {
+		t.Errorf("Expected slow replica clamped to max 500ms, got %v", got)
+	}
+	if got := timeout("unknown:1"); got != time.Second {
+		t.Errorf("Expected unmeasured replica to use the fixed timeout, got %v", got)
+	}
+
+	// A per-request override wins over the adaptive timeout
+	if got := s.quorumOptions(250).Timeout("slow:1"); got != 250*time.Millisecond {
+		t.Errorf("Expected request override of 250ms, got %v", got)
+	}
+}
+
+func TestTimeoutConfig_Validate(t *testing.T) {
+	if err := DefaultTimeoutConfig().Validate(); err != nil {
+		t.Errorf("Expected default config to be valid: %v", err)
+	}
+
+	cfg := DefaultTimeoutConfig()
+	cfg.Mode = TimeoutAdaptive
+	cfg.Min, cfg.Max = time.Second, time.Millisecond
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for min > max")
+	}
+
+	if _, err := ParseTimeoutMode("sometimes"); err == nil {
+		t.Error("Expected error for unknown mode")
+	}
+	if m, err := ParseTimeoutMode("Adaptive"); err != nil || m != TimeoutAdaptive {
+		t.Errorf("Expected adaptive, got %v, %v", m, err)
+	}
+}
@@ -0,0 +1,98 @@
+package node
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"kvstore/internal/tlsutil"
+)
+
+// SetTLS enables TLS for client traffic and mutual TLS between nodes.
+// Must be called before Start.
+func (n *Node) SetTLS(certs *tlsutil.Certs) {
+	n.certs = certs
+	n.clientMgr.SetTLS(certs, n.peerID)
+}
+
+// peerID returns the node ID of the member at addr, or "" if unknown.
+func (n *Node) peerID(addr string) string {
+	if n.membership != nil {
+		for _, m := range n.membership.Snapshot() {
+			if m.Addr == addr {
+				return m.ID
+			}
+		}
+		return ""
+	}
+	n.ringMu.RLock()
+	defer n.ringMu.RUnlock()
+	for _, node := range n.ring.GetNodes() {
+		if node.Addr == addr {
+			return node.ID
+		}
+	}
+	return ""
+}
+
+// nodeOnly reports whether a method may only be called by other nodes: the
+// replica RPCs and the gossip protocol. Membership queries (GetMembership,
+// GetRing, Health) stay available to clients.
+func nodeOnly(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/kvstore.KVInternal/") ||
+		fullMethod == "/kvstore.Membership/Ping" ||
+		fullMethod == "/kvstore.Membership/Gossip"
+}
+
+// requireNodeCert rejects node-only calls whose caller did not present a
+// certificate signed by the node CA. For gossip, the certificate must also
+// identify the node named in the request.
+func (n *Node) requireNodeCert(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if !nodeOnly(info.FullMethod) {
+		return handler(ctx, req)
+	}
+	leaf, err := n.peerNodeCert(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if r, ok := req.(interface{ GetFromId() string }); ok && r.GetFromId() != "" && !tlsutil.HasIdentity(leaf, r.GetFromId()) {
+		return nil, status.Errorf(codes.PermissionDenied, "certificate %q does not identify node %s", leaf.Subject.CommonName, r.GetFromId())
+	}
+	return handler(ctx, req)
+}
+
+// peerNodeCert returns the caller's certificate if it is a node certificate.
+func (n *Node) peerNodeCert(ctx context.Context) (*x509.Certificate, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, errors.New("no peer information")
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil, errors.New("connection is not TLS")
+	}
+	return n.certs.VerifyNode(info.State.PeerCertificates, x509.ExtKeyUsageClientAuth)
+}
+
+// SetTLS makes connections to peers use mutual TLS. peerID maps an address to
+// the node ID its certificate must carry ("" accepts any node certificate).
+// Must be called before the first connection is made.
+func (cm *ClientManager) SetTLS(certs *tlsutil.Certs, peerID func(addr string) string) {
+	cm.certs = certs
+	cm.peerID = peerID
+}
+
+// transportCredentials returns the credentials for connecting to addr.
+func (cm *ClientManager) transportCredentials(addr string) credentials.TransportCredentials {
+	if cm.certs == nil {
+		return insecure.NewCredentials()
+	}
+	return credentials.NewTLS(cm.certs.PeerConfig(func() string { return cm.peerID(addr) }))
+}
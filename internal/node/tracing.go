@@ -0,0 +1,92 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"kvstore/internal/quorum"
+	"kvstore/internal/tracing"
+)
+
+// SetTracer records traces of the requests the node serves and the RPCs it
+// makes. Must be called before Start.
+func (n *Node) SetTracer(tp trace.TracerProvider) {
+	n.tracer = tp
+	n.clientMgr.SetTracer(tp)
+}
+
+// traced reports whether calls of a method are traced. Gossip runs every
+// probe interval regardless of load and would drown out client requests.
+func traced(fullMethod string) bool {
+	return !strings.HasPrefix(fullMethod, "/kvstore.Membership/")
+}
+
+// trace adds the key and request ID of a request to the server span that
+// the tracing stats handler started for it.
+func (n *Node) trace(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		span.SetAttributes(requestAttributes(req)...)
+	}
+	return handler(ctx, req)
+}
+
+// requestAttributes returns the key and request ID of a data or replica
+// request as span attributes.
+func requestAttributes(req any) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if r, ok := req.(interface{ GetKey() string }); ok && r.GetKey() != "" {
+		attrs = append(attrs, attribute.String("kvstore.key", r.GetKey()))
+	}
+	if r, ok := req.(interface{ GetRequestId() string }); ok && r.GetRequestId() != "" {
+		attrs = append(attrs, attribute.String("kvstore.request_id", r.GetRequestId()))
+	}
+	return attrs
+}
+
+// SetTracer traces the RPCs made to peers. Must be called before the first
+// client is created.
+func (cm *ClientManager) SetTracer(tp trace.TracerProvider) {
+	cm.tracer = tp
+}
+
+// dialOptions returns the options of connections to the peer at addr: its
+// transport credentials, and tracing of the RPCs made on it.
+func (cm *ClientManager) dialOptions(addr string) []grpc.DialOption {
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(cm.transportCredentials(addr))}
+	if cm.tracer != nil {
+		opts = append(opts, grpc.WithStatsHandler(tracing.ClientHandler(cm.tracer, traced)))
+	}
+	return opts
+}
+
+// startQuorumSpan starts the span of a quorum operation fanning out to
+// replicas.
+func startQuorumSpan(ctx context.Context, t trace.Tracer, name, key string, required, replicas int) (context.Context, trace.Span) {
+	return t.Start(ctx, name, trace.WithAttributes(
+		attribute.String("kvstore.key", key),
+		attribute.Int("kvstore.quorum.required", required),
+		attribute.Int("kvstore.quorum.replicas", replicas),
+	))
+}
+
+// endQuorumSpan records the outcome of a quorum operation and ends its span.
+func endQuorumSpan(span trace.Span, success bool, errorMessage string, outcomes []quorum.ReplicaOutcome) {
+	span.SetAttributes(
+		attribute.Bool("kvstore.quorum.success", success),
+		attribute.String("kvstore.quorum.outcomes", quorum.FormatOutcomes(outcomes)),
+	)
+	if !success {
+		tracing.SetError(span, errors.New(errorMessage))
+	}
+	span.End()
+}
+
+// startStoreSpan starts the span of an operation on the local store.
+func startStoreSpan(ctx context.Context, t trace.Tracer, op, key string) trace.Span {
+	_, span := t.Start(ctx, "store."+op, trace.WithAttributes(attribute.String("kvstore.key", key)))
+	return span
+}
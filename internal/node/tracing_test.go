@@ -0,0 +1,54 @@
+package node
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	kvstorepb "kvstore/internal/gen/api"
+)
+
+func TestNode_Trace(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	n := &Node{tracer: tp}
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "kvstore.KVInternal/ReplicaPut")
+	handler := func(ctx context.Context, req any) (any, error) {
+		return &kvstorepb.ReplicaPutResponse{}, nil
+	}
+	req := &kvstorepb.ReplicaPutRequest{Key: "k", RequestId: "r1"}
+	n.trace(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/kvstore.KVInternal/ReplicaPut"}, handler)
+	span.End()
+
+	// Requests without a recording span are passed through untouched
+	n.trace(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/kvstore.KVInternal/ReplicaPut"}, handler)
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Recorded %d spans, want 1", len(spans))
+	}
+	want := map[attribute.Key]string{"kvstore.key": "k", "kvstore.request_id": "r1"}
+	for _, a := range spans[0].Attributes() {
+		if v, ok := want[a.Key]; ok && v == a.Value.AsString() {
+			delete(want, a.Key)
+		}
+	}
+	if len(want) != 0 {
+		t.Errorf("Span missing attributes %v, got %v", want, spans[0].Attributes())
+	}
+
+	// Gossip is not traced
+	for method, want := range map[string]bool{
+		"/kvstore.KVInternal/ReplicaPut": true,
+		"/kvstore.KV/Get":                true,
+		"/kvstore.Membership/Ping":       false,
+	} {
+		if got := traced(method); got != want {
+			t.Errorf("traced(%q) = %v, want %v", method, got, want)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package quorum
+
+import "time"
+
+// LatencyTracker records per-replica read latencies and uses them to choose
+// which replicas a read contacts first and when to send a speculative read.
+// Implementations must be safe for concurrent use.
+type LatencyTracker interface {
+	// Rank returns the replicas ordered from fastest to slowest expected
+	// latency. It must return a permutation of replicas.
+	Rank(replicas []string) []string
+
+	// HedgeDelay returns how long to wait for the contacted replicas before
+	// speculatively reading from one more. A value <= 0 disables hedging.
+	HedgeDelay(contacted []string) time.Duration
+
+	// Observe records the latency and outcome of a read from replica. A
+	// not-found read is observed with its latency, any other failed read
+	// with the timeout that was applied to it.
+	Observe(replica string, latency time.Duration, err error)
+}
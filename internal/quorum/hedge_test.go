@@ -0,0 +1,227 @@
+package quorum
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTracker ranks replicas in a fixed order and hedges after a fixed delay.
+type fakeTracker struct {
+	order []string
+	delay time.Duration
+
+	mu        sync.Mutex
+	observed  map[string]int
+	latencies map[string]time.Duration
+}
+
+func (f *fakeTracker) Rank(replicas []string) []string { return f.order }
+
+func (f *fakeTracker) HedgeDelay(contacted []string) time.Duration { return f.delay }
+
+func (f *fakeTracker) Observe(replica string, latency time.Duration, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.observed == nil {
+		f.observed = make(map[string]int)
+		f.latencies = make(map[string]time.Duration)
+	}
+	f.observed[replica]++
+	f.latencies[replica] = latency
+}
+
+// recordingReadFn returns a read function that records which replicas were
+// contacted, sleeping for the given per-replica delay and failing replicas in fail.
+func recordingReadFn(delays map[string]time.Duration, fail map[string]bool) (ReplicaReadFunc, func() []string) {
+	var (
+		mu        sync.Mutex
+		contacted []string
+	)
+	fn := func(ctx context.Context, replicaID string) ([]byte, interface{}, bool, error) {
+		mu.Lock()
+		contacted = append(contacted, replicaID)
+		mu.Unlock()
+
+		select {
+		case <-time.After(delays[replicaID]):
+		case <-ctx.Done():
+			return nil, nil, false, ctx.Err()
+		}
+		if fail[replicaID] {
+			return nil, nil, false, errors.New("replica failed")
+		}
+		return []byte(replicaID), nil, false, nil
+	}
+	get := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), contacted...)
+	}
+	return fn, get
+}
+
+func TestDoReadWithOptions_ContactsOnlyR(t *testing.T) {
+	tracker := &fakeTracker{order: []string{"r3", "r1", "r2"}, delay: time.Second}
+	readFn, contacted := recordingReadFn(nil, nil)
+
+	result := DoReadWithOptions(context.Background(), []string{"r1", "r2", "r3"}, 2, readFn, Options{Tracker: tracker})
+
+	if !result.Success {
+		t.Fatalf("Expected success, got: %s", result.ErrorMessage)
+	}
+	if result.Contacted != 2 || result.Hedged {
+		t.Errorf("Expected 2 contacted without hedging, got %d (hedged=%v)", result.Contacted, result.Hedged)
+	}
+	got := contacted()
+	for _, rid := range got {
+		if rid == "r2" {
+			t.Errorf("Expected slowest-ranked replica r2 not to be contacted, got %v", got)
+		}
+	}
+}
+
+func TestDoReadWithOptions_ReplacesFailedReplica(t *testing.T) {
+	readFn, contacted := recordingReadFn(nil, map[string]bool{"r1": true})
+
+	result := DoReadWithOptions(context.Background(), []string{"r1", "r2", "r3"}, 2, readFn, Options{})
+
+	if !result.Success {
+		t.Fatalf("Expected success, got: %s", result.ErrorMessage)
+	}
+	if result.Contacted != 3 {
+		t.Errorf("Expected failed read to be replaced (3 contacted), got %d: %v", result.Contacted, contacted())
+	}
+}
+
+func TestDoReadWithOptions_HedgesSlowReplica(t *testing.T) {
+	tracker := &fakeTracker{order: []string{"r1", "r2", "r3"}, delay: 10 * time.Millisecond}
+	readFn, _ := recordingReadFn(map[string]time.Duration{"r1": time.Second}, nil)
+
+	start := time.Now()
+	result := DoReadWithOptions(context.Background(), []string{"r1", "r2", "r3"}, 2, readFn, Options{Tracker: tracker})
+
+	if !result.Success {
+		t.Fatalf("Expected success, got: %s", result.ErrorMessage)
+	}
+	if !result.Hedged || result.Contacted != 3 {
+		t.Errorf("Expected a speculative read to r3, got contacted=%d hedged=%v", result.Contacted, result.Hedged)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected hedged read to avoid the slow replica, took %v", elapsed)
+	}
+}
+
+func TestDoReadWithOptions_FailsFastWhenQuorumImpossible(t *testing.T) {
+	readFn, _ := recordingReadFn(nil, map[string]bool{"r1": true, "r2": true})
+
+	result := DoReadWithOptions(context.Background(), []string{"r1", "r2", "r3"}, 2, readFn, Options{})
+
+	if result.Success {
+		t.Error("Expected failure with two failed replicas")
+	}
+	// Gives up once two reads failed, without waiting for r3
+	if result.Responses >= 2 {
+		t.Errorf("Expected fewer than 2 responses, got %d", result.Responses)
+	}
+}
+
+func TestDoReadWithOptions_ObservesLatency(t *testing.T) {
+	tracker := &fakeTracker{order: []string{"r1", "r2", "r3"}}
+	readFn, _ := recordingReadFn(nil, nil)
+
+	DoReadWithOptions(context.Background(), []string{"r1", "r2", "r3"}, 2, readFn, Options{Tracker: tracker})
+
+	// Observations are recorded by the read goroutines before delivering results
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	if tracker.observed["r1"] != 1 || tracker.observed["r2"] != 1 {
+		t.Errorf("Expected one observation each for r1 and r2, got %v", tracker.observed)
+	}
+}
+
+func TestDoReadWithOptions_ObservesAppliedTimeoutOnFailure(t *testing.T) {
+	tracker := &fakeTracker{order: []string{"r1", "r2", "r3"}}
+	readFn := func(ctx context.Context, replicaID string) ([]byte, interface{}, bool, error) {
+		switch replicaID {
+		case "r1":
+			return nil, nil, false, errors.New("replica failed")
+		case "r2":
+			return nil, nil, false, ErrNotFound
+		}
+		return []byte(replicaID), nil, false, nil
+	}
+	timeout := func(string) time.Duration { return 300 * time.Millisecond }
+
+	DoReadWithOptions(context.Background(), []string{"r1", "r2", "r3"}, 2, readFn, Options{Timeout: timeout, Tracker: tracker})
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	if got := tracker.latencies["r1"]; got < 250*time.Millisecond || got > 300*time.Millisecond {
+		t.Errorf("Expected the failed read observed at the 300ms timeout, got %v", got)
+	}
+	if got := tracker.latencies["r2"]; got >= 250*time.Millisecond {
+		t.Errorf("Expected the not-found read observed at its latency, got %v", got)
+	}
+}
+
+func TestDoWriteWithOptions_DoesNotObserveLatency(t *testing.T) {
+	tracker := &fakeTracker{order: []string{"r1", "r2", "r3"}}
+	writeFn := func(ctx context.Context, replicaID string) (bool, error) { return true, nil }
+
+	DoWriteWithOptions(context.Background(), []string{"r1", "r2", "r3"}, 3, writeFn, Options{Tracker: tracker})
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	if len(tracker.observed) != 0 {
+		t.Errorf("Expected writes not to feed the read latency tracker, got %v", tracker.observed)
+	}
+}
+
+func TestDoReadWithOptions_PerReplicaTimeout(t *testing.T) {
+	readFn, _ := recordingReadFn(map[string]time.Duration{"r1": time.Second}, nil)
+	opts := Options{Timeout: func(replicaID string) time.Duration {
+		if replicaID == "r1" {
+			return 20 * time.Millisecond
+		}
+		return time.Second
+	}}
+
+	start := time.Now()
+	result := DoReadWithOptions(context.Background(), []string{"r1", "r2", "r3"}, 2, readFn, opts)
+
+	if !result.Success {
+		t.Fatalf("Expected success after replacing the timed out replica, got: %s", result.ErrorMessage)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected r1 to time out after 20ms, took %v", elapsed)
+	}
+	for _, o := range result.Outcomes {
+		if o.ReplicaID == "r1" && o.Class != ErrorTimeout {
+			t.Errorf("Expected r1 timeout outcome, got %s", o)
+		}
+	}
+}
+
+func TestDoWriteWithOptions_HonorsParentDeadline(t *testing.T) {
+	writeFn := func(ctx context.Context, replicaID string) (bool, error) {
+		<-ctx.Done()
+		return false, ctx.Err()
+	}
+	// The per-replica timeout is longer than the caller's deadline
+	opts := Options{Timeout: func(string) time.Duration { return 10 * time.Second }}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	result := DoWriteWithOptions(ctx, []string{"r1", "r2"}, 1, writeFn, opts)
+
+	if result.Success {
+		t.Error("Expected failure")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the caller's deadline to bound the write, took %v", elapsed)
+	}
+}
@@ -0,0 +1,91 @@
+package quorum
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level is a named consistency level, mapped to a replica count by Required.
+type Level int
+
+const (
+	// LevelUnspecified means no level was requested; raw R/W values or the
+	// node defaults apply.
+	LevelUnspecified Level = iota
+	// One requires a single replica.
+	One
+	// Quorum requires a majority of the N replicas.
+	Quorum
+	// All requires every one of the N replicas.
+	All
+	// LocalQuorum requires a majority of the replicas in the coordinator's
+	// zone. Without zone-aware placement every replica is local, so it
+	// behaves like Quorum.
+	LocalQuorum
+)
+
+// String returns the name of the level.
+func (l Level) String() string {
+	switch l {
+	case LevelUnspecified:
+		return "UNSPECIFIED"
+	case One:
+		return "ONE"
+	case Quorum:
+		return "QUORUM"
+	case All:
+		return "ALL"
+	case LocalQuorum:
+		return "LOCAL_QUORUM"
+	default:
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+}
+
+// ParseLevel parses a level name (case-insensitive).
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "", "UNSPECIFIED":
+		return LevelUnspecified, nil
+	case "ONE":
+		return One, nil
+	case "QUORUM":
+		return Quorum, nil
+	case "ALL":
+		return All, nil
+	case "LOCAL_QUORUM":
+		return LocalQuorum, nil
+	default:
+		return LevelUnspecified, fmt.Errorf("unknown consistency level %q (expected ONE, QUORUM, ALL or LOCAL_QUORUM)", s)
+	}
+}
+
+// Required returns the number of replicas that must respond to satisfy level,
+// given the replication factor n, the number of replicas in the preference
+// list (available) and how many of the n replicas are placed in the
+// coordinator's zone (local). Like Quorum, LocalQuorum is a majority of the
+// placement, not of the replicas that happen to be available. Returns an error
+// if the level cannot be satisfied by the available replicas.
+func Required(level Level, n, available, local int) (int, error) {
+	var required, pool int
+	switch level {
+	case One:
+		required, pool = 1, available
+	case Quorum:
+		required, pool = n/2+1, available
+	case All:
+		required, pool = n, available
+	case LocalQuorum:
+		required, pool = local/2+1, min(local, available)
+		if local == 0 {
+			return 0, fmt.Errorf("consistency level %s cannot be satisfied: no replicas in the local zone", level)
+		}
+	default:
+		return 0, fmt.Errorf("unknown consistency level %s", level)
+	}
+
+	if required > pool {
+		return 0, fmt.Errorf("consistency level %s requires %d replicas, only %d available", level, required, pool)
+	}
+	return required, nil
+}
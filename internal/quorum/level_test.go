@@ -0,0 +1,71 @@
+package quorum
+
+import "testing"
+
+func TestRequired(t *testing.T) {
+	tests := []struct {
+		level     Level
+		n         int
+		available int
+		local     int
+		want      int
+	}{
+		{One, 3, 3, 3, 1},
+		{Quorum, 3, 3, 3, 2},
+		{Quorum, 5, 5, 5, 3},
+		{All, 3, 3, 3, 3},
+		{LocalQuorum, 3, 3, 3, 2},
+		{LocalQuorum, 6, 6, 3, 2},
+		{LocalQuorum, 3, 2, 3, 2},
+		// Quorum is computed from N, not from the replicas that happen to be available
+		{Quorum, 3, 2, 2, 2},
+	}
+	for _, tt := range tests {
+		got, err := Required(tt.level, tt.n, tt.available, tt.local)
+		if err != nil {
+			t.Errorf("Required(%s, n=%d) failed: %v", tt.level, tt.n, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Required(%s, n=%d) = %d, want %d", tt.level, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestRequired_Unsatisfiable(t *testing.T) {
+	tests := []struct {
+		name      string
+		level     Level
+		n         int
+		available int
+		local     int
+	}{
+		{"all with missing replica", All, 3, 2, 2},
+		{"quorum with one replica", Quorum, 3, 1, 1},
+		{"local quorum without local replicas", LocalQuorum, 3, 3, 0},
+		{"local quorum with one replica", LocalQuorum, 3, 1, 3},
+		{"unspecified", LevelUnspecified, 3, 3, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Required(tt.level, tt.n, tt.available, tt.local); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, l := range []Level{One, Quorum, All, LocalQuorum} {
+		got, err := ParseLevel(l.String())
+		if err != nil || got != l {
+			t.Errorf("ParseLevel(%q) = %v, %v", l.String(), got, err)
+		}
+	}
+	if got, err := ParseLevel("local_quorum"); err != nil || got != LocalQuorum {
+		t.Errorf("Expected case-insensitive parse, got %v, %v", got, err)
+	}
+	if _, err := ParseLevel("MOST"); err == nil {
+		t.Error("Expected error for unknown level")
+	}
+}
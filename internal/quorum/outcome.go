@@ -0,0 +1,122 @@
+package quorum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrNotFound is returned by a ReplicaReadFunc when the replica holds no
+// version of the key. It is classified as ErrorNotFound.
+var ErrNotFound = errors.New("not found")
+
+// errAbandoned is the outcome error of a read still outstanding when the
+// quorum read returned.
+var errAbandoned = errors.New("no response before the read completed")
+
+// ErrorClass classifies the outcome of a single replica operation.
+type ErrorClass int
+
+const (
+	// ErrorNone means the replica operation succeeded.
+	ErrorNone ErrorClass = iota
+	// ErrorNotFound means the replica holds no version of the key.
+	ErrorNotFound
+	// ErrorTimeout means the replica did not respond in time.
+	ErrorTimeout
+	// ErrorCanceled means the operation was abandoned, e.g. because the
+	// quorum was already met or the caller went away.
+	ErrorCanceled
+	// ErrorUnavailable means the replica could not be reached.
+	ErrorUnavailable
+	// ErrorRejected means the replica responded but refused the operation.
+	ErrorRejected
+	// ErrorOther is any other failure.
+	ErrorOther
+)
+
+// String returns the name of the error class.
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorNone:
+		return "ok"
+	case ErrorNotFound:
+		return "not_found"
+	case ErrorTimeout:
+		return "timeout"
+	case ErrorCanceled:
+		return "canceled"
+	case ErrorUnavailable:
+		return "unavailable"
+	case ErrorRejected:
+		return "rejected"
+	default:
+		return "error"
+	}
+}
+
+// ClassifyError returns the error class of a replica operation error.
+func ClassifyError(err error) ErrorClass {
+	switch {
+	case err == nil:
+		return ErrorNone
+	case errors.Is(err, ErrNotFound):
+		return ErrorNotFound
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorCanceled
+	}
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.DeadlineExceeded:
+			return ErrorTimeout
+		case codes.Canceled:
+			return ErrorCanceled
+		case codes.Unavailable:
+			return ErrorUnavailable
+		}
+	}
+	return ErrorOther
+}
+
+// ReplicaOutcome is the outcome of a quorum operation on a single replica.
+type ReplicaOutcome struct {
+	ReplicaID string
+	Success   bool
+	Class     ErrorClass
+	Err       error         // nil on success
+	Latency   time.Duration // Zero if the replica had not responded when the operation returned
+	Version   interface{}   // Version returned by a successful read (nil for writes)
+}
+
+// String returns a compact representation of the outcome, e.g. "n2:timeout(2s)".
+func (o ReplicaOutcome) String() string {
+	return fmt.Sprintf("%s:%s(%v)", o.ReplicaID, o.Class, o.Latency.Round(time.Microsecond))
+}
+
+// FormatOutcomes returns a compact, single-line summary of outcomes for logging.
+func FormatOutcomes(outcomes []ReplicaOutcome) string {
+	parts := make([]string, len(outcomes))
+	for i, o := range outcomes {
+		parts[i] = o.String()
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
+
+// Acked returns the IDs of the replicas that succeeded or, for reads, answered
+// that they hold no version of the key, in outcome order.
+func Acked(outcomes []ReplicaOutcome) []string {
+	var ids []string
+	for _, o := range outcomes {
+		if o.Success || o.Class == ErrorNotFound {
+			ids = append(ids, o.ReplicaID)
+		}
+	}
+	return ids
+}
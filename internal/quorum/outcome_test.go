@@ -0,0 +1,95 @@
+package quorum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ErrorClass
+	}{
+		{nil, ErrorNone},
+		{ErrNotFound, ErrorNotFound},
+		{fmt.Errorf("wrapped: %w", ErrNotFound), ErrorNotFound},
+		{context.DeadlineExceeded, ErrorTimeout},
+		{context.Canceled, ErrorCanceled},
+		{status.Error(codes.DeadlineExceeded, "slow"), ErrorTimeout},
+		{status.Error(codes.Unavailable, "down"), ErrorUnavailable},
+		{errors.New("boom"), ErrorOther},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.err); got != tt.want {
+			t.Errorf("ClassifyError(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestDoWrite_Outcomes(t *testing.T) {
+	writeFn := func(ctx context.Context, replicaID string) (bool, error) {
+		switch replicaID {
+		case "r2":
+			return false, status.Error(codes.Unavailable, "down")
+		case "r3":
+			return false, nil
+		}
+		return true, nil
+	}
+
+	result := DoWrite(context.Background(), []string{"r1", "r2", "r3"}, 1, writeFn)
+
+	if len(result.Outcomes) != 3 {
+		t.Fatalf("Expected an outcome per replica, got %v", result.Outcomes)
+	}
+	classes := make(map[string]ErrorClass)
+	for _, o := range result.Outcomes {
+		classes[o.ReplicaID] = o.Class
+	}
+	if classes["r1"] != ErrorNone || classes["r2"] != ErrorUnavailable || classes["r3"] != ErrorRejected {
+		t.Errorf("Unexpected outcome classes: %v", classes)
+	}
+	if acked := Acked(result.Outcomes); len(acked) != 1 || acked[0] != "r1" {
+		t.Errorf("Expected only r1 acked, got %v", acked)
+	}
+}
+
+func TestDoRead_OutcomesIdentifyReplicas(t *testing.T) {
+	readFn := func(ctx context.Context, replicaID string) ([]byte, interface{}, bool, error) {
+		if replicaID == "r1" {
+			return nil, nil, false, ErrNotFound
+		}
+		return []byte("value-" + replicaID), "version-" + replicaID, false, nil
+	}
+
+	result := DoRead(context.Background(), []string{"r1", "r2", "r3"}, 3, readFn)
+
+	if !result.Success {
+		t.Fatalf("Expected success, got: %s", result.ErrorMessage)
+	}
+	for _, v := range result.Values {
+		if string(v.Value) != "value-"+v.ReplicaID {
+			t.Errorf("Value %q attributed to wrong replica %s", v.Value, v.ReplicaID)
+		}
+	}
+	for _, o := range result.Outcomes {
+		switch o.ReplicaID {
+		case "r1":
+			if o.Class != ErrorNotFound || o.Success {
+				t.Errorf("Expected r1 not found, got %s", o)
+			}
+		default:
+			if !o.Success || o.Version != "version-"+o.ReplicaID {
+				t.Errorf("Expected %s to succeed with its version, got %+v", o.ReplicaID, o)
+			}
+		}
+	}
+	if len(result.Outcomes) != 3 {
+		t.Errorf("Expected 3 outcomes, got %s", FormatOutcomes(result.Outcomes))
+	}
+}
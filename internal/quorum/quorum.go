@@ -18,6 +18,7 @@ type WriteResult struct {
 	Acks         int
 	Required     int
 	Replicas     int
+	Outcomes     []ReplicaOutcome // One per replica, in completion order
 	ErrorMessage string
 }
 
@@ -27,15 +28,19 @@ type ReadResult struct {
 	Responses    int
 	Required     int
 	Replicas     int
+	Contacted    int  // Replicas a read was sent to
+	Hedged       bool // A speculative read was sent
 	Values       []ReadValue
+	Outcomes     []ReplicaOutcome // One per contacted replica, in completion order
 	ErrorMessage string
 }
 
 // ReadValue represents a value read from a replica.
 type ReadValue struct {
-	Value   []byte
-	Version interface{} // Will be clock.VectorClock, but using interface{} to avoid circular import
-	Deleted bool
+	ReplicaID string // Replica that returned the value
+	Value     []byte
+	Version   interface{} // Will be clock.VectorClock, but using interface{} to avoid circular import
+	Deleted   bool
 }
 
 // ReplicaWriteFunc is a function that performs a write to a single replica.
@@ -46,9 +51,39 @@ type ReplicaWriteFunc func(ctx context.Context, replicaID string) (bool, error)
 // Returns the value, version, deleted flag, and error.
 type ReplicaReadFunc func(ctx context.Context, replicaID string) ([]byte, interface{}, bool, error)
 
-// DoWrite performs a quorum write operation.
-// It fans out to all replicas in parallel and returns success when W acks are received.
+// Options tunes a quorum operation. The zero value applies
+// DefaultPerReplicaTimeout to every replica and tracks no latency.
+type Options struct {
+	// Timeout returns the timeout of the RPC to a single replica. A nil
+	// Timeout or a value <= 0 means DefaultPerReplicaTimeout. The parent
+	// context's deadline always applies as well.
+	Timeout func(replicaID string) time.Duration
+
+	// Tracker ranks replicas and drives hedging for reads, and observes the
+	// latency of every replica read. Writes only use it through Timeout, if
+	// at all. Nil disables ranking and hedging.
+	Tracker LatencyTracker
+}
+
+// replicaTimeout returns the timeout for a single replica RPC.
+func (o Options) replicaTimeout(replicaID string) time.Duration {
+	if o.Timeout != nil {
+		if d := o.Timeout(replicaID); d > 0 {
+			return d
+		}
+	}
+	return DefaultPerReplicaTimeout
+}
+
+// DoWrite performs a quorum write operation with default options.
+// See DoWriteWithOptions.
 func DoWrite(ctx context.Context, replicas []string, requiredW int, writeFn ReplicaWriteFunc) WriteResult {
+	return DoWriteWithOptions(ctx, replicas, requiredW, writeFn, Options{})
+}
+
+// DoWriteWithOptions performs a quorum write operation.
+// It fans out to all replicas in parallel and returns success when W acks are received.
+func DoWriteWithOptions(ctx context.Context, replicas []string, requiredW int, writeFn ReplicaWriteFunc, opts Options) WriteResult {
 	if len(replicas) == 0 {
 		return WriteResult{
 			Success:      false,
@@ -68,26 +103,41 @@ func DoWrite(ctx context.Context, replicas []string, requiredW int, writeFn Repl
 	}
 
 	var (
-		mu     sync.Mutex
-		acks   int
-		errors []error
-		wg     sync.WaitGroup
+		mu       sync.Mutex
+		acks     int
+		errors   []error
+		outcomes []ReplicaOutcome
+		wg       sync.WaitGroup
 	)
 
-	// Create context with per-replica timeout
-	replicaCtx, cancel := context.WithTimeout(ctx, DefaultPerReplicaTimeout)
-	defer cancel()
-
 	// Fanout to all replicas
 	for _, replicaID := range replicas {
 		wg.Add(1)
 		go func(rid string) {
 			defer wg.Done()
 
+			// Per-replica timeout, bounded by the parent deadline
+			replicaCtx, cancel := context.WithTimeout(ctx, opts.replicaTimeout(rid))
+			defer cancel()
+
+			// Write latency is not observed: the tracker ranks replicas for reads
+			start := time.Now()
 			success, err := writeFn(replicaCtx, rid)
+			outcome := ReplicaOutcome{
+				ReplicaID: rid,
+				Success:   success,
+				Class:     ClassifyError(err),
+				Err:       err,
+				Latency:   time.Since(start),
+			}
+			if !success && err == nil {
+				outcome.Class = ErrorRejected
+			}
+
 			mu.Lock()
 			defer mu.Unlock()
 
+			outcomes = append(outcomes, outcome)
 			if success {
 				acks++
 			} else if err != nil {
@@ -108,11 +158,14 @@ func DoWrite(ctx context.Context, replicas []string, requiredW int, writeFn Repl
 		// All replicas responded
 	case <-ctx.Done():
 		// Parent context cancelled
+		mu.Lock()
+		defer mu.Unlock()
 		return WriteResult{
 			Success:      false,
 			Acks:         acks,
 			Required:     requiredW,
 			Replicas:     len(replicas),
+			Outcomes:     append([]ReplicaOutcome(nil), outcomes...),
 			ErrorMessage: fmt.Sprintf("context cancelled: %v", ctx.Err()),
 		}
 	}
@@ -126,6 +179,7 @@ func DoWrite(ctx context.Context, replicas []string, requiredW int, writeFn Repl
 			Acks:     acks,
 			Required: requiredW,
 			Replicas: len(replicas),
+			Outcomes: outcomes,
 		}
 	}
 
@@ -140,13 +194,27 @@ func DoWrite(ctx context.Context, replicas []string, requiredW int, writeFn Repl
 		Acks:         acks,
 		Required:     requiredW,
 		Replicas:     len(replicas),
+		Outcomes:     outcomes,
 		ErrorMessage: errMsg,
 	}
 }
 
-// DoRead performs a quorum read operation.
-// It fans out to all replicas in parallel and returns when R responses are received.
+// DoRead performs a quorum read operation with default options.
+// See DoReadWithOptions.
 func DoRead(ctx context.Context, replicas []string, requiredR int, readFn ReplicaReadFunc) ReadResult {
+	return DoReadWithOptions(ctx, replicas, requiredR, readFn, Options{})
+}
+
+// DoReadWithOptions performs a quorum read operation.
+// It initially contacts only R replicas, ranked fastest first by the tracker,
+// and returns as soon as R responses are received. A replica answering
+// ErrNotFound has responded: it counts toward R but adds no value, so a read
+// whose responses are all not-found succeeds with no Values. A failed read is replaced
+// by the next untried replica. If no response arrives within the tracker's
+// hedge delay, one extra replica is contacted speculatively. Without a
+// tracker the given replica order is kept and speculative reads are disabled.
+func DoReadWithOptions(ctx context.Context, replicas []string, requiredR int, readFn ReplicaReadFunc, opts Options) ReadResult {
+	tracker := opts.Tracker
 	if len(replicas) == 0 {
 		return ReadResult{
 			Success:      false,
@@ -165,72 +233,162 @@ func DoRead(ctx context.Context, replicas []string, requiredR int, readFn Replic
 		}
 	}
 
-	var (
-		mu        sync.Mutex
-		responses int
-		values    []ReadValue
-		errors    []error
-		wg        sync.WaitGroup
-	)
+	order := replicas
+	if tracker != nil {
+		order = tracker.Rank(replicas)
+	}
 
-	// Create context with per-replica timeout
-	replicaCtx, cancel := context.WithTimeout(ctx, DefaultPerReplicaTimeout)
+	// Cancelling readCtx abandons outstanding reads once the quorum is met
+	readCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	// Fanout to all replicas
-	for _, replicaID := range replicas {
-		wg.Add(1)
-		go func(rid string) {
-			defer wg.Done()
-
+	type readResponse struct {
+		value   ReadValue
+		err     error
+		latency time.Duration
+	}
+	// Buffered so abandoned reads never block
+	results := make(chan readResponse, len(order))
+
+	next := 0
+	launch := func() {
+		rid := order[next]
+		next++
+		go func() {
+			// Per-replica timeout, bounded by the parent deadline
+			replicaCtx, replicaCancel := context.WithTimeout(readCtx, opts.replicaTimeout(rid))
+			defer replicaCancel()
+
+			start := time.Now()
 			value, version, deleted, err := readFn(replicaCtx, rid)
-			mu.Lock()
-			defer mu.Unlock()
+			latency := time.Since(start)
+			// Reads abandoned after the quorum was met say nothing about the replica
+			if tracker != nil && !(err != nil && readCtx.Err() != nil) {
+				observed := latency
+				if err != nil && ClassifyError(err) != ErrorNotFound {
+					// A failed read counts as taking the whole timeout applied to it
+					if deadline, ok := replicaCtx.Deadline(); ok {
+						observed = deadline.Sub(start)
+					}
+				}
+				tracker.Observe(rid, observed, err)
+			}
+			results <- readResponse{
+				value:   ReadValue{ReplicaID: rid, Value: value, Version: version, Deleted: deleted},
+				err:     err,
+				latency: latency,
+			}
+		}()
+	}
 
-			if err == nil {
-				responses++
-				values = append(values, ReadValue{
-					Value:   value,
-					Version: version,
-					Deleted: deleted,
+	for next < requiredR {
+		launch()
+	}
+
+	// Speculative read timer
+	var hedge <-chan time.Time
+	if tracker != nil && next < len(order) {
+		if delay := tracker.HedgeDelay(order[:next]); delay > 0 {
+			timer := time.NewTimer(delay)
+			defer timer.Stop()
+			hedge = timer.C
+		}
+	}
+
+	var (
+		responses   int
+		outstanding = next
+		values      []ReadValue
+		errors      []error
+		outcomes    []ReplicaOutcome
+		hedged      bool
+	)
+
+	// finish fills in the outcome of reads still outstanding at return time
+	finish := func() []ReplicaOutcome {
+		responded := make(map[string]bool, len(outcomes))
+		for _, o := range outcomes {
+			responded[o.ReplicaID] = true
+		}
+		for _, rid := range order[:next] {
+			if !responded[rid] {
+				outcomes = append(outcomes, ReplicaOutcome{
+					ReplicaID: rid,
+					Class:     ErrorCanceled,
+					Err:       errAbandoned,
 				})
-			} else {
-				errors = append(errors, fmt.Errorf("replica %s: %w", rid, err))
 			}
-		}(replicaID)
+		}
+		return outcomes
 	}
 
-	// Wait for quorum or all responses
-	done := make(chan struct{})
-	go func() {
-		wg.Wait()
-		close(done)
-	}()
+	for responses < requiredR {
+		// Fail fast once the quorum can no longer be met
+		if responses+outstanding+(len(order)-next) < requiredR {
+			break
+		}
 
-	select {
-	case <-done:
-		// All replicas responded
-	case <-ctx.Done():
-		// Parent context cancelled
-		return ReadResult{
-			Success:      false,
-			Responses:    responses,
-			Required:     requiredR,
-			Replicas:     len(replicas),
-			ErrorMessage: fmt.Sprintf("context cancelled: %v", ctx.Err()),
+		select {
+		case res := <-results:
+			outstanding--
+			outcome := ReplicaOutcome{
+				ReplicaID: res.value.ReplicaID,
+				Success:   res.err == nil,
+				Class:     ClassifyError(res.err),
+				Err:       res.err,
+				Latency:   res.latency,
+			}
+			if res.err == nil {
+				outcome.Version = res.value.Version
+				outcomes = append(outcomes, outcome)
+				responses++
+				values = append(values, res.value)
+				continue
+			}
+			if outcome.Class == ErrorNotFound {
+				outcomes = append(outcomes, outcome)
+				responses++
+				continue
+			}
+			outcomes = append(outcomes, outcome)
+			errors = append(errors, fmt.Errorf("replica %s: %w", res.value.ReplicaID, res.err))
+			// Replace the failed read with the next untried replica
+			if responses+outstanding < requiredR && next < len(order) {
+				launch()
+				outstanding++
+			}
+		case <-hedge:
+			hedge = nil
+			if next < len(order) {
+				launch()
+				outstanding++
+				hedged = true
+			}
+		case <-ctx.Done():
+			// Parent context cancelled
+			return ReadResult{
+				Success:      false,
+				Responses:    responses,
+				Required:     requiredR,
+				Replicas:     len(replicas),
+				Contacted:    next,
+				Hedged:       hedged,
+				Outcomes:     finish(),
+				ErrorMessage: fmt.Sprintf("context cancelled: %v", ctx.Err()),
+			}
 		}
 	}
 
-	mu.Lock()
-	defer mu.Unlock()
-
 	if responses >= requiredR {
 		return ReadResult{
 			Success:   true,
 			Responses: responses,
 			Required:  requiredR,
 			Replicas:  len(replicas),
+			Contacted: next,
+			Hedged:    hedged,
 			Values:    values,
+			Outcomes:  finish(),
 		}
 	}
 
@@ -245,6 +403,9 @@ func DoRead(ctx context.Context, replicas []string, requiredR int, readFn Replic
 		Responses:    responses,
 		Required:     requiredR,
 		Replicas:     len(replicas),
+		Contacted:    next,
+		Hedged:       hedged,
+		Outcomes:     finish(),
 		ErrorMessage: errMsg,
 	}
 }
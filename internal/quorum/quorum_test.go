@@ -117,6 +117,26 @@ func TestDoRead_QuorumNotMet(t *testing.T) {
 	}
 }
 
+func TestDoRead_NotFoundCountsTowardQuorum(t *testing.T) {
+	replicas := []string{"r1", "r2", "r3"}
+
+	readFn := func(ctx context.Context, replicaID string) ([]byte, interface{}, bool, error) {
+		return nil, nil, false, ErrNotFound
+	}
+
+	result := DoRead(context.Background(), replicas, 2, readFn)
+
+	if !result.Success {
+		t.Fatalf("Expected success, got: %s", result.ErrorMessage)
+	}
+	if result.Responses != 2 || len(result.Values) != 0 {
+		t.Errorf("Expected 2 responses and no values, got %d and %d", result.Responses, len(result.Values))
+	}
+	if result.Contacted != 2 {
+		t.Errorf("Expected only 2 replicas contacted, got %d", result.Contacted)
+	}
+}
+
 func TestDoWrite_Timeout(t *testing.T) {
 	replicas := []string{"r1", "r2", "r3"}
 	requiredW := 2
@@ -0,0 +1,9 @@
+// Package ratelimit limits the request and byte rates of individual clients
+// with token buckets, so that one client cannot monopolize a node.
+//
+// Each client has a request bucket and a byte bucket that refill at the
+// configured rates up to their burst sizes. A request is admitted only if
+// both buckets hold enough tokens. Bytes read are only known after the
+// request completes, so they are charged afterwards and may leave the byte
+// bucket in debt, delaying the client's next requests.
+package ratelimit
@@ -0,0 +1,249 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets of idle clients are dropped.
+const sweepInterval = time.Minute
+
+// Limits are the rates allowed to one client. A zero rate is unlimited.
+type Limits struct {
+	Requests     float64 // Requests per second
+	RequestBurst int     // Requests allowed at once (0 = one second's worth)
+	Bytes        float64 // Bytes per second
+	ByteBurst    int     // Bytes allowed at once (0 = one second's worth)
+}
+
+// Validate checks that no rate or burst is negative.
+func (l Limits) Validate() error {
+	if l.Requests < 0 || l.Bytes < 0 {
+		return fmt.Errorf("rate limits cannot be negative")
+	}
+	if l.RequestBurst < 0 || l.ByteBurst < 0 {
+		return fmt.Errorf("rate limit bursts cannot be negative")
+	}
+	return nil
+}
+
+// Config holds the limits of all clients.
+type Config struct {
+	Default Limits
+	Clients map[string]Limits // Overrides by client name
+}
+
+// Validate checks the default and per-client limits.
+func (c Config) Validate() error {
+	if err := c.Default.Validate(); err != nil {
+		return err
+	}
+	for name, l := range c.Clients {
+		if err := l.Validate(); err != nil {
+			return fmt.Errorf("client %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// For returns the limits of a client.
+func (c Config) For(client string) Limits {
+	if l, ok := c.Clients[client]; ok {
+		return l
+	}
+	return c.Default
+}
+
+// LimitError is returned for a request that exceeds a client's limits.
+type LimitError struct {
+	Client     string
+	Limit      string // "request" or "byte"
+	RetryAfter time.Duration
+}
+
+func (e *LimitError) Error() string {
+	client := e.Client
+	if client == "" {
+		client = "anonymous"
+	}
+	return fmt.Sprintf("client %s exceeded its %s rate limit, retry after %v", client, e.Limit, e.RetryAfter)
+}
+
+// Stats summarizes limiter activity.
+type Stats struct {
+	Clients           int    // Clients being tracked
+	Allowed           uint64 // Requests admitted
+	ThrottledRequests uint64 // Requests rejected by a request rate limit
+	ThrottledBytes    uint64 // Requests rejected by a byte rate limit
+}
+
+// bucket is a token bucket. A nil bucket is unlimited.
+type bucket struct {
+	rate   float64 // Tokens per second
+	burst  float64
+	tokens float64 // Negative while in debt
+	last   time.Time
+}
+
+func newBucket(rate float64, burst int, now time.Time) *bucket {
+	if rate <= 0 {
+		return nil
+	}
+	b := float64(burst)
+	if burst == 0 {
+		b = math.Max(1, math.Ceil(rate))
+	}
+	return &bucket{rate: rate, burst: b, tokens: b, last: now}
+}
+
+// refill adds the tokens accrued since the last refill.
+func (b *bucket) refill(now time.Time) {
+	if b == nil {
+		return
+	}
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// wait returns how long until n tokens are available, or 0 if they are.
+// Requests larger than the burst wait for a full bucket.
+func (b *bucket) wait(n float64) time.Duration {
+	if b == nil {
+		return 0
+	}
+	need := math.Min(n, b.burst)
+	if b.tokens >= need {
+		return 0
+	}
+	return time.Duration((need - b.tokens) / b.rate * float64(time.Second))
+}
+
+func (b *bucket) take(n float64) {
+	if b != nil {
+		b.tokens -= n
+	}
+}
+
+func (b *bucket) full() bool {
+	return b == nil || b.tokens >= b.burst
+}
+
+// client holds the buckets of one client.
+type client struct {
+	requests *bucket
+	bytes    *bucket
+}
+
+// Limiter enforces per-client rate limits. It is safe for concurrent use.
+type Limiter struct {
+	mu        sync.Mutex
+	config    Config
+	clients   map[string]*client
+	stats     Stats
+	lastSweep time.Time
+	now       func() time.Time // overridable in tests
+}
+
+// NewLimiter creates a limiter with the given limits.
+func NewLimiter(config Config) *Limiter {
+	return &Limiter{
+		config:    config,
+		clients:   make(map[string]*client),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// SetConfig replaces the limits. Buckets restart full.
+func (l *Limiter) SetConfig(config Config) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.config = config
+	l.clients = make(map[string]*client)
+}
+
+// Allow admits a request of a client carrying bytes, or returns a
+// *LimitError if it exceeds the client's request or byte rate.
+func (l *Limiter) Allow(name string, bytes int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	c := l.client(name)
+	if c == nil {
+		l.stats.Allowed++
+		return nil
+	}
+	if wait := c.requests.wait(1); wait > 0 {
+		l.stats.ThrottledRequests++
+		return &LimitError{Client: name, Limit: "request", RetryAfter: wait}
+	}
+	if wait := c.bytes.wait(float64(bytes)); wait > 0 {
+		l.stats.ThrottledBytes++
+		return &LimitError{Client: name, Limit: "byte", RetryAfter: wait}
+	}
+	c.requests.take(1)
+	c.bytes.take(float64(bytes))
+	l.stats.Allowed++
+	return nil
+}
+
+// Charge takes bytes from a client's byte bucket after a request, for sizes
+// only known once it completed (e.g. values read).
+func (l *Limiter) Charge(name string, bytes int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if c := l.client(name); c != nil {
+		c.bytes.take(float64(bytes))
+	}
+}
+
+// Stats returns a snapshot of the limiter's statistics.
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := l.stats
+	stats.Clients = len(l.clients)
+	return stats
+}
+
+// client returns the refilled buckets of a client, or nil if it is
+// unlimited. Must be called with mu held.
+func (l *Limiter) client(name string) *client {
+	now := l.now()
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		l.sweep(now)
+	}
+
+	c, ok := l.clients[name]
+	if !ok {
+		limits := l.config.For(name)
+		if limits.Requests <= 0 && limits.Bytes <= 0 {
+			return nil
+		}
+		c = &client{
+			requests: newBucket(limits.Requests, limits.RequestBurst, now),
+			bytes:    newBucket(limits.Bytes, limits.ByteBurst, now),
+		}
+		l.clients[name] = c
+	}
+	c.requests.refill(now)
+	c.bytes.refill(now)
+	return c
+}
+
+// sweep drops the buckets of clients that have been idle long enough to
+// refill them; they would be recreated full. Must be called with mu held.
+func (l *Limiter) sweep(now time.Time) {
+	for name, c := range l.clients {
+		c.requests.refill(now)
+		c.bytes.refill(now)
+		if c.requests.full() && c.bytes.full() {
+			delete(l.clients, name)
+		}
+	}
+	l.lastSweep = now
+}
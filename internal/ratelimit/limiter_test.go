@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestLimiter(config Config) (*Limiter, *time.Time) {
+	now := time.Unix(1700000000, 0)
+	l := NewLimiter(config)
+	l.now = func() time.Time { return now }
+	l.lastSweep = now
+	return l, &now
+}
+
+func TestLimiter_RequestRate(t *testing.T) {
+	l, now := newTestLimiter(Config{Default: Limits{Requests: 2, RequestBurst: 3}})
+
+	for i := 0; i < 3; i++ {
+		if err := l.Allow("batch", 0); err != nil {
+			t.Fatalf("Request %d within burst rejected: %v", i, err)
+		}
+	}
+	err := l.Allow("batch", 0)
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != "request" || limitErr.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("Expected request limit with 500ms retry, got %v", err)
+	}
+	if err := l.Allow("web", 0); err != nil {
+		t.Errorf("Expected other clients to have their own bucket, got %v", err)
+	}
+
+	*now = now.Add(500 * time.Millisecond)
+	if err := l.Allow("batch", 0); err != nil {
+		t.Errorf("Expected a token after refill, got %v", err)
+	}
+
+	stats := l.Stats()
+	if stats.Allowed != 5 || stats.ThrottledRequests != 1 || stats.Clients != 2 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestLimiter_ByteRate(t *testing.T) {
+	l, now := newTestLimiter(Config{Default: Limits{Bytes: 100}})
+
+	if err := l.Allow("c", 60); err != nil {
+		t.Fatalf("Write within burst rejected: %v", err)
+	}
+	if err := l.Allow("c", 60); err == nil {
+		t.Fatal("Expected write beyond the byte burst to be rejected")
+	}
+
+	// Reads are charged afterwards and may leave the bucket in debt
+	l.Charge("c", 190)
+	*now = now.Add(time.Second)
+	if err := l.Allow("c", 0); err == nil {
+		t.Fatal("Expected client in debt to be rejected")
+	}
+	*now = now.Add(time.Second)
+	if err := l.Allow("c", 0); err != nil {
+		t.Errorf("Expected debt to be repaid after refill, got %v", err)
+	}
+
+	// A request larger than the burst passes once the bucket is full
+	*now = now.Add(time.Second)
+	if err := l.Allow("c", 500); err != nil {
+		t.Errorf("Expected oversized request to pass with a full bucket, got %v", err)
+	}
+	if stats := l.Stats(); stats.ThrottledBytes != 2 {
+		t.Errorf("Expected 2 byte throttles, got %+v", stats)
+	}
+}
+
+func TestLimiter_Overrides(t *testing.T) {
+	l, now := newTestLimiter(Config{
+		Default: Limits{Requests: 1},
+		Clients: map[string]Limits{"ops": {}},
+	})
+	for i := 0; i < 10; i++ {
+		if err := l.Allow("ops", 0); err != nil {
+			t.Fatalf("Expected unlimited override, got %v", err)
+		}
+	}
+	l.Allow("batch", 0)
+	if err := l.Allow("batch", 0); err == nil {
+		t.Error("Expected default limit for other clients")
+	}
+
+	// Idle clients are forgotten
+	*now = now.Add(sweepInterval)
+	l.Allow("web", 0)
+	if stats := l.Stats(); stats.Clients != 1 {
+		t.Errorf("Expected idle buckets to be swept, got %d clients", stats.Clients)
+	}
+
+	l.SetConfig(Config{})
+	if err := l.Allow("batch", 0); err != nil || l.Stats().Clients != 0 {
+		t.Errorf("Expected no limits after SetConfig, got %v", err)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"unlimited", Config{}, false},
+		{"limits", Config{Default: Limits{Requests: 100, Bytes: 1 << 20, ByteBurst: 4 << 20}}, false},
+		{"negative rate", Config{Default: Limits{Requests: -1}}, true},
+		{"negative client burst", Config{Clients: map[string]Limits{"a": {RequestBurst: -1}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
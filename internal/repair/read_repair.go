@@ -3,11 +3,17 @@ package repair
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"kvstore/internal/admission"
 	"kvstore/internal/clock"
 	kvstorepb "kvstore/internal/gen/api"
+	"kvstore/internal/logging"
+	"kvstore/internal/tracing"
 )
 
 // ReadRepairer performs asynchronous read repair to converge stale replicas.
@@ -15,6 +21,38 @@ type ReadRepairer struct {
 	// clientProvider returns an internal client for a given node address
 	clientProvider func(addr string) (kvstorepb.KVInternalClient, error)
 	timeout        time.Duration
+	admission      *admission.Controller // Bounds concurrent repairs (nil = unbounded)
+	tracer         trace.Tracer
+	log            *slog.Logger
+
+	triggered atomic.Uint64
+	skipped   atomic.Uint64
+	succeeded atomic.Uint64
+	failed    atomic.Uint64
+	pending   atomic.Int64
+}
+
+// Stats counts read repairs since the repairer was created.
+type Stats struct {
+	Triggered uint64 // Reads that found stale replicas
+	Skipped   uint64 // Repairs shed by admission control
+	Succeeded uint64 // Stale replicas repaired
+	Failed    uint64 // Stale replicas whose repair failed
+}
+
+// Stats returns a snapshot of the repair counters.
+func (r *ReadRepairer) Stats() Stats {
+	return Stats{
+		Triggered: r.triggered.Load(),
+		Skipped:   r.skipped.Load(),
+		Succeeded: r.succeeded.Load(),
+		Failed:    r.failed.Load(),
+	}
+}
+
+// Pending returns the number of repairs in progress.
+func (r *ReadRepairer) Pending() int64 {
+	return r.pending.Load()
 }
 
 // NewReadRepairer creates a new read repairer.
@@ -25,30 +63,68 @@ func NewReadRepairer(clientProvider func(addr string) (kvstorepb.KVInternalClien
 	return &ReadRepairer{
 		clientProvider: clientProvider,
 		timeout:        timeout,
+		tracer:         tracing.Tracer(nil),
+		log:            logging.For(logging.Repair),
 	}
 }
 
+// SetAdmission makes repairs take a slot of c at repair priority. Repairs
+// that find no slot are skipped; a later read repairs the key again.
+func (r *ReadRepairer) SetAdmission(c *admission.Controller) {
+	r.admission = c
+}
+
+// SetTracer traces repairs, each in a new trace linked to the read that
+// triggered it.
+func (r *ReadRepairer) SetTracer(tp trace.TracerProvider) {
+	r.tracer = tracing.Tracer(tp)
+}
+
+// SetLogger sets the logger of repairs.
+func (r *ReadRepairer) SetLogger(l *slog.Logger) {
+	r.log = l
+}
+
 // Repair asynchronously repairs stale replicas with winning versions.
 // This is fire-and-forget: it logs errors but does not block or retry.
-// ctx should be detached from the request context (use context.Background()).
+// ctx must not be canceled with the request (see context.WithoutCancel); the
+// repair is linked to the span it holds.
 func (r *ReadRepairer) Repair(ctx context.Context, key string, winners []VersionedValue, stale map[string]VersionedValue, replicaIDToAddr map[string]string) {
 	if len(stale) == 0 {
 		return // Nothing to repair
 	}
+	r.triggered.Add(1)
+
+	release := func() {}
+	if r.admission != nil {
+		var err error
+		if release, err = r.admission.Acquire(ctx, admission.Repair); err != nil {
+			r.skipped.Add(1)
+			return // Overloaded; counted as shed, not logged, to spare the node
+		}
+	}
 
 	// Fire-and-forget: run in goroutine
+	r.pending.Add(1)
 	go func() {
+		defer r.pending.Add(-1)
+		defer release()
 		defer func() {
 			if err := recover(); err != nil {
-				log.Printf("Read repair panic for key %s: %v", key, err)
+				r.log.Error("Read repair panic", logging.Key(key), slog.Any("panic", err))
 			}
 		}()
 
 		// Use detached context with timeout
 		repairCtx, cancel := context.WithTimeout(context.Background(), r.timeout)
 		defer cancel()
+		repairCtx, span := r.tracer.Start(repairCtx, "read_repair",
+			trace.WithNewRoot(),
+			trace.WithLinks(trace.LinkFromContext(ctx)),
+			trace.WithAttributes(attribute.String("kvstore.key", key), attribute.Int("kvstore.repair.stale", len(stale))))
+		defer span.End()
 
-		log.Printf("Read repair triggered for key=%s: %d stale replicas, %d winners", key, len(stale), len(winners))
+		r.log.Debug("Read repair triggered", logging.Key(key), slog.Int("stale", len(stale)), slog.Int("winners", len(winners)))
 
 		repairCount := 0
 		failureCount := 0
@@ -57,20 +133,24 @@ func (r *ReadRepairer) Repair(ctx context.Context, key string, winners []Version
 			// Skip if we don't have address mapping
 			addr, exists := replicaIDToAddr[replicaID]
 			if !exists {
-				log.Printf("Read repair: skipping replica %s (no address)", replicaID)
+				r.log.Warn("Read repair skipping replica without address", logging.Key(key), slog.String("replica", replicaID))
 				continue
 			}
 
 			// Repair this replica
 			if err := r.repairReplica(repairCtx, addr, key, winners, staleValue); err != nil {
-				log.Printf("Read repair failed for replica %s (key=%s): %v", replicaID, key, err)
+				r.log.Warn("Read repair failed", logging.Key(key), slog.String("replica", replicaID), logging.Err(err))
 				failureCount++
+				r.failed.Add(1)
+				tracing.SetError(span, err)
 			} else {
 				repairCount++
+				r.succeeded.Add(1)
 			}
 		}
 
-		log.Printf("Read repair completed for key=%s: %d repaired, %d failed", key, repairCount, failureCount)
+		r.log.Debug("Read repair completed", logging.Key(key), slog.Int("repaired", repairCount), slog.Int("failed", failureCount))
+		span.SetAttributes(attribute.Int("kvstore.repair.repaired", repairCount), attribute.Int("kvstore.repair.failed", failureCount))
 	}()
 }
 
@@ -103,16 +183,41 @@ func (r *ReadRepairer) repairReplica(ctx context.Context, addr string, key strin
 	return fmt.Errorf("no winners to repair with")
 }
 
+// Write writes a version of key to the replica at addr and waits for it. As
+// with read repair, the replica keeps a version that is newer or concurrent.
+func (r *ReadRepairer) Write(ctx context.Context, addr, key string, vv VersionedValue) error {
+	client, err := r.clientProvider(addr)
+	if err != nil {
+		return fmt.Errorf("failed to get client: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return r.writeVersion(ctx, client, key, vv)
+}
+
 // writeVersion writes a version to a replica (put or delete/tombstone).
 func (r *ReadRepairer) writeVersion(ctx context.Context, client kvstorepb.KVInternalClient, key string, vv VersionedValue) error {
+	encodedVersion, err := clock.Encode(vv.Version, nil)
+	if err != nil {
+		return fmt.Errorf("failed to encode version: %w", err)
+	}
+
 	req := &kvstorepb.ReplicaPutRequest{
-		Key:           key,
-		Value:         vv.Value,
-		Version:       vectorClockToProto(vv.Version),
-		CoordinatorId: "read-repair", // Special ID for repair operations
-		RequestId:     fmt.Sprintf("repair-%d", time.Now().UnixNano()),
-		Deleted:       vv.Deleted,
-		IsRepair:      true, // Mark as repair to prevent clock increments
+		Key:            key,
+		Value:          vv.Value,
+		EncodedVersion: encodedVersion,
+		CoordinatorId:  "read-repair", // Special ID for repair operations
+		RequestId:      fmt.Sprintf("repair-%d", time.Now().UnixNano()),
+		Deleted:        vv.Deleted,
+		IsRepair:       true, // Mark as repair to prevent clock increments
+		Timestamp: &kvstorepb.HLCTimestamp{
+			WallTimeMs: vv.Timestamp.WallTime,
+			Logical:    vv.Timestamp.Logical,
+			NodeId:     vv.Timestamp.NodeID,
+		},
+	}
+	if vv.ExpiresAt != nil {
+		req.ExpiresAtMs = vv.ExpiresAt.UnixMilli()
 	}
 
 	resp, err := client.ReplicaPut(ctx, req)
@@ -126,22 +231,3 @@ func (r *ReadRepairer) writeVersion(ctx context.Context, client kvstorepb.KVInte
 
 	return nil
 }
-
-// vectorClockToProto converts a vector clock to protobuf format.
-func vectorClockToProto(vc clock.VectorClock) *kvstorepb.VectorClock {
-	if vc == nil {
-		return &kvstorepb.VectorClock{}
-	}
-
-	entries := make([]*kvstorepb.VectorClockEntry, 0)
-	for nodeID, counter := range vc {
-		entries = append(entries, &kvstorepb.VectorClockEntry{
-			NodeId:  nodeID,
-			Counter: int64(counter),
-		})
-	}
-
-	return &kvstorepb.VectorClock{
-		Entries: entries,
-	}
-}
@@ -2,7 +2,6 @@ package repair
 
 import (
 	"context"
-	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -12,13 +11,16 @@ import (
 	kvstorepb "kvstore/internal/gen/api"
 )
 
-// mockInternalClient is a mock for testing read repair
+// mockInternalClient is a mock for testing read repair. Only ReplicaPut is
+// implemented; other methods panic on the nil embedded client.
 type mockInternalClient struct {
+	kvstorepb.KVInternalClient
+
 	mu          sync.Mutex
 	putCalled   bool
 	putKey      string
 	putValue    []byte
-	putVersion  *kvstorepb.VectorClock
+	putVersion  []byte
 	putDeleted  bool
 	putIsRepair bool
 	putError    error
@@ -31,7 +33,7 @@ func (m *mockInternalClient) ReplicaPut(ctx context.Context, req *kvstorepb.Repl
 	m.putCalled = true
 	m.putKey = req.Key
 	m.putValue = req.Value
-	m.putVersion = req.Version
+	m.putVersion = req.EncodedVersion
 	m.putDeleted = req.Deleted
 	m.putIsRepair = req.IsRepair
 
@@ -44,14 +46,6 @@ func (m *mockInternalClient) ReplicaPut(ctx context.Context, req *kvstorepb.Repl
 	}, nil
 }
 
-func (m *mockInternalClient) ReplicaGet(ctx context.Context, req *kvstorepb.ReplicaGetRequest, opts ...grpc.CallOption) (*kvstorepb.ReplicaGetResponse, error) {
-	return nil, errors.New("not implemented")
-}
-
-func (m *mockInternalClient) ReplicaDelete(ctx context.Context, req *kvstorepb.ReplicaDeleteRequest, opts ...grpc.CallOption) (*kvstorepb.ReplicaDeleteResponse, error) {
-	return nil, errors.New("not implemented")
-}
-
 func TestReadRepairer_Repair_SingleWinner(t *testing.T) {
 	mockClient := &mockInternalClient{}
 
@@ -113,6 +107,20 @@ func TestReadRepairer_Repair_SingleWinner(t *testing.T) {
 	if !mockClient.putIsRepair {
 		t.Error("Expected is_repair to be true")
 	}
+	putVersion, _, err := clock.Decode(mockClient.putVersion)
+	if err != nil {
+		t.Fatalf("Failed to decode repaired version: %v", err)
+	}
+	if !putVersion.Equal(vc) {
+		t.Errorf("Expected exact winner version %v, got %v", vc, putVersion)
+	}
+
+	for i := 0; i < 10 && repairer.Stats().Succeeded == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := repairer.Stats(); got != (Stats{Triggered: 1, Succeeded: 1}) {
+		t.Errorf("Stats() = %+v, want 1 triggered and 1 succeeded", got)
+	}
 }
 
 func TestReadRepairer_Repair_NoStale(t *testing.T) {
@@ -143,4 +151,7 @@ func TestReadRepairer_Repair_NoStale(t *testing.T) {
 	if mockClient.putCalled {
 		t.Error("Expected ReplicaPut NOT to be called when no stale replicas")
 	}
+	if got := repairer.Stats(); got != (Stats{}) {
+		t.Errorf("Stats() = %+v, want no repairs counted", got)
+	}
 }
@@ -2,14 +2,18 @@ package repair
 
 import (
 	"kvstore/internal/clock"
+	"kvstore/internal/conflict"
+	"time"
 )
 
 // VersionedValue represents a value with its vector clock version.
 // This is used for reconciliation and is compatible with storage.VersionedValue.
 type VersionedValue struct {
-	Value   []byte
-	Version clock.VectorClock
-	Deleted bool
+	Value     []byte
+	Version   clock.VectorClock
+	Timestamp clock.Timestamp
+	Deleted   bool
+	ExpiresAt *time.Time // nil if the value does not expire
 }
 
 // ReconcileResult represents the result of reconciling multiple versions.
@@ -28,6 +32,15 @@ type ReconcileResult struct {
 // It returns winners (non-dominated versions) and stale versions (dominated ones).
 // replicaIDs should correspond 1:1 with values (for tracking which replica returned which version).
 func Reconcile(values []VersionedValue, replicaIDs []string) ReconcileResult {
+	return ReconcileWithPolicy(values, replicaIDs, conflict.Siblings)
+}
+
+// ReconcileWithPolicy is like Reconcile but resolves concurrent winners
+// according to policy. Under conflict.LWW the winner with the newest HLC
+// timestamp (ties broken on node ID) is the only winner, and every replica
+// that returned a different version is reported as stale so that read
+// repair converges to the winner.
+func ReconcileWithPolicy(values []VersionedValue, replicaIDs []string, policy conflict.Policy) ReconcileResult {
 	if len(values) == 0 {
 		return ReconcileResult{
 			Winners: []VersionedValue{},
@@ -98,6 +111,23 @@ func Reconcile(values []VersionedValue, replicaIDs []string) ReconcileResult {
 		}
 	}
 
+	// Last-write-wins: collapse concurrent siblings to the newest timestamp
+	if policy == conflict.LWW && len(winners) > 1 {
+		winner := winners[0]
+		for _, w := range winners[1:] {
+			if w.Timestamp.Newer(winner.Timestamp) {
+				winner = w
+			}
+		}
+		winners = []VersionedValue{winner}
+
+		for _, v := range allVersions {
+			if !v.value.Version.Equal(winner.Version) {
+				stale[v.replicaID] = v.value
+			}
+		}
+	}
+
 	return ReconcileResult{
 		Winners: winners,
 		Stale:   stale,
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"kvstore/internal/clock"
+	"kvstore/internal/conflict"
 )
 
 func TestReconcile_SingleWinner(t *testing.T) {
@@ -230,3 +231,75 @@ func TestReconcile_MixedDominanceAndConcurrency(t *testing.T) {
 		t.Errorf("Expected 1 stale version (vc2), got %d", len(result.Stale))
 	}
 }
+
+func TestReconcileWithPolicy_LWWPicksNewestTimestamp(t *testing.T) {
+	vc1 := clock.New()
+	vc1.Set("n1", 1)
+
+	vc2 := clock.New()
+	vc2.Set("n2", 1)
+
+	values := []VersionedValue{
+		{Value: []byte("older"), Version: vc1, Timestamp: clock.Timestamp{WallTime: 100, NodeID: "n1"}},
+		{Value: []byte("newer"), Version: vc2, Timestamp: clock.Timestamp{WallTime: 200, NodeID: "n2"}},
+		{Value: []byte("older"), Version: vc1, Timestamp: clock.Timestamp{WallTime: 100, NodeID: "n1"}},
+	}
+
+	result := ReconcileWithPolicy(values, []string{"r1", "r2", "r3"}, conflict.LWW)
+
+	if !result.IsResolved() {
+		t.Fatalf("Expected single winner under LWW, got %d winners", len(result.Winners))
+	}
+	if string(result.Winners[0].Value) != "newer" {
+		t.Errorf("Expected winner 'newer', got '%s'", string(result.Winners[0].Value))
+	}
+	// Replicas holding the losing sibling must be repaired
+	if len(result.Stale) != 2 {
+		t.Errorf("Expected 2 stale replicas, got %d", len(result.Stale))
+	}
+	if _, ok := result.Stale["r2"]; ok {
+		t.Error("Winning replica r2 should not be stale")
+	}
+}
+
+func TestReconcileWithPolicy_LWWTieBreakOnNodeID(t *testing.T) {
+	vc1 := clock.New()
+	vc1.Set("n1", 1)
+
+	vc2 := clock.New()
+	vc2.Set("n2", 1)
+
+	ts := clock.Timestamp{WallTime: 100, Logical: 0}
+	tsA, tsB := ts, ts
+	tsA.NodeID = "n1"
+	tsB.NodeID = "n2"
+
+	// Winner must not depend on response order
+	for _, order := range [][]VersionedValue{
+		{{Value: []byte("a"), Version: vc1, Timestamp: tsA}, {Value: []byte("b"), Version: vc2, Timestamp: tsB}},
+		{{Value: []byte("b"), Version: vc2, Timestamp: tsB}, {Value: []byte("a"), Version: vc1, Timestamp: tsA}},
+	} {
+		result := ReconcileWithPolicy(order, []string{"r1", "r2"}, conflict.LWW)
+		if !result.IsResolved() || string(result.Winners[0].Value) != "b" {
+			t.Errorf("Expected deterministic winner 'b', got %v", result.Winners)
+		}
+	}
+}
+
+func TestReconcileWithPolicy_SiblingsKeepsConflicts(t *testing.T) {
+	vc1 := clock.New()
+	vc1.Set("n1", 1)
+
+	vc2 := clock.New()
+	vc2.Set("n2", 1)
+
+	values := []VersionedValue{
+		{Value: []byte("a"), Version: vc1, Timestamp: clock.Timestamp{WallTime: 100}},
+		{Value: []byte("b"), Version: vc2, Timestamp: clock.Timestamp{WallTime: 200}},
+	}
+
+	result := ReconcileWithPolicy(values, []string{"r1", "r2"}, conflict.Siblings)
+	if !result.HasConflict() {
+		t.Error("Expected siblings policy to keep concurrent versions")
+	}
+}
@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"kvstore/internal/clock"
+)
+
+const (
+	// RecordV1 is the version tag of the current on-disk value record format.
+	RecordV1 byte = 1
+
+	recordFlagDeleted byte = 1 << 0
+	recordFlagExpires byte = 1 << 1
+)
+
+// EncodeValue returns the binary on-disk representation of vv.
+//
+// Format (v1):
+//
+//	version   byte     RecordV1
+//	flags     byte     bit 0: tombstone, bit 1: expiry present
+//	clock     bytes    clock.Encode(Version, EntryTimes)
+//	wall      varint   HLC wall time (Unix ms)
+//	logical   varint   HLC logical counter
+//	node      uvarint length + bytes (HLC node ID)
+//	expires   varint   expiry (Unix ms), only if flag set
+//	value     uvarint length + bytes
+func EncodeValue(vv *VersionedValue) ([]byte, error) {
+	clockBytes, err := clock.Encode(vv.Version, vv.EntryTimes)
+	if err != nil {
+		return nil, err
+	}
+
+	var flags byte
+	if vv.Deleted {
+		flags |= recordFlagDeleted
+	}
+	if vv.ExpiresAt != nil {
+		flags |= recordFlagExpires
+	}
+
+	buf := make([]byte, 0, 2+len(clockBytes)+4*binary.MaxVarintLen64+len(vv.Timestamp.NodeID)+len(vv.Value))
+	buf = append(buf, RecordV1, flags)
+	buf = append(buf, clockBytes...)
+	buf = binary.AppendVarint(buf, vv.Timestamp.WallTime)
+	buf = binary.AppendVarint(buf, int64(vv.Timestamp.Logical))
+	buf = binary.AppendUvarint(buf, uint64(len(vv.Timestamp.NodeID)))
+	buf = append(buf, vv.Timestamp.NodeID...)
+	if vv.ExpiresAt != nil {
+		buf = binary.AppendVarint(buf, vv.ExpiresAt.UnixMilli())
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(vv.Value)))
+	buf = append(buf, vv.Value...)
+	return buf, nil
+}
+
+// DecodeValue decodes a record produced by EncodeValue.
+func DecodeValue(data []byte) (*VersionedValue, error) {
+	if len(data) < 2 {
+		return nil, fmt.Errorf("value record too short: %d bytes", len(data))
+	}
+	if data[0] != RecordV1 {
+		return nil, fmt.Errorf("unsupported value record version %d", data[0])
+	}
+	flags := data[1]
+	if flags&^(recordFlagDeleted|recordFlagExpires) != 0 {
+		return nil, fmt.Errorf("unsupported value record flags %#x", flags)
+	}
+	pos := 2
+
+	version, entryTimes, n, err := clock.DecodePrefix(data[pos:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode version: %w", err)
+	}
+	pos += n
+
+	readVarint := func() (int64, error) {
+		v, n := binary.Varint(data[pos:])
+		if n <= 0 {
+			return 0, fmt.Errorf("malformed varint at offset %d", pos)
+		}
+		pos += n
+		return v, nil
+	}
+	readBytes := func() ([]byte, error) {
+		length, n := binary.Uvarint(data[pos:])
+		if n <= 0 {
+			return nil, fmt.Errorf("malformed varint at offset %d", pos)
+		}
+		pos += n
+		if length > uint64(len(data)-pos) {
+			return nil, fmt.Errorf("field length %d exceeds remaining %d bytes", length, len(data)-pos)
+		}
+		b := data[pos : pos+int(length)]
+		pos += int(length)
+		return b, nil
+	}
+
+	vv := &VersionedValue{
+		Version:    version,
+		EntryTimes: entryTimes,
+		Deleted:    flags&recordFlagDeleted != 0,
+	}
+
+	if vv.Timestamp.WallTime, err = readVarint(); err != nil {
+		return nil, err
+	}
+	logical, err := readVarint()
+	if err != nil {
+		return nil, err
+	}
+	vv.Timestamp.Logical = int32(logical)
+	nodeID, err := readBytes()
+	if err != nil {
+		return nil, err
+	}
+	vv.Timestamp.NodeID = string(nodeID)
+
+	if flags&recordFlagExpires != 0 {
+		ms, err := readVarint()
+		if err != nil {
+			return nil, err
+		}
+		expiresAt := time.UnixMilli(ms)
+		vv.ExpiresAt = &expiresAt
+	}
+
+	value, err := readBytes()
+	if err != nil {
+		return nil, err
+	}
+	if len(value) > 0 {
+		vv.Value = append([]byte(nil), value...)
+	}
+
+	if pos != len(data) {
+		return nil, fmt.Errorf("value record has %d trailing bytes", len(data)-pos)
+	}
+	return vv, nil
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"kvstore/internal/clock"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestEncodeValue_Golden(t *testing.T) {
+	expiresAt := time.UnixMilli(1700000060000)
+	tests := []struct {
+		golden string
+		vv     *VersionedValue
+	}{
+		{
+			golden: "value_v1.golden",
+			vv: &VersionedValue{
+				Value:      []byte("hello"),
+				Version:    clock.VectorClock{"n1": 3, "n2": 1},
+				Timestamp:  clock.Timestamp{WallTime: 1700000000000, Logical: 2, NodeID: "n1"},
+				EntryTimes: clock.EntryTimes{"n1": 1700000000000, "n2": 1699999999000},
+			},
+		},
+		{
+			golden: "value_v1_tombstone_ttl.golden",
+			vv: &VersionedValue{
+				Version:    clock.VectorClock{"n2": 4},
+				Timestamp:  clock.Timestamp{WallTime: 1700000000500, NodeID: "n2"},
+				EntryTimes: clock.EntryTimes{"n2": 1700000000500},
+				Deleted:    true,
+				ExpiresAt:  &expiresAt,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			data, err := EncodeValue(tt.vv)
+			if err != nil {
+				t.Fatalf("EncodeValue failed: %v", err)
+			}
+
+			path := filepath.Join("testdata", tt.golden)
+			if *update {
+				if err := os.WriteFile(path, data, 0644); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if !bytes.Equal(data, want) {
+				t.Errorf("encoding does not match %s:\n got  %x\n want %x", path, data, want)
+			}
+
+			// Golden records must always decode, even after format changes
+			decoded, err := DecodeValue(want)
+			if err != nil {
+				t.Fatalf("DecodeValue failed: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, tt.vv) {
+				t.Errorf("Round trip mismatch:\n got  %+v\n want %+v", decoded, tt.vv)
+			}
+		})
+	}
+}
+
+func TestDecodeValue_RejectsUnknownVersion(t *testing.T) {
+	data, err := EncodeValue(&VersionedValue{Version: clock.New()})
+	if err != nil {
+		t.Fatalf("EncodeValue failed: %v", err)
+	}
+	data[0] = RecordV1 + 1
+	if _, err := DecodeValue(data); err == nil {
+		t.Error("Expected error for unknown record version")
+	}
+}
+
+func TestDecodeValue_RejectsTruncated(t *testing.T) {
+	data, err := EncodeValue(&VersionedValue{
+		Value:     []byte("value"),
+		Version:   clock.VectorClock{"n1": 1},
+		Timestamp: clock.Timestamp{WallTime: 1, NodeID: "n1"},
+	})
+	if err != nil {
+		t.Fatalf("EncodeValue failed: %v", err)
+	}
+	for i := 0; i < len(data); i++ {
+		if _, err := DecodeValue(data[:i]); err == nil {
+			t.Errorf("Expected error decoding %d-byte prefix", i)
+		}
+	}
+}
@@ -1,4 +1,7 @@
 // Package storage provides the local key-value storage interface and
 // in-memory implementation. The storage layer tracks vector clocks for
-// each value to enable conflict detection and resolution.
+// each value to enable conflict detection and resolution. Keys of a
+// namespace are stored with the namespace as a prefix (see NamespacedKey).
+// A store can be written to and loaded from a snapshot of value records
+// (see EncodeValue and WriteSnapshot).
 package storage
@@ -0,0 +1,30 @@
+package storage
+
+import "strings"
+
+// namespaceSeparator ends the namespace part of a stored key. Client keys
+// cannot contain it (see ValidKey), so stored keys of different namespaces
+// never collide.
+const namespaceSeparator = "\x00"
+
+// NamespacedKey returns the stored key of key in a namespace. Keys of the
+// default namespace ("") are stored as is.
+func NamespacedKey(namespace, key string) string {
+	if namespace == "" {
+		return key
+	}
+	return namespace + namespaceSeparator + key
+}
+
+// SplitKey returns the namespace and client key of a stored key.
+func SplitKey(stored string) (namespace, key string) {
+	if ns, key, ok := strings.Cut(stored, namespaceSeparator); ok {
+		return ns, key
+	}
+	return "", stored
+}
+
+// ValidKey reports whether a client key can be stored.
+func ValidKey(key string) bool {
+	return key != "" && !strings.Contains(key, namespaceSeparator)
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// snapshotMagic starts every snapshot, followed by the format version.
+var snapshotMagic = []byte("KVSNAP")
+
+const (
+	// SnapshotV1 is the version tag of the current snapshot format.
+	SnapshotV1 byte = 1
+
+	maxSnapshotField = 1 << 30 // Longest key or value record accepted on load
+)
+
+// WriteSnapshot writes every value and tombstone in the store to w and
+// returns the number of records written. Expired values are skipped.
+//
+// Format (v1):
+//
+//	magic     "KVSNAP"
+//	version   byte     SnapshotV1
+//	records, in key order, until the end of the stream:
+//	  key     uvarint length + bytes
+//	  value   uvarint length + bytes (EncodeValue)
+//
+// The records are copied under the read lock and written after releasing it,
+// so writes are not blocked while the snapshot is written.
+func (s *InMemoryStore) WriteSnapshot(w io.Writer) (int, error) {
+	s.mu.RLock()
+	keys := make([]string, 0, len(s.data))
+	values := make(map[string]*VersionedValue, len(s.data))
+	for key, vv := range s.data {
+		if vv.IsExpired() {
+			continue
+		}
+		keys = append(keys, key)
+		values[key] = vv // Stored values are replaced, never modified
+	}
+	s.mu.RUnlock()
+	sort.Strings(keys)
+
+	bw := bufio.NewWriter(w)
+	bw.Write(snapshotMagic)
+	bw.WriteByte(SnapshotV1)
+	var buf []byte
+	for _, key := range keys {
+		record, err := EncodeValue(values[key])
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode %q: %w", key, err)
+		}
+		buf = binary.AppendUvarint(buf[:0], uint64(len(key)))
+		buf = append(buf, key...)
+		buf = binary.AppendUvarint(buf, uint64(len(record)))
+		buf = append(buf, record...)
+		if _, err := bw.Write(buf); err != nil {
+			return 0, err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return 0, err
+	}
+	return len(keys), nil
+}
+
+// LoadSnapshot reads a snapshot written by WriteSnapshot into the store and
+// returns the number of records loaded. Versions are kept as written, without
+// incrementing or pruning their clocks. A key already in the store is only
+// replaced by a version that dominates it, as with PutRepair. Records read
+// before an error are kept.
+func (s *InMemoryStore) LoadSnapshot(r io.Reader) (int, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(snapshotMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return 0, fmt.Errorf("failed to read snapshot header: %w", err)
+	}
+	if !bytes.Equal(header[:len(snapshotMagic)], snapshotMagic) {
+		return 0, errors.New("not a snapshot")
+	}
+	if header[len(snapshotMagic)] != SnapshotV1 {
+		return 0, fmt.Errorf("unsupported snapshot version %d", header[len(snapshotMagic)])
+	}
+
+	loaded := 0
+	for {
+		key, err := readSnapshotField(br)
+		if err == io.EOF {
+			return loaded, nil
+		}
+		if err != nil {
+			return loaded, fmt.Errorf("failed to read key of record %d: %w", loaded+1, err)
+		}
+		record, err := readSnapshotField(br)
+		if err != nil {
+			return loaded, fmt.Errorf("failed to read value of %q: %w", key, unexpectedEOF(err))
+		}
+		vv, err := DecodeValue(record)
+		if err != nil {
+			return loaded, fmt.Errorf("failed to decode %q: %w", key, err)
+		}
+		s.load(string(key), vv)
+		loaded++
+	}
+}
+
+// load stores a decoded snapshot record unless the key holds a version that
+// it does not dominate.
+func (s *InMemoryStore) load(key string, vv *VersionedValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.data[key]; ok && !existing.IsExpired() && !vv.Version.Dominates(existing.Version) {
+		return
+	}
+	s.set(key, vv)
+}
+
+// readSnapshotField reads a length-prefixed field. It returns io.EOF only at
+// the end of the stream before the field starts.
+func readSnapshotField(r *bufio.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if length > maxSnapshotField {
+		return nil, fmt.Errorf("field length %d too large", length)
+	}
+	b := make([]byte, length)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return b, nil
+}
+
+// unexpectedEOF reports a stream that ends inside a record.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"kvstore/internal/clock"
+)
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	src := NewInMemoryStore("n1")
+	src.Put("a", []byte("1"), nil, clock.Timestamp{WallTime: 1, NodeID: "n1"}, false)
+	src.Put("b", []byte("2"), clock.VectorClock{"n2": 3}, clock.Timestamp{WallTime: 2, NodeID: "n2"}, false)
+	src.Delete("c", nil, clock.Timestamp{WallTime: 3, NodeID: "n1"})
+	expired := time.Now().Add(-time.Minute)
+	src.data["d"] = &VersionedValue{Value: []byte("4"), Version: clock.VectorClock{"n1": 1}, ExpiresAt: &expired}
+
+	var buf bytes.Buffer
+	written, err := src.WriteSnapshot(&buf)
+	if err != nil || written != 3 {
+		t.Fatalf("WriteSnapshot() = %d, %v; want 3 records", written, err)
+	}
+
+	dst := NewInMemoryStore("n2")
+	loaded, err := dst.LoadSnapshot(&buf)
+	if err != nil || loaded != 3 {
+		t.Fatalf("LoadSnapshot() = %d, %v; want 3 records", loaded, err)
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		if got, want := dst.data[key], src.data[key]; !reflect.DeepEqual(got, want) {
+			t.Errorf("Loaded %s = %+v, want %+v", key, got, want)
+		}
+	}
+	if _, ok := dst.data["d"]; ok {
+		t.Error("Expected the expired value not to be written")
+	}
+	if st := dst.Stats(); st.Keys != 2 || st.Tombstones != 1 || st != src.Stats() {
+		t.Errorf("Stats() after load = %+v, want %+v", st, src.Stats())
+	}
+}
+
+func TestSnapshot_LoadKeepsNewerVersions(t *testing.T) {
+	src := NewInMemoryStore("n1")
+	src.PutRepair("k", []byte("old"), clock.VectorClock{"n1": 1}, clock.Timestamp{WallTime: 1}, false, nil)
+	var buf bytes.Buffer
+	if _, err := src.WriteSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := NewInMemoryStore("n1")
+	dst.PutRepair("k", []byte("new"), clock.VectorClock{"n1": 2}, clock.Timestamp{WallTime: 2}, false, nil)
+	if _, err := dst.LoadSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := dst.Get("k"); string(got.Value) != "new" {
+		t.Errorf("Expected the newer version to be kept, got %q", got.Value)
+	}
+}
+
+func TestSnapshot_LoadErrors(t *testing.T) {
+	src := NewInMemoryStore("n1")
+	src.Put("a", []byte("1"), nil, clock.Timestamp{}, false)
+	src.Put("b", []byte("2"), nil, clock.Timestamp{}, false)
+	var buf bytes.Buffer
+	if _, err := src.WriteSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	full := buf.Bytes()
+
+	tests := []struct {
+		name   string
+		data   []byte
+		loaded int
+	}{
+		{"empty", nil, 0},
+		{"not a snapshot", []byte("KVSTORE"), 0},
+		{"unknown version", append([]byte("KVSNAP"), 9), 0},
+		{"truncated record", full[:len(full)-1], 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loaded, err := NewInMemoryStore("n2").LoadSnapshot(bytes.NewReader(tt.data))
+			if err == nil || loaded != tt.loaded {
+				t.Errorf("LoadSnapshot() = %d, %v; want an error after %d records", loaded, err, tt.loaded)
+			}
+		})
+	}
+
+	// A snapshot of an empty store loads nothing
+	buf.Reset()
+	NewInMemoryStore("n1").WriteSnapshot(&buf)
+	if loaded, err := NewInMemoryStore("n2").LoadSnapshot(strings.NewReader(buf.String())); err != nil || loaded != 0 {
+		t.Errorf("LoadSnapshot(empty) = %d, %v", loaded, err)
+	}
+}
@@ -2,18 +2,26 @@ package storage
 
 import (
 	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"kvstore/internal/clock"
+	"kvstore/internal/conflict"
+	"kvstore/internal/logging"
 )
 
 // VersionedValue represents a value with its vector clock version.
 type VersionedValue struct {
 	Value     []byte
 	Version   clock.VectorClock
-	Deleted   bool       // True if this is a tombstone (deleted)
-	ExpiresAt *time.Time // nil if no expiration
+	Timestamp clock.Timestamp // HLC timestamp assigned by the coordinator
+	// EntryTimes records when each version entry last advanced on this replica
+	EntryTimes clock.EntryTimes
+	Deleted    bool       // True if this is a tombstone (deleted)
+	ExpiresAt  *time.Time // nil if no expiration
 }
 
 // IsExpired checks if the value has expired.
@@ -33,22 +41,48 @@ func (vv *VersionedValue) IsTombstone() bool {
 type Store interface {
 	// Get retrieves a value by key. Returns nil if not found or expired.
 	Get(key string) *VersionedValue
-	// Put stores a value with the given version. If version is nil, creates a new one.
-	// If deleted is true, stores a tombstone.
-	Put(key string, value []byte, version clock.VectorClock, deleted bool) clock.VectorClock
+	// Put stores a value with the given version and HLC timestamp. If version is nil,
+	// creates a new one. If deleted is true, stores a tombstone.
+	Put(key string, value []byte, version clock.VectorClock, ts clock.Timestamp, deleted bool) clock.VectorClock
+	// PutVersion stores a value with the coordinator's version, without
+	// incrementing it, expiring at expiresAt (nil = never). Returns the
+	// stored version.
+	PutVersion(key string, value []byte, version clock.VectorClock, ts clock.Timestamp, deleted bool, expiresAt *time.Time) clock.VectorClock
 	// PutRepair stores a value with the exact version (no increment) for read repair.
 	// Only overwrites if incoming version dominates or is equal to existing.
-	PutRepair(key string, value []byte, version clock.VectorClock, deleted bool) error
+	PutRepair(key string, value []byte, version clock.VectorClock, ts clock.Timestamp, deleted bool, expiresAt *time.Time) error
 	// Delete removes a key. Returns the version after deletion.
-	Delete(key string, version clock.VectorClock) clock.VectorClock
+	Delete(key string, version clock.VectorClock, ts clock.Timestamp) clock.VectorClock
+	// Keys returns up to limit live keys with the given prefix that sort after
+	// startAfter, in order, and whether more keys follow. Only keys in the
+	// namespace of prefix are returned.
+	Keys(prefix, startAfter string, limit int) ([]string, bool)
+	// Usage returns the keys and bytes a namespace stores.
+	Usage(namespace string) Usage
+}
+
+// Usage is the storage used by a namespace. Tombstones are not counted.
+type Usage struct {
+	Keys  int   // Keys holding a value
+	Bytes int64 // Size of those keys and their values
+}
+
+// size returns the bytes key and vv count towards a namespace's usage.
+func size(key string, vv *VersionedValue) int64 {
+	return int64(len(key) + len(vv.Value))
 }
 
 // InMemoryStore is an in-memory implementation of Store.
 // It's thread-safe and supports TTL expiration.
 type InMemoryStore struct {
-	mu     sync.RWMutex
-	data   map[string]*VersionedValue
-	nodeID string // Node ID for generating vector clocks
+	mu       sync.RWMutex
+	data     map[string]*VersionedValue
+	nodeID   string             // Node ID for generating vector clocks
+	policies *conflict.Policies // Conflict resolution policy per key space
+	pruner   *clock.Pruner      // Vector clock pruning applied on write (nil = disabled)
+	usage    map[string]Usage   // By namespace
+	deleted  int                // Tombstones held
+	log      *slog.Logger
 }
 
 // NewInMemoryStore creates a new in-memory store.
@@ -56,9 +90,28 @@ func NewInMemoryStore(nodeID string) *InMemoryStore {
 	return &InMemoryStore{
 		data:   make(map[string]*VersionedValue),
 		nodeID: nodeID,
+		usage:  make(map[string]Usage),
+		log:    logging.For(logging.Storage).With(logging.NodeID(nodeID)),
 	}
 }
 
+// SetPolicies sets the conflict resolution policies applied on writes.
+// Under conflict.LWW, a write never replaces a concurrent version with a
+// newer HLC timestamp.
+func (s *InMemoryStore) SetPolicies(policies *conflict.Policies) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policies = policies
+}
+
+// SetPruner sets the vector clock pruner applied on Put and Delete.
+// Repair writes are never pruned so that replicas converge to identical versions.
+func (s *InMemoryStore) SetPruner(pruner *clock.Pruner) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruner = pruner
+}
+
 // Get retrieves a value by key.
 func (s *InMemoryStore) Get(key string) *VersionedValue {
 	s.mu.RLock()
@@ -77,10 +130,12 @@ func (s *InMemoryStore) Get(key string) *VersionedValue {
 
 	// Return a copy to avoid external modifications
 	return &VersionedValue{
-		Value:     append([]byte(nil), vv.Value...),
-		Version:   vv.Version.Copy(),
-		Deleted:   vv.Deleted,
-		ExpiresAt: copyTime(vv.ExpiresAt),
+		Value:      append([]byte(nil), vv.Value...),
+		Version:    vv.Version.Copy(),
+		Timestamp:  vv.Timestamp,
+		EntryTimes: vv.EntryTimes.Copy(),
+		Deleted:    vv.Deleted,
+		ExpiresAt:  copyTime(vv.ExpiresAt),
 	}
 }
 
@@ -88,7 +143,10 @@ func (s *InMemoryStore) Get(key string) *VersionedValue {
 // If version is nil, creates a new vector clock and increments it.
 // Otherwise, merges the provided version and increments.
 // If deleted is true, stores a tombstone.
-func (s *InMemoryStore) Put(key string, value []byte, version clock.VectorClock, deleted bool) clock.VectorClock {
+// Under conflict.LWW, if the existing version is concurrent with (or newer
+// than) the incoming one and has a newer timestamp, the write is dropped and
+// the existing version is returned.
+func (s *InMemoryStore) Put(key string, value []byte, version clock.VectorClock, ts clock.Timestamp, deleted bool) clock.VectorClock {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -100,31 +158,81 @@ func (s *InMemoryStore) Put(key string, value []byte, version clock.VectorClock,
 	}
 
 	// Merge with existing version if present
-	if existing, exists := s.data[key]; exists && !existing.IsExpired() {
+	existing, exists := s.data[key]
+	if exists && !existing.IsExpired() {
+		if s.lwwKeepsExisting(key, existing, newVersion, ts) {
+			return existing.Version.Copy()
+		}
 		newVersion.Merge(existing.Version)
+	} else {
+		existing = nil
 	}
 
 	// Increment for this node
 	newVersion.Increment(s.nodeID)
+	entryTimes := s.advanceAndPrune(key, existing, newVersion, ts)
 
 	// Store the value (or tombstone)
 	var valueCopy []byte
 	if !deleted {
 		valueCopy = append([]byte(nil), value...)
 	}
-	s.data[key] = &VersionedValue{
-		Value:     valueCopy,
-		Version:   newVersion,
-		Deleted:   deleted,
-		ExpiresAt: nil, // TTL will be handled in Phase 2+ if needed
+	s.set(key, &VersionedValue{
+		Value:      valueCopy,
+		Version:    newVersion,
+		Timestamp:  ts,
+		EntryTimes: entryTimes,
+		Deleted:    deleted,
+		ExpiresAt:  nil, // TTL will be handled in Phase 2+ if needed
+	})
+
+	return newVersion.Copy()
+}
+
+// PutVersion stores a value with a version assigned by the coordinator of
+// the write, without incrementing it, so that every replica stores the same
+// version. A version the existing one dominates is dropped. A concurrent
+// existing version is merged into the stored one. The stored version is
+// returned. LWW keys behave as in Put. The value expires at expiresAt (nil =
+// never).
+func (s *InMemoryStore) PutVersion(key string, value []byte, version clock.VectorClock, ts clock.Timestamp, deleted bool, expiresAt *time.Time) clock.VectorClock {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	newVersion := clock.New()
+	if version != nil {
+		newVersion = version.Copy()
+	}
+	existing, exists := s.data[key]
+	if exists && !existing.IsExpired() {
+		if s.lwwKeepsExisting(key, existing, newVersion, ts) || newVersion.Compare(existing.Version) == clock.Before {
+			return existing.Version.Copy()
+		}
+		newVersion.Merge(existing.Version)
+	} else {
+		existing = nil
 	}
+	entryTimes := s.advanceAndPrune(key, existing, newVersion, ts)
 
+	var valueCopy []byte
+	if !deleted {
+		valueCopy = append([]byte(nil), value...)
+	}
+	s.set(key, &VersionedValue{
+		Value:      valueCopy,
+		Version:    newVersion,
+		Timestamp:  ts,
+		EntryTimes: entryTimes,
+		Deleted:    deleted,
+		ExpiresAt:  copyTime(expiresAt),
+	})
 	return newVersion.Copy()
 }
 
 // PutRepair stores a value with the exact version (no increment) for read repair.
 // Only overwrites if incoming version dominates or is equal to existing.
-func (s *InMemoryStore) PutRepair(key string, value []byte, version clock.VectorClock, deleted bool) error {
+// The value expires at expiresAt (nil = never).
+func (s *InMemoryStore) PutRepair(key string, value []byte, version clock.VectorClock, ts clock.Timestamp, deleted bool, expiresAt *time.Time) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -133,32 +241,42 @@ func (s *InMemoryStore) PutRepair(key string, value []byte, version clock.Vector
 	}
 
 	// Check if we should overwrite
-	if existing, exists := s.data[key]; exists && !existing.IsExpired() {
+	existing, exists := s.data[key]
+	if exists && !existing.IsExpired() {
 		comp := version.Compare(existing.Version)
+		// Under LWW, a concurrent version with a newer timestamp also wins
+		lwwWins := comp == clock.Concurrent && s.policies.For(key) == conflict.LWW && ts.Newer(existing.Timestamp)
 		// Only overwrite if incoming dominates or is equal
-		if comp != clock.After && comp != clock.Equal {
+		if comp != clock.After && comp != clock.Equal && !lwwWins {
 			// Incoming version is before or concurrent - don't overwrite
 			return nil // Silently skip (best effort)
 		}
 	}
 
-	// Overwrite with exact version (no increment)
+	// Overwrite with exact version (no increment, no pruning)
+	var prevVersion clock.VectorClock
+	var prevTimes clock.EntryTimes
+	if exists {
+		prevVersion, prevTimes = existing.Version, existing.EntryTimes
+	}
 	var valueCopy []byte
 	if !deleted {
 		valueCopy = append([]byte(nil), value...)
 	}
-	s.data[key] = &VersionedValue{
-		Value:     valueCopy,
-		Version:   version.Copy(), // Store exact version
-		Deleted:   deleted,
-		ExpiresAt: nil,
-	}
+	s.set(key, &VersionedValue{
+		Value:      valueCopy,
+		Version:    version.Copy(), // Store exact version
+		Timestamp:  ts,
+		EntryTimes: prevTimes.Advance(prevVersion, version, entryTimeMs(ts)),
+		Deleted:    deleted,
+		ExpiresAt:  copyTime(expiresAt),
+	})
 
 	return nil
 }
 
 // Delete removes a key.
-func (s *InMemoryStore) Delete(key string, version clock.VectorClock) clock.VectorClock {
+func (s *InMemoryStore) Delete(key string, version clock.VectorClock, ts clock.Timestamp) clock.VectorClock {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -170,31 +288,220 @@ func (s *InMemoryStore) Delete(key string, version clock.VectorClock) clock.Vect
 	}
 
 	// Merge with existing version if present
-	if existing, exists := s.data[key]; exists && !existing.IsExpired() {
+	existing, exists := s.data[key]
+	if exists && !existing.IsExpired() {
+		if s.lwwKeepsExisting(key, existing, newVersion, ts) {
+			return existing.Version.Copy()
+		}
 		newVersion.Merge(existing.Version)
+	} else {
+		existing = nil
 	}
 
 	// Increment for this node
 	newVersion.Increment(s.nodeID)
+	entryTimes := s.advanceAndPrune(key, existing, newVersion, ts)
 
 	// Store tombstone instead of deleting (for replication)
-	s.data[key] = &VersionedValue{
-		Value:     nil,
-		Version:   newVersion,
-		Deleted:   true,
-		ExpiresAt: nil,
-	}
+	s.set(key, &VersionedValue{
+		Value:      nil,
+		Version:    newVersion,
+		Timestamp:  ts,
+		EntryTimes: entryTimes,
+		Deleted:    true,
+		ExpiresAt:  nil,
+	})
 
 	return newVersion.Copy()
 }
 
+// advanceAndPrune computes entry times for newVersion relative to the
+// existing value (nil if none) and prunes newVersion in place if a pruner is
+// configured. Must be called with lock held.
+func (s *InMemoryStore) advanceAndPrune(key string, existing *VersionedValue, newVersion clock.VectorClock, ts clock.Timestamp) clock.EntryTimes {
+	var prevVersion clock.VectorClock
+	var prevTimes clock.EntryTimes
+	if existing != nil {
+		prevVersion, prevTimes = existing.Version, existing.EntryTimes
+	}
+	entryTimes := prevTimes.Advance(prevVersion, newVersion, entryTimeMs(ts))
+
+	if s.pruner != nil {
+		if removed := s.pruner.Prune(newVersion, entryTimes, time.Now()); len(removed) > 0 {
+			s.log.Info("Pruned vector clock, may cause false conflicts",
+				logging.Key(key), slog.Any("removed", removed), slog.Int("entries", len(newVersion)))
+		}
+	}
+	return entryTimes
+}
+
+// entryTimeMs returns the time used to stamp advanced clock entries: the
+// write's HLC wall time, or the local clock if the write has no timestamp.
+func entryTimeMs(ts clock.Timestamp) int64 {
+	if ts.IsZero() {
+		return time.Now().UnixMilli()
+	}
+	return ts.WallTime
+}
+
+// lwwKeepsExisting reports whether an incoming write must be dropped because
+// the key uses last-write-wins and the existing version, which the incoming
+// version does not causally dominate, has a newer timestamp.
+// Must be called with lock held.
+func (s *InMemoryStore) lwwKeepsExisting(key string, existing *VersionedValue, version clock.VectorClock, ts clock.Timestamp) bool {
+	if s.policies.For(key) != conflict.LWW {
+		return false
+	}
+	if comp := version.Compare(existing.Version); comp == clock.After || comp == clock.Equal {
+		return false
+	}
+	return existing.Timestamp.Newer(ts)
+}
+
+// Keys returns up to limit live (not deleted or expired) keys with the given
+// prefix that sort after startAfter, in order, and whether more keys follow.
+// Only keys in the namespace of prefix are returned.
+func (s *InMemoryStore) Keys(prefix, startAfter string, limit int) ([]string, bool) {
+	return s.keys(prefix, startAfter, limit, false)
+}
+
+// KeysWithTombstones is Keys including deleted keys whose tombstones have not
+// been compacted.
+func (s *InMemoryStore) KeysWithTombstones(prefix, startAfter string, limit int) ([]string, bool) {
+	return s.keys(prefix, startAfter, limit, true)
+}
+
+func (s *InMemoryStore) keys(prefix, startAfter string, limit int, tombstones bool) ([]string, bool) {
+	namespace, _ := SplitKey(prefix)
+	s.mu.RLock()
+	keys := make([]string, 0)
+	for key, vv := range s.data {
+		if strings.HasPrefix(key, prefix) && key > startAfter && (tombstones || !vv.Deleted) && !vv.IsExpired() {
+			if ns, _ := SplitKey(key); ns != namespace {
+				continue
+			}
+			keys = append(keys, key)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Strings(keys)
+	if limit > 0 && len(keys) > limit {
+		return keys[:limit], true
+	}
+	return keys, false
+}
+
+// Usage returns the keys and bytes a namespace stores.
+func (s *InMemoryStore) Usage(namespace string) Usage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.usage[namespace]
+}
+
+// Stats summarizes everything a store holds.
+type Stats struct {
+	Keys       int   // Keys holding a value
+	Tombstones int   // Deleted keys not yet removed
+	Bytes      int64 // Size of the keys holding a value and their values
+}
+
+// Stats returns the totals across all namespaces.
+func (s *InMemoryStore) Stats() Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	st := Stats{Tombstones: s.deleted}
+	for _, u := range s.usage {
+		st.Keys += u.Keys
+		st.Bytes += u.Bytes
+	}
+	return st
+}
+
+// CompactStats counts the entries removed by a compaction.
+type CompactStats struct {
+	Expired    int // Values past their TTL
+	Tombstones int // Tombstones written before the cutoff
+}
+
+// Compact removes expired values and the tombstones written before
+// tombstoneCutoff. A value is expired past its ExpiresAt, or once it is older
+// than the TTL ttl returns for its namespace (nil or 0 = no TTL). Values and
+// tombstones without a timestamp are kept. A tombstone must outlive every
+// replica that missed the delete, or read repair resurrects the deleted
+// value.
+func (s *InMemoryStore) Compact(tombstoneCutoff time.Time, ttl func(namespace string) time.Duration) CompactStats {
+	cutoff := tombstoneCutoff.UnixMilli()
+	now := time.Now()
+	ttls := make(map[string]time.Duration)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var st CompactStats
+	for key, vv := range s.data {
+		switch {
+		case vv.Deleted:
+			if !vv.Timestamp.IsZero() && vv.Timestamp.WallTime < cutoff {
+				s.set(key, nil)
+				st.Tombstones++
+			}
+		case vv.IsExpired():
+			s.set(key, nil)
+			st.Expired++
+		case ttl != nil && !vv.Timestamp.IsZero():
+			namespace, _ := SplitKey(key)
+			d, ok := ttls[namespace]
+			if !ok {
+				d = ttl(namespace)
+				ttls[namespace] = d
+			}
+			if d > 0 && now.Sub(vv.Timestamp.Time()) >= d {
+				s.set(key, nil)
+				st.Expired++
+			}
+		}
+	}
+	return st
+}
+
+// set stores vv under key, or removes key if vv is nil, and maintains the
+// per-namespace usage and tombstone count. Must be called with lock held.
+func (s *InMemoryStore) set(key string, vv *VersionedValue) {
+	namespace, _ := SplitKey(key)
+	u := s.usage[namespace]
+	if old, ok := s.data[key]; ok {
+		if old.Deleted {
+			s.deleted--
+		} else {
+			u.Keys--
+			u.Bytes -= size(key, old)
+		}
+	}
+	if vv == nil {
+		delete(s.data, key)
+	} else {
+		s.data[key] = vv
+		if vv.Deleted {
+			s.deleted++
+		} else {
+			u.Keys++
+			u.Bytes += size(key, vv)
+		}
+	}
+	if u == (Usage{}) {
+		delete(s.usage, namespace)
+	} else {
+		s.usage[namespace] = u
+	}
+}
+
 // deleteExpired removes an expired key (called asynchronously).
 func (s *InMemoryStore) deleteExpired(key string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if vv, exists := s.data[key]; exists && vv.IsExpired() {
-		delete(s.data, key)
+		s.set(key, nil)
 	}
 }
 
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"testing"
+
+	"kvstore/internal/clock"
+	"kvstore/internal/conflict"
+)
+
+func newLWWStore(t *testing.T) *InMemoryStore {
+	t.Helper()
+	store := NewInMemoryStore("node1")
+	policies, err := conflict.ParsePolicies("cache/=lww")
+	if err != nil {
+		t.Fatalf("ParsePolicies failed: %v", err)
+	}
+	store.SetPolicies(policies)
+	return store
+}
+
+func TestInMemoryStore_LWW_PutKeepsNewerConcurrentWrite(t *testing.T) {
+	store := newLWWStore(t)
+
+	vcA := clock.New()
+	vcA.Set("n1", 1)
+	store.Put("cache/k", []byte("newer"), vcA, clock.Timestamp{WallTime: 200, NodeID: "n1"}, false)
+
+	// Concurrent write with an older timestamp arrives late
+	vcB := clock.New()
+	vcB.Set("n2", 1)
+	store.Put("cache/k", []byte("older"), vcB, clock.Timestamp{WallTime: 100, NodeID: "n2"}, false)
+
+	vv := store.Get("cache/k")
+	if vv == nil || string(vv.Value) != "newer" {
+		t.Errorf("Expected LWW to keep 'newer', got %v", vv)
+	}
+}
+
+func TestInMemoryStore_LWW_PutAcceptsCausalSuccessor(t *testing.T) {
+	store := newLWWStore(t)
+
+	vcA := clock.New()
+	vcA.Set("n1", 1)
+	store.Put("cache/k", []byte("v1"), vcA, clock.Timestamp{WallTime: 200, NodeID: "n1"}, false)
+
+	// A write that causally follows is accepted even with an older timestamp
+	vv := store.Get("cache/k")
+	store.Put("cache/k", []byte("v2"), vv.Version, clock.Timestamp{WallTime: 150, NodeID: "n2"}, false)
+
+	vv = store.Get("cache/k")
+	if vv == nil || string(vv.Value) != "v2" {
+		t.Errorf("Expected causal successor 'v2', got %v", vv)
+	}
+}
+
+func TestInMemoryStore_LWW_OnlyAppliesToConfiguredPrefix(t *testing.T) {
+	store := newLWWStore(t)
+
+	vcA := clock.New()
+	vcA.Set("n1", 1)
+	store.Put("user/k", []byte("a"), vcA, clock.Timestamp{WallTime: 200}, false)
+
+	vcB := clock.New()
+	vcB.Set("n2", 1)
+	store.Put("user/k", []byte("b"), vcB, clock.Timestamp{WallTime: 100}, false)
+
+	vv := store.Get("user/k")
+	if vv == nil || string(vv.Value) != "b" {
+		t.Errorf("Expected siblings key space to accept write, got %v", vv)
+	}
+}
+
+func TestInMemoryStore_LWW_PutRepairConvergesToWinner(t *testing.T) {
+	store := newLWWStore(t)
+
+	vcA := clock.New()
+	vcA.Set("n1", 1)
+	if err := store.PutRepair("cache/k", []byte("loser"), vcA, clock.Timestamp{WallTime: 100, NodeID: "n1"}, false, nil); err != nil {
+		t.Fatalf("PutRepair failed: %v", err)
+	}
+
+	// Concurrent repair with newer timestamp overwrites under LWW
+	vcB := clock.New()
+	vcB.Set("n2", 1)
+	if err := store.PutRepair("cache/k", []byte("winner"), vcB, clock.Timestamp{WallTime: 200, NodeID: "n2"}, false, nil); err != nil {
+		t.Fatalf("PutRepair failed: %v", err)
+	}
+
+	vv := store.Get("cache/k")
+	if vv == nil || string(vv.Value) != "winner" {
+		t.Fatalf("Expected 'winner', got %v", vv)
+	}
+	if !vv.Version.Equal(vcB) {
+		t.Errorf("Expected exact winner version %v, got %v", vcB, vv.Version)
+	}
+
+	// Concurrent repair with older timestamp is ignored
+	if err := store.PutRepair("cache/k", []byte("loser"), vcA, clock.Timestamp{WallTime: 100, NodeID: "n1"}, false, nil); err != nil {
+		t.Fatalf("PutRepair failed: %v", err)
+	}
+	if vv := store.Get("cache/k"); string(vv.Value) != "winner" {
+		t.Errorf("Expected 'winner' to be kept, got %s", string(vv.Value))
+	}
+}
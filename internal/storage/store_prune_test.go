@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"kvstore/internal/clock"
+)
+
+func TestInMemoryStore_PutPrunesVersion(t *testing.T) {
+	store := NewInMemoryStore("node1")
+	pruner := clock.NewPruner(clock.PruneConfig{MinEntries: 1, MaxEntries: 3})
+	store.SetPruner(pruner)
+
+	// Each write comes from a different coordinator, so the clock grows
+	old := time.Now().Add(-time.Hour).UnixMilli()
+	var version clock.VectorClock
+	for i := 0; i < 5; i++ {
+		vc := clock.New()
+		vc.Merge(version)
+		vc.Increment(fmt.Sprintf("coord%d", i))
+		version = store.Put("key1", []byte("v"), vc, clock.Timestamp{WallTime: old + int64(i)}, false)
+	}
+
+	vv := store.Get("key1")
+	if len(vv.Version) != 3 {
+		t.Errorf("Expected clock pruned to 3 entries, got %d: %v", len(vv.Version), vv.Version)
+	}
+	// The local node's entry was advanced by the latest write and must survive
+	if vv.Version.Get("node1") == 0 {
+		t.Error("Expected local entry to be kept")
+	}
+	if len(vv.EntryTimes) != len(vv.Version) {
+		t.Errorf("Expected entry times to track clock entries, got %v", vv.EntryTimes)
+	}
+
+	stats := pruner.Stats()
+	if stats.Observed != 5 || stats.PrunedClocks == 0 {
+		t.Errorf("Unexpected pruning stats: %+v", stats)
+	}
+}
+
+func TestInMemoryStore_PutRepairIsNotPruned(t *testing.T) {
+	store := NewInMemoryStore("node1")
+	store.SetPruner(clock.NewPruner(clock.PruneConfig{MaxEntries: 1}))
+
+	vc := clock.VectorClock{"n1": 1, "n2": 1, "n3": 1}
+	if err := store.PutRepair("key1", []byte("v"), vc, clock.Timestamp{}, false, nil); err != nil {
+		t.Fatalf("PutRepair failed: %v", err)
+	}
+
+	if vv := store.Get("key1"); !vv.Version.Equal(vc) {
+		t.Errorf("Expected exact repair version %v, got %v", vc, vv.Version)
+	}
+}
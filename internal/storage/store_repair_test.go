@@ -15,14 +15,14 @@ func TestInMemoryStore_PutRepair(t *testing.T) {
 	vc1.Set("node2", 1)
 
 	// Store initial value
-	store.Put("key1", []byte("value1"), vc1, false)
+	store.Put("key1", []byte("value1"), vc1, clock.Timestamp{}, false)
 
 	// Repair with dominating version (should overwrite)
 	vc2 := clock.New()
 	vc2.Set("node1", 2)
 	vc2.Set("node2", 1)
 
-	err := store.PutRepair("key1", []byte("value2"), vc2, false)
+	err := store.PutRepair("key1", []byte("value2"), vc2, clock.Timestamp{}, false, nil)
 	if err != nil {
 		t.Errorf("PutRepair should succeed: %v", err)
 	}
@@ -49,14 +49,14 @@ func TestInMemoryStore_PutRepair_RejectsOlderVersion(t *testing.T) {
 	vc1.Set("node1", 2)
 	vc1.Set("node2", 1)
 
-	store.Put("key1", []byte("value1"), vc1, false)
+	store.Put("key1", []byte("value1"), vc1, clock.Timestamp{}, false)
 
 	// Try to repair with older version (should be rejected)
 	vc2 := clock.New()
 	vc2.Set("node1", 1)
 	vc2.Set("node2", 1)
 
-	err := store.PutRepair("key1", []byte("value2"), vc2, false)
+	err := store.PutRepair("key1", []byte("value2"), vc2, clock.Timestamp{}, false, nil)
 	if err != nil {
 		t.Errorf("PutRepair should silently skip (not error): %v", err)
 	}
@@ -74,14 +74,14 @@ func TestInMemoryStore_PutRepair_Tombstone(t *testing.T) {
 	// Store initial value
 	vc1 := clock.New()
 	vc1.Set("node1", 1)
-	store.Put("key1", []byte("value1"), vc1, false)
+	store.Put("key1", []byte("value1"), vc1, clock.Timestamp{}, false)
 
 	// Repair with tombstone
 	vc2 := clock.New()
 	vc2.Set("node1", 2)
 	vc2.Set("node2", 1)
 
-	err := store.PutRepair("key1", nil, vc2, true)
+	err := store.PutRepair("key1", nil, vc2, clock.Timestamp{}, true, nil)
 	if err != nil {
 		t.Errorf("PutRepair tombstone should succeed: %v", err)
 	}
@@ -12,7 +12,7 @@ func TestInMemoryStore_GetPut(t *testing.T) {
 	store := NewInMemoryStore("node1")
 
 	// Put a value
-	version := store.Put("key1", []byte("value1"), nil, false)
+	version := store.Put("key1", []byte("value1"), nil, clock.Timestamp{}, false)
 	if version == nil {
 		t.Fatal("Expected non-nil version")
 	}
@@ -44,7 +44,7 @@ func TestInMemoryStore_PutWithVersion(t *testing.T) {
 	// Put with initial version
 	initialVersion := clock.New()
 	initialVersion.Set("node2", 5)
-	version1 := store.Put("key1", []byte("value1"), initialVersion, false)
+	version1 := store.Put("key1", []byte("value1"), initialVersion, clock.Timestamp{}, false)
 
 	// Version should merge and increment
 	if version1.Get("node2") != 5 {
@@ -57,7 +57,7 @@ func TestInMemoryStore_PutWithVersion(t *testing.T) {
 	// Put again with updated version
 	updatedVersion := clock.New()
 	updatedVersion.Set("node2", 7)
-	version2 := store.Put("key1", []byte("value2"), updatedVersion, false)
+	version2 := store.Put("key1", []byte("value2"), updatedVersion, clock.Timestamp{}, false)
 
 	// Should merge both versions
 	if version2.Get("node2") != 7 {
@@ -68,14 +68,59 @@ func TestInMemoryStore_PutWithVersion(t *testing.T) {
 	}
 }
 
+func TestInMemoryStore_PutVersion(t *testing.T) {
+	store := NewInMemoryStore("node1")
+
+	// The coordinator's version is stored as is, without this node's counter
+	version := clock.New()
+	version.Set("node2", 3)
+	stored := store.PutVersion("key1", []byte("value1"), version, clock.Timestamp{}, false, nil)
+	if !reflect.DeepEqual(stored, version) {
+		t.Errorf("Expected stored version %v, got %v", version, stored)
+	}
+
+	// An older version does not replace the stored value
+	older := clock.New()
+	older.Set("node2", 2)
+	store.PutVersion("key1", []byte("old"), older, clock.Timestamp{}, false, nil)
+	if vv := store.Get("key1"); string(vv.Value) != "value1" || vv.Version.Get("node2") != 3 {
+		t.Errorf("Expected value1 at node2:3, got %s at %v", vv.Value, vv.Version)
+	}
+
+	// A tombstone with a newer version replaces it
+	newer := clock.New()
+	newer.Set("node2", 4)
+	store.PutVersion("key1", nil, newer, clock.Timestamp{}, true, nil)
+	if vv := store.Get("key1"); !vv.Deleted || vv.Version.Get("node2") != 4 || vv.Version.Get("node1") != 0 {
+		t.Errorf("Expected tombstone at node2:4, got deleted=%v at %v", vv.Deleted, vv.Version)
+	}
+}
+
+func TestInMemoryStore_PutVersionExpires(t *testing.T) {
+	store := NewInMemoryStore("node1")
+	version := clock.VectorClock{"node2": 1}
+
+	future := time.Now().Add(time.Hour)
+	store.PutVersion("key1", []byte("v"), version, clock.Timestamp{}, false, &future)
+	if vv := store.Get("key1"); vv == nil || vv.ExpiresAt == nil || !vv.ExpiresAt.Equal(future) {
+		t.Errorf("Expected value expiring at %v, got %+v", future, vv)
+	}
+
+	past := time.Now().Add(-time.Second)
+	store.PutVersion("key2", []byte("v"), version, clock.Timestamp{}, false, &past)
+	if vv := store.Get("key2"); vv != nil {
+		t.Errorf("Expected expired value to read as not found, got %+v", vv)
+	}
+}
+
 func TestInMemoryStore_Delete(t *testing.T) {
 	store := NewInMemoryStore("node1")
 
 	// Put a value
-	store.Put("key1", []byte("value1"), nil, false)
+	store.Put("key1", []byte("value1"), nil, clock.Timestamp{}, false)
 
 	// Delete it (should increment version from 1 to 2)
-	version := store.Delete("key1", nil)
+	version := store.Delete("key1", nil, clock.Timestamp{})
 	if version.Get("node1") != 2 {
 		t.Errorf("Expected version counter 2 after delete (was 1 after put), got %d", version.Get("node1"))
 	}
@@ -97,7 +142,7 @@ func TestInMemoryStore_ConcurrentAccess(t *testing.T) {
 	done := make(chan bool, 10)
 	for i := 0; i < 10; i++ {
 		go func(i int) {
-			store.Put("key1", []byte("value"), nil, false)
+			store.Put("key1", []byte("value"), nil, clock.Timestamp{}, false)
 			done <- true
 		}(i)
 	}
@@ -140,7 +185,7 @@ func TestVersionedValue_IsExpired(t *testing.T) {
 
 func TestInMemoryStore_GetReturnsCopy(t *testing.T) {
 	store := NewInMemoryStore("node1")
-	store.Put("key1", []byte("value1"), nil, false)
+	store.Put("key1", []byte("value1"), nil, clock.Timestamp{}, false)
 
 	vv1 := store.Get("key1")
 	vv2 := store.Get("key1")
@@ -153,3 +198,139 @@ func TestInMemoryStore_GetReturnsCopy(t *testing.T) {
 		t.Error("Get should return independent copies")
 	}
 }
+
+func TestInMemoryStore_PutStoresTimestamp(t *testing.T) {
+	store := NewInMemoryStore("node1")
+	ts := clock.Timestamp{WallTime: 1700000000000, Logical: 2}
+	store.Put("key1", []byte("value1"), nil, ts, false)
+
+	vv := store.Get("key1")
+	if vv == nil {
+		t.Fatal("Expected value to exist")
+	}
+	if vv.Timestamp != ts {
+		t.Errorf("Expected timestamp %s, got %s", ts, vv.Timestamp)
+	}
+
+	// Tombstones carry the timestamp of the delete
+	delTS := clock.Timestamp{WallTime: 1700000000500}
+	store.Delete("key1", nil, delTS)
+	vv = store.Get("key1")
+	if vv == nil || !vv.Deleted {
+		t.Fatal("Expected tombstone")
+	}
+	if vv.Timestamp != delTS {
+		t.Errorf("Expected tombstone timestamp %s, got %s", delTS, vv.Timestamp)
+	}
+}
+
+func TestInMemoryStore_Keys(t *testing.T) {
+	store := NewInMemoryStore("node1")
+	for _, key := range []string{"user:3", "user:1", "cart:1", "user:2", "user:4"} {
+		store.Put(key, []byte("v"), nil, clock.Timestamp{}, false)
+	}
+	store.Delete("user:4", nil, clock.Timestamp{})
+
+	keys, more := store.Keys("user:", "", 2)
+	if !reflect.DeepEqual(keys, []string{"user:1", "user:2"}) || !more {
+		t.Errorf("Expected first page [user:1 user:2] with more, got %v more=%v", keys, more)
+	}
+	keys, more = store.Keys("user:", "user:2", 2)
+	if !reflect.DeepEqual(keys, []string{"user:3"}) || more {
+		t.Errorf("Expected last page [user:3] without tombstone, got %v more=%v", keys, more)
+	}
+	keys, more = store.KeysWithTombstones("user:", "user:2", 0)
+	if !reflect.DeepEqual(keys, []string{"user:3", "user:4"}) || more {
+		t.Errorf("Expected [user:3 user:4] with tombstone, got %v more=%v", keys, more)
+	}
+}
+
+func TestInMemoryStore_Namespaces(t *testing.T) {
+	store := NewInMemoryStore("node1")
+	for _, key := range []string{"a", "b", NamespacedKey("tenant", "a"), NamespacedKey("tenant", "c")} {
+		store.Put(key, []byte("v"), nil, clock.Timestamp{}, false)
+	}
+	store.Delete(NamespacedKey("tenant", "c"), nil, clock.Timestamp{})
+
+	if keys, _ := store.Keys("", "", 0); !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Errorf("Expected default namespace keys [a b], got %v", keys)
+	}
+	if keys, _ := store.Keys(NamespacedKey("tenant", ""), "", 0); !reflect.DeepEqual(keys, []string{NamespacedKey("tenant", "a")}) {
+		t.Errorf("Expected tenant keys [a], got %q", keys)
+	}
+	if got := store.Usage(""); got != (Usage{Keys: 2, Bytes: 4}) {
+		t.Errorf("Usage(default) = %+v, want 2 keys, 4 bytes", got)
+	}
+	if got := store.Usage("tenant"); got != (Usage{Keys: 1, Bytes: 9}) {
+		t.Errorf("Usage(tenant) = %+v, want 1 key, 9 bytes (tombstones are not counted)", got)
+	}
+	store.Put("a", []byte("longer"), nil, clock.Timestamp{}, false)
+	if got := store.Usage(""); got != (Usage{Keys: 2, Bytes: 9}) {
+		t.Errorf("Usage(default) after overwrite = %+v, want 2 keys, 9 bytes", got)
+	}
+
+	if ns, key := SplitKey(NamespacedKey("tenant", "x/y")); ns != "tenant" || key != "x/y" {
+		t.Errorf("SplitKey() = %q, %q", ns, key)
+	}
+	if ValidKey("a\x00b") || ValidKey("") || !ValidKey("a") {
+		t.Error("Expected keys with the namespace separator to be invalid")
+	}
+}
+
+func TestInMemoryStore_Stats(t *testing.T) {
+	store := NewInMemoryStore("node1")
+	store.Put("a", []byte("v"), nil, clock.Timestamp{}, false)
+	store.Put(NamespacedKey("tenant", "b"), []byte("v"), nil, clock.Timestamp{}, false)
+	store.Put("c", []byte("v"), nil, clock.Timestamp{}, false)
+	store.Delete("c", nil, clock.Timestamp{})
+	store.Delete("never-written", nil, clock.Timestamp{})
+
+	want := Stats{Keys: 2, Tombstones: 2, Bytes: 2 + int64(len(NamespacedKey("tenant", "b"))+1)}
+	if got := store.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+
+	store.Put("c", []byte("v"), nil, clock.Timestamp{}, false)
+	if got := store.Stats(); got.Keys != 3 || got.Tombstones != 1 {
+		t.Errorf("Stats() after rewrite = %+v, want 3 keys and 1 tombstone", got)
+	}
+}
+
+func TestInMemoryStore_Compact(t *testing.T) {
+	store := NewInMemoryStore("node1")
+	now := time.Now()
+	old := clock.Timestamp{WallTime: now.Add(-2 * time.Hour).UnixMilli(), NodeID: "node1"}
+	recent := clock.Timestamp{WallTime: now.UnixMilli(), NodeID: "node1"}
+
+	store.Put("live", []byte("v"), nil, recent, false)
+	store.Delete("old", nil, old)
+	store.Delete("recent", nil, recent)
+	store.Delete("untimed", nil, clock.Timestamp{})
+	past := now.Add(-time.Minute)
+	store.mu.Lock()
+	store.set("expired", &VersionedValue{Value: []byte("v"), Version: clock.New(), ExpiresAt: &past})
+	store.mu.Unlock()
+	store.Put(NamespacedKey("cache", "old"), []byte("v"), nil, old, false)
+	store.Put(NamespacedKey("cache", "new"), []byte("v"), nil, recent, false)
+	store.Put(NamespacedKey("other", "old"), []byte("v"), nil, old, false)
+	ttl := func(namespace string) time.Duration {
+		if namespace == "cache" {
+			return time.Hour
+		}
+		return 0
+	}
+
+	got := store.Compact(now.Add(-time.Hour), ttl)
+	if want := (CompactStats{Expired: 2, Tombstones: 1}); got != want {
+		t.Errorf("Compact() = %+v, want %+v", got, want)
+	}
+	if st := store.Stats(); st.Keys != 3 || st.Tombstones != 2 {
+		t.Errorf("Stats() after compaction = %+v, want 3 keys and 2 tombstones", st)
+	}
+	if u := store.Usage("cache"); u.Keys != 1 {
+		t.Errorf("Usage(cache) after compaction = %+v, want 1 key", u)
+	}
+	if store.Get("recent") == nil || store.Get("untimed") == nil {
+		t.Error("Expected recent and untimed tombstones to be kept")
+	}
+}
@@ -0,0 +1,238 @@
+package tlsutil
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"kvstore/internal/logging"
+)
+
+// Files holds the paths of a node's TLS material, all PEM-encoded.
+type Files struct {
+	CertFile     string // Node certificate, presented to clients and peers
+	KeyFile      string // Private key of CertFile
+	CAFile       string // CA bundle signing node certificates
+	ClientCAFile string // CA bundle signing client certificates (optional)
+}
+
+// Certs holds the TLS material currently loaded from Files.
+type Certs struct {
+	files Files
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	nodeCAs   *x509.CertPool
+	clientCAs *x509.CertPool // Node CAs plus the client CA
+	modTimes  map[string]time.Time
+}
+
+// Load loads the TLS material. The certificate, key and CA files are required.
+func Load(files Files) (*Certs, error) {
+	if files.CertFile == "" || files.KeyFile == "" || files.CAFile == "" {
+		return nil, errors.New("tls: cert, key and ca files are required")
+	}
+	c := &Certs{files: files}
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reload rereads all files. On error the previously loaded material is kept.
+// Connections established before the reload keep their certificates; new
+// handshakes use the reloaded ones.
+func (c *Certs) Reload() error {
+	modTimes := c.fileModTimes()
+	cert, err := tls.LoadX509KeyPair(c.files.CertFile, c.files.KeyFile)
+	if err != nil {
+		return fmt.Errorf("tls: load certificate: %w", err)
+	}
+	nodeCAs := x509.NewCertPool()
+	if err := appendPEM(nodeCAs, c.files.CAFile); err != nil {
+		return err
+	}
+	clientCAs := nodeCAs.Clone()
+	if c.files.ClientCAFile != "" {
+		if err := appendPEM(clientCAs, c.files.ClientCAFile); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cert = &cert
+	c.nodeCAs = nodeCAs
+	c.clientCAs = clientCAs
+	c.modTimes = modTimes
+	return nil
+}
+
+// appendPEM adds the certificates of a PEM file to pool.
+func appendPEM(pool *x509.CertPool, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("tls: read CA: %w", err)
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return fmt.Errorf("tls: no certificates found in %s", path)
+	}
+	return nil
+}
+
+// fileModTimes returns the modification time of every configured file.
+func (c *Certs) fileModTimes() map[string]time.Time {
+	times := make(map[string]time.Time)
+	for _, path := range []string{c.files.CertFile, c.files.KeyFile, c.files.CAFile, c.files.ClientCAFile} {
+		if path == "" {
+			continue
+		}
+		if fi, err := os.Stat(path); err == nil {
+			times[path] = fi.ModTime()
+		}
+	}
+	return times
+}
+
+// Watch reloads the files whenever one of them changes, checking every
+// interval, until ctx is done. A failed reload is logged and retried on the
+// next change; the previous material stays in use.
+func (c *Certs) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := c.fileModTimes()
+			c.mu.RLock()
+			changed := !maps.Equal(current, c.modTimes)
+			c.mu.RUnlock()
+			if !changed {
+				continue
+			}
+			if err := c.Reload(); err != nil {
+				logging.For(logging.TLS).Warn("Certificate reload failed, keeping previous certificates", logging.Err(err))
+				c.mu.Lock()
+				c.modTimes = current // Do not retry until the files change again
+				c.mu.Unlock()
+				continue
+			}
+			logging.For(logging.TLS).Info("Reloaded TLS certificates", slog.String("cert_file", c.files.CertFile))
+		}
+	}
+}
+
+// certificate returns the current node certificate.
+func (c *Certs) certificate() *tls.Certificate {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert
+}
+
+// ServerConfig returns the TLS configuration of the node's gRPC server.
+// Clients may present a certificate signed by the node or client CA; which
+// RPCs require a node certificate is enforced per method (see VerifyNode).
+func (c *Certs) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			c.mu.RLock()
+			defer c.mu.RUnlock()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*c.cert},
+				ClientAuth:   tls.VerifyClientCertIfGiven,
+				ClientCAs:    c.clientCAs,
+				NextProtos:   []string{"h2"},
+			}, nil
+		},
+	}
+}
+
+// PeerConfig returns the TLS configuration for dialing another node. It
+// presents the node certificate and only accepts a server certificate signed
+// by the node CA. If expectedID returns a non-empty node ID at handshake
+// time, the certificate must also identify that node (see HasIdentity).
+func (c *Certs) PeerConfig(expectedID func() string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// The chain is verified in VerifyConnection against the current CA,
+		// which a static RootCAs pool would not pick up after a reload
+		InsecureSkipVerify: true,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return c.certificate(), nil
+		},
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			leaf, err := c.VerifyNode(cs.PeerCertificates, x509.ExtKeyUsageServerAuth)
+			if err != nil {
+				return err
+			}
+			if id := expectedID(); id != "" && !HasIdentity(leaf, id) {
+				return fmt.Errorf("tls: peer certificate %q does not identify node %s", leaf.Subject.CommonName, id)
+			}
+			return nil
+		},
+	}
+}
+
+// VerifyNode verifies that chain (leaf first) is signed by the node CA and
+// valid for usage, and returns the leaf.
+func (c *Certs) VerifyNode(chain []*x509.Certificate, usage x509.ExtKeyUsage) (*x509.Certificate, error) {
+	if len(chain) == 0 {
+		return nil, errors.New("tls: no node certificate presented")
+	}
+	c.mu.RLock()
+	roots := c.nodeCAs
+	c.mu.RUnlock()
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{usage},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("tls: not a node certificate: %w", err)
+	}
+	return chain[0], nil
+}
+
+// HasIdentity reports whether cert identifies node id, as its common name or
+// one of its DNS names.
+func HasIdentity(cert *x509.Certificate, id string) bool {
+	return cert.Subject.CommonName == id || slices.Contains(cert.DNSNames, id)
+}
+
+// ClientConfig returns a TLS configuration for clients of the KVStore
+// service. caFile verifies the server (the system roots if empty); certFile
+// and keyFile, if set, are presented as the client certificate.
+func ClientConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		cfg.RootCAs = x509.NewCertPool()
+		if err := appendPEM(cfg.RootCAs, caFile); err != nil {
+			return nil, err
+		}
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,220 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA is a self-signed CA that issues test certificates.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns PEM certificate and key for a node or client named cn.
+func (ca *testCA) issue(t *testing.T, cn string, serial int64) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeNodeFiles writes a node certificate signed by ca and returns its files.
+func writeNodeFiles(t *testing.T, dir string, ca *testCA, cn string, serial int64) Files {
+	t.Helper()
+	certPEM, keyPEM := ca.issue(t, cn, serial)
+	files := Files{
+		CertFile: filepath.Join(dir, cn+".crt"),
+		KeyFile:  filepath.Join(dir, cn+".key"),
+		CAFile:   filepath.Join(dir, "ca.crt"),
+	}
+	for path, data := range map[string][]byte{files.CertFile: certPEM, files.KeyFile: keyPEM, files.CAFile: ca.pem} {
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return files
+}
+
+// handshake connects a peer client to a server and returns the client error.
+func handshake(t *testing.T, server *tls.Config, client *tls.Config) error {
+	t.Helper()
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	go func() {
+		s := tls.Server(c2, server)
+		_ = s.Handshake()
+		s.Close()
+	}()
+	cfg := client.Clone()
+	cfg.NextProtos = []string{"h2"}
+	return tls.Client(c1, cfg).Handshake()
+}
+
+func TestCerts_PeerHandshake(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "node-ca")
+	n1, err := Load(writeNodeFiles(t, dir, ca, "n1", 2))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	n2, err := Load(writeNodeFiles(t, dir, ca, "n2", 3))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if err := handshake(t, n1.ServerConfig(), n2.PeerConfig(func() string { return "n1" })); err != nil {
+		t.Errorf("Expected handshake with the expected node to succeed: %v", err)
+	}
+	if err := handshake(t, n1.ServerConfig(), n2.PeerConfig(func() string { return "" })); err != nil {
+		t.Errorf("Expected handshake with an unknown node ID to succeed: %v", err)
+	}
+	if err := handshake(t, n1.ServerConfig(), n2.PeerConfig(func() string { return "n3" })); err == nil {
+		t.Error("Expected handshake to fail when the certificate identifies another node")
+	}
+
+	// A server certificate from another CA is rejected
+	rogue, err := Load(writeNodeFiles(t, t.TempDir(), newTestCA(t, "rogue-ca"), "n1", 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(t, rogue.ServerConfig(), n2.PeerConfig(func() string { return "n1" })); err == nil {
+		t.Error("Expected handshake to fail for a certificate from another CA")
+	}
+}
+
+func TestCerts_VerifyNode(t *testing.T) {
+	dir := t.TempDir()
+	nodeCA := newTestCA(t, "node-ca")
+	clientCA := newTestCA(t, "client-ca")
+	files := writeNodeFiles(t, dir, nodeCA, "n1", 2)
+	files.ClientCAFile = filepath.Join(dir, "client-ca.crt")
+	if err := os.WriteFile(files.ClientCAFile, clientCA.pem, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	certs, err := Load(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parse := func(certPEM []byte) *x509.Certificate {
+		block, _ := pem.Decode(certPEM)
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert
+	}
+	nodeCert, _ := nodeCA.issue(t, "n2", 5)
+	clientCert, _ := clientCA.issue(t, "alice", 6)
+
+	leaf, err := certs.VerifyNode([]*x509.Certificate{parse(nodeCert)}, x509.ExtKeyUsageClientAuth)
+	if err != nil || !HasIdentity(leaf, "n2") {
+		t.Errorf("VerifyNode(node cert) = %v, %v", leaf, err)
+	}
+	if _, err := certs.VerifyNode([]*x509.Certificate{parse(clientCert)}, x509.ExtKeyUsageClientAuth); err == nil {
+		t.Error("Expected a client certificate not to pass as a node certificate")
+	}
+	if _, err := certs.VerifyNode(nil, x509.ExtKeyUsageClientAuth); err == nil {
+		t.Error("Expected error without a certificate")
+	}
+}
+
+func TestCerts_Reload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "node-ca")
+	files := writeNodeFiles(t, dir, ca, "n1", 2)
+	certs, err := Load(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := certs.certificate()
+
+	// A broken key is rejected and the previous certificate kept
+	if err := os.WriteFile(files.KeyFile, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := certs.Reload(); err == nil {
+		t.Error("Expected reload of a broken key to fail")
+	}
+	if certs.certificate() != before {
+		t.Error("Expected failed reload to keep the previous certificate")
+	}
+
+	// A rotated certificate is used by new handshakes
+	writeNodeFiles(t, dir, ca, "n1", 7)
+	if err := certs.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	leaf, err := x509.ParseCertificate(certs.certificate().Certificate[0])
+	if err != nil || leaf.SerialNumber.Int64() != 7 {
+		t.Errorf("Expected rotated certificate with serial 7, got %v, %v", leaf, err)
+	}
+}
+
+func TestLoad_MissingFiles(t *testing.T) {
+	if _, err := Load(Files{CertFile: "n1.crt", KeyFile: "n1.key"}); err == nil {
+		t.Error("Expected error without a CA file")
+	}
+	if _, err := Load(Files{CertFile: "missing.crt", KeyFile: "missing.key", CAFile: "missing.crt"}); err == nil {
+		t.Error("Expected error for missing files")
+	}
+}
@@ -0,0 +1,6 @@
+// Package tlsutil loads and reloads the TLS material of a node: its own
+// certificate, the CA that signs node certificates (used for mutual TLS
+// between nodes) and an optional CA for client certificates. Configurations
+// built from a Certs pick up reloaded files on the next handshake, so
+// certificates can be rotated without restarting the node.
+package tlsutil
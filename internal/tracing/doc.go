@@ -0,0 +1,13 @@
+// Package tracing sets up OpenTelemetry tracing for the node: a tracer
+// provider with the node's resource and sampler, the OTLP/HTTP and stdout
+// exporters, and the otelgrpc stats handlers that trace gRPC calls.
+//
+// Trace context crosses process boundaries in the W3C traceparent header,
+// carried in gRPC metadata by the stats handlers, so the spans a request
+// creates on the coordinator and on each replica form one trace. Work that
+// outlives the request, such as read repair, starts a new trace linked to
+// the request instead.
+//
+// Whether a trace is recorded is decided once, when its root span starts, and
+// is propagated with the trace context.
+package tracing
@@ -0,0 +1,33 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/stats"
+)
+
+// Propagator carries trace context in the W3C traceparent header.
+var Propagator propagation.TextMapPropagator = propagation.TraceContext{}
+
+// ServerHandler returns a gRPC stats handler that starts a server span for
+// each call of a method for which traced returns true, continuing the trace
+// of the caller if it sent one.
+func ServerHandler(tp trace.TracerProvider, traced func(fullMethod string) bool) stats.Handler {
+	return otelgrpc.NewServerHandler(handlerOptions(tp, traced)...)
+}
+
+// ClientHandler returns a gRPC stats handler that starts a client span for
+// each call of a method for which traced returns true, and propagates the
+// trace context to the server.
+func ClientHandler(tp trace.TracerProvider, traced func(fullMethod string) bool) stats.Handler {
+	return otelgrpc.NewClientHandler(handlerOptions(tp, traced)...)
+}
+
+func handlerOptions(tp trace.TracerProvider, traced func(string) bool) []otelgrpc.Option {
+	return []otelgrpc.Option{
+		otelgrpc.WithTracerProvider(tp),
+		otelgrpc.WithPropagators(Propagator),
+		otelgrpc.WithFilter(func(info *stats.RPCTagInfo) bool { return traced(info.FullMethodName) }),
+	}
+}
@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestHandlers(t *testing.T) {
+	tp, rec := newTestProvider(1)
+	traced := func(method string) bool { return method != "/grpc.health.v1.Health/List" }
+
+	lis := bufconn.Listen(1 << 16)
+	server := grpc.NewServer(grpc.StatsHandler(ServerHandler(tp, traced)))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(ClientHandler(tp, traced)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	ctx, parent := Tracer(tp).Start(context.Background(), "parent")
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	client.List(ctx, &healthpb.HealthListRequest{})
+	parent.End()
+
+	spans := make(map[string]trace.SpanKind)
+	var clientSpan, serverSpan trace.SpanContext
+	var serverParent trace.SpanContext
+	for _, s := range rec.Ended() {
+		if s.SpanContext().TraceID() != parent.SpanContext().TraceID() {
+			t.Errorf("Span %s is not in the caller's trace", s.Name())
+		}
+		spans[s.Name()] = s.SpanKind()
+		if s.Name() == "grpc.health.v1.Health/Check" {
+			switch s.SpanKind() {
+			case trace.SpanKindClient:
+				clientSpan = s.SpanContext()
+			case trace.SpanKindServer:
+				serverSpan, serverParent = s.SpanContext(), s.Parent()
+			}
+		}
+	}
+	if _, ok := spans["grpc.health.v1.Health/List"]; ok {
+		t.Error("Expected the filtered method not to be traced")
+	}
+	if !clientSpan.IsValid() || !serverSpan.IsValid() {
+		t.Fatalf("Expected a client and a server span for Check, got %v", spans)
+	}
+	if !serverParent.Equal(clientSpan.WithRemote(true)) {
+		t.Errorf("Server span parent = %v, want the client span %v", serverParent.SpanID(), clientSpan.SpanID())
+	}
+}
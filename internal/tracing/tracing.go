@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"fmt"
+	"math"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"kvstore/internal/logging"
+)
+
+// ScopeName is the instrumentation scope of the node's own spans.
+const ScopeName = "kvstore"
+
+// Config configures a tracer provider.
+type Config struct {
+	Service    string  // service.name of the exported spans
+	InstanceID string  // service.instance.id, e.g. the node ID
+	SampleRate float64 // Fraction of new traces that are recorded, 0 to 1
+}
+
+// NewProvider creates a tracer provider exporting sampled spans to exporter
+// in batches. Call Shutdown on it to flush the remaining spans.
+func NewProvider(cfg Config, exporter sdktrace.SpanExporter) (*sdktrace.TracerProvider, error) {
+	if cfg.SampleRate < 0 || cfg.SampleRate > 1 || math.IsNaN(cfg.SampleRate) {
+		return nil, fmt.Errorf("sample rate must be between 0 and 1, got %v", cfg.SampleRate)
+	}
+	attrs := []attribute.KeyValue{semconv.ServiceName(cfg.Service)}
+	if cfg.InstanceID != "" {
+		attrs = append(attrs, semconv.ServiceInstanceID(cfg.InstanceID))
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(sdkresource.NewSchemaless(attrs...)),
+		sdktrace.WithSampler(NewSampler(cfg.SampleRate)),
+	), nil
+}
+
+// Tracer returns the tracer of the node's own spans from tp, or a tracer
+// that records nothing if tp is nil.
+func Tracer(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		tp = noop.NewTracerProvider()
+	}
+	return tp.Tracer(ScopeName)
+}
+
+// NewSampler returns a sampler that follows the decision of a span's parent.
+// A root span is sampled if one of its links is, so that a read repair is
+// recorded with the read that triggered it, and otherwise with the given
+// rate.
+func NewSampler(rate float64) sdktrace.Sampler {
+	return sdktrace.ParentBased(linkSampler{ratio: sdktrace.TraceIDRatioBased(rate)})
+}
+
+// linkSampler samples root spans with a sampled link, and others by ratio.
+type linkSampler struct {
+	ratio sdktrace.Sampler
+}
+
+func (s linkSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	for _, l := range p.Links {
+		if l.SpanContext.IsSampled() {
+			return sdktrace.SamplingResult{
+				Decision:   sdktrace.RecordAndSample,
+				Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+			}
+		}
+	}
+	return s.ratio.ShouldSample(p)
+}
+
+func (s linkSampler) Description() string {
+	return "LinkSampler{" + s.ratio.Description() + "}"
+}
+
+// SetError records err on span and marks the span as failed. A nil err is
+// ignored.
+func SetError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// ErrorHandler logs errors of the OpenTelemetry SDK, such as failed span
+// exports, as warnings of the tracing subsystem.
+func ErrorHandler(nodeID string) otel.ErrorHandler {
+	log := logging.For(logging.Tracing).With(logging.NodeID(nodeID))
+	return otel.ErrorHandlerFunc(func(err error) {
+		log.Warn("Tracing failed", logging.Err(err))
+	})
+}
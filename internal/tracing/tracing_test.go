@@ -0,0 +1,140 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTestProvider returns a provider recording the spans it ends.
+func newTestProvider(rate float64) (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	rec := tracetest.NewSpanRecorder()
+	return sdktrace.NewTracerProvider(sdktrace.WithSampler(NewSampler(rate)), sdktrace.WithSpanProcessor(rec)), rec
+}
+
+func TestNewProvider(t *testing.T) {
+	if _, err := NewProvider(Config{SampleRate: 1.5}, tracetest.NewInMemoryExporter()); err == nil {
+		t.Error("Expected error for a sample rate above 1")
+	}
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp, err := NewProvider(Config{Service: "kvstore", InstanceID: "n1", SampleRate: 1}, exporter)
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	_, span := Tracer(tp).Start(context.Background(), "op")
+	span.End()
+	if err := tp.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("ForceFlush() error = %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Exported %d spans, want 1", len(spans))
+	}
+	want := map[attribute.Key]string{"service.name": "kvstore", "service.instance.id": "n1"}
+	for _, kv := range spans[0].Resource.Attributes() {
+		if want[kv.Key] == kv.Value.AsString() {
+			delete(want, kv.Key)
+		}
+	}
+	if len(want) != 0 {
+		t.Errorf("Resource missing %v, got %v", want, spans[0].Resource.Attributes())
+	}
+}
+
+func TestSampler(t *testing.T) {
+	tp, rec := newTestProvider(0)
+	tracer := Tracer(tp)
+
+	// New traces are not sampled at rate 0, and neither are their children
+	ctx, root := tracer.Start(context.Background(), "root")
+	_, child := tracer.Start(ctx, "child")
+	if root.SpanContext().IsSampled() || child.SpanContext().IsSampled() {
+		t.Error("Expected no spans to be sampled at rate 0")
+	}
+
+	// A sampled parent from another process is followed
+	remote := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	_, continued := tracer.Start(trace.ContextWithRemoteSpanContext(context.Background(), remote), "continued")
+	if !continued.SpanContext().IsSampled() || continued.SpanContext().TraceID() != remote.TraceID() {
+		t.Error("Expected the sampled remote trace to be continued")
+	}
+
+	// A new root linked to a sampled span is sampled
+	_, linked := tracer.Start(context.Background(), "linked", trace.WithLinks(trace.Link{SpanContext: remote}))
+	if !linked.SpanContext().IsSampled() || linked.SpanContext().TraceID() == remote.TraceID() {
+		t.Error("Expected a new sampled trace for a span linked to a sampled span")
+	}
+
+	for _, s := range []trace.Span{child, root, continued, linked} {
+		s.End()
+	}
+	if got := len(rec.Ended()); got != 2 {
+		t.Errorf("Recorded %d spans, want 2", got)
+	}
+}
+
+func TestSetError(t *testing.T) {
+	tp, rec := newTestProvider(1)
+	_, span := Tracer(tp).Start(context.Background(), "op")
+	SetError(span, nil)
+	SetError(span, errors.New("boom"))
+	span.End()
+
+	s := rec.Ended()[0]
+	if s.Status().Code != codes.Error || s.Status().Description != "boom" {
+		t.Errorf("Status = %+v, want error boom", s.Status())
+	}
+	if len(s.Events()) != 1 || s.Events()[0].Name != "exception" {
+		t.Errorf("Expected one exception event, got %v", s.Events())
+	}
+}
+
+func TestTracer_NilProviderRecordsNothing(t *testing.T) {
+	_, span := Tracer(nil).Start(context.Background(), "op")
+	if span.IsRecording() || span.SpanContext().IsValid() {
+		t.Error("Expected a non-recording span without a provider")
+	}
+	span.End()
+}
+
+func TestExporters(t *testing.T) {
+	if _, err := NewOTLPExporter(context.Background(), "localhost:4318"); err == nil {
+		t.Error("Expected error for an endpoint without a scheme")
+	}
+	if _, err := NewOTLPExporter(context.Background(), "http://localhost:4318"); err != nil {
+		t.Errorf("NewOTLPExporter() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	exporter, err := NewWriterExporter(&out)
+	if err != nil {
+		t.Fatalf("NewWriterExporter() error = %v", err)
+	}
+	tp, err := NewProvider(Config{Service: "kvstore", SampleRate: 1}, exporter)
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	_, span := Tracer(tp).Start(context.Background(), "op")
+	span.End()
+	tp.Shutdown(context.Background())
+
+	var decoded struct{ Name string }
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || decoded.Name != "op" {
+		t.Errorf("Expected a JSON line for span op, got %q (%v)", out.String(), err)
+	}
+}
@@ -11,6 +11,7 @@ LOCAL_DIR="$PROJECT_ROOT/.local"
 LOGS_DIR="$LOCAL_DIR/logs"
 PIDS_DIR="$LOCAL_DIR/pids"
 BINARY="$PROJECT_ROOT/kvstore"
+LOG_LEVELS="${LOG_LEVELS:-repair=debug}"  # Read repair logs for the demos
 
 # Build binary if it doesn't exist
 if [ ! -f "$BINARY" ]; then
@@ -35,6 +36,7 @@ DEFAULT_R=2
 DEFAULT_W=2
 DEFAULT_VNODES=128
 BASE_PORT=50051
+BASE_METRICS_PORT=9101
 
 # Ensure directories exist
 mkdir -p "$LOGS_DIR" "$PIDS_DIR"
@@ -71,6 +73,7 @@ start_node() {
     local vnodes=${6:-$DEFAULT_VNODES}
     
     local port=$(get_node_port "$node_id")
+    local metrics_port=$((BASE_METRICS_PORT + ${node_id#n} - 1))
     local addr=$(get_node_addr "$node_id")
     local log_file="$LOGS_DIR/${node_id}.log"
     local pid_file="$PIDS_DIR/${node_id}.pid"
@@ -117,11 +120,13 @@ start_node() {
     "$BINARY" \
         --node-id="$node_id" \
         --listen=":$port" \
+        --metrics-addr="127.0.0.1:$metrics_port" \
         --peers="$peers" \
         --rf="$rf" \
         --r="$r" \
         --w="$w" \
         --vnodes="$vnodes" \
+        --log-levels="$LOG_LEVELS" \
         > "$log_file" 2>&1 &
     
     local pid=$!